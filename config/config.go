@@ -2,12 +2,14 @@ package config
 
 import (
 	"crypto/rand"
-	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
+
+	"minecraft-easyserver/utils"
 
 	"gopkg.in/yaml.v3"
 )
@@ -21,6 +23,8 @@ type Config struct {
 	} `yaml:"server"`
 
 	Auth struct {
+		// Deprecated: password hash of older versions. The owner account is created
+		// from it on first start, after which it is removed from the file.
 		Password  string `yaml:"password"`
 		JWTSecret string `yaml:"jwt_secret"`
 	} `yaml:"auth"`
@@ -37,20 +41,30 @@ type Config struct {
 	} `yaml:"web"`
 
 	Logging struct {
-		Level      string `yaml:"level"`
-		FileOutput bool   `yaml:"file_output"`
-		FilePath   string `yaml:"file_path"`
+		Level         string `yaml:"level"`
+		Format        string `yaml:"format"`
+		FileOutput    bool   `yaml:"file_output"`
+		FilePath      string `yaml:"file_path"`       // Directory and name prefix of server session logs
+		PanelFilePath string `yaml:"panel_file_path"` // The panel's own log; defaults to panel.log next to the session logs
+		MaxSizeMB     int    `yaml:"max_size_mb"`
+		MaxAgeDays    int    `yaml:"max_age_days"`
+		Compress      bool   `yaml:"compress"`
 	} `yaml:"logging"`
 }
 
 const (
 	// DefaultConfigPath default configuration file path
 	DefaultConfigPath = "config/config.yml"
+	// DefaultDataDir directory for data files managed by the panel itself
+	DefaultDataDir = "config/data"
 )
 
 var (
 	// AppConfig global configuration instance
 	AppConfig *Config
+
+	// loadedPath file AppConfig was loaded from
+	loadedPath string
 )
 
 // LoadConfig loads configuration from file
@@ -78,9 +92,65 @@ func LoadConfig(configPath string) error {
 	}
 
 	AppConfig = config
+	loadedPath = configPath
+	return nil
+}
+
+// ClearLegacyPassword removes the deprecated auth.password hash from the configuration
+// and from the file it was loaded from, once the accounts no longer need it
+func ClearLegacyPassword() error {
+	if AppConfig == nil || AppConfig.Auth.Password == "" {
+		return nil
+	}
+
+	if loadedPath != "" {
+		data, err := os.ReadFile(loadedPath)
+		if err != nil {
+			return fmt.Errorf("failed to read configuration file: %v", err)
+		}
+
+		// Edit the document rather than the struct to keep the rest of the file as it is
+		var document yaml.Node
+		if err := yaml.Unmarshal(data, &document); err != nil {
+			return fmt.Errorf("failed to parse configuration file: %v", err)
+		}
+		if removeYAMLKey(&document, "auth", "password") {
+			data, err := yaml.Marshal(&document)
+			if err != nil {
+				return err
+			}
+			if err := utils.WriteFileAtomic(loadedPath, data, 0644); err != nil {
+				return fmt.Errorf("failed to write configuration file: %v", err)
+			}
+		}
+	}
+
+	AppConfig.Auth.Password = ""
 	return nil
 }
 
+// removeYAMLKey removes the key at path from a YAML document and reports whether it existed
+func removeYAMLKey(node *yaml.Node, path ...string) bool {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if node.Kind != yaml.MappingNode || len(path) == 0 {
+		return false
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value != path[0] {
+			continue
+		}
+		if len(path) > 1 {
+			return removeYAMLKey(node.Content[i+1], path[1:]...)
+		}
+		node.Content = append(node.Content[:i], node.Content[i+2:]...)
+		return true
+	}
+	return false
+}
+
 // generateRandomSecret generates a random secret key
 func generateRandomSecret(length int) (string, error) {
 	bytes := make([]byte, length)
@@ -91,12 +161,6 @@ func generateRandomSecret(length int) (string, error) {
 	return hex.EncodeToString(bytes), nil
 }
 
-// hashPassword creates SHA256 hash of password
-func hashPassword(password string) string {
-	hash := sha256.Sum256([]byte(password))
-	return hex.EncodeToString(hash[:])
-}
-
 // createDefaultConfig creates default configuration file
 func createDefaultConfig(configPath string) error {
 	defaultConfig := &Config{}
@@ -113,9 +177,8 @@ func createDefaultConfig(configPath string) error {
 	}
 	defaultConfig.Auth.JWTSecret = jwtSecret
 
-	// Set default password (hashed)
-	defaultPassword := "admin123"
-	defaultConfig.Auth.Password = hashPassword(defaultPassword)
+	// No default password: the owner account gets a one-time bootstrap password
+	// the first time the panel starts
 
 	defaultConfig.Bedrock.Path = ""
 	// Set executable name based on operating system
@@ -130,8 +193,13 @@ func createDefaultConfig(configPath string) error {
 	defaultConfig.Web.TemplateFile = "./web/index.html"
 
 	defaultConfig.Logging.Level = "info"
-	defaultConfig.Logging.FileOutput = false
+	defaultConfig.Logging.Format = "text"
+	defaultConfig.Logging.FileOutput = true
 	defaultConfig.Logging.FilePath = "./logs/server.log"
+	defaultConfig.Logging.PanelFilePath = "./logs/panel.log"
+	defaultConfig.Logging.MaxSizeMB = 10
+	defaultConfig.Logging.MaxAgeDays = 30
+	defaultConfig.Logging.Compress = true
 
 	data, err := yaml.Marshal(defaultConfig)
 	if err != nil {
@@ -144,7 +212,7 @@ func createDefaultConfig(configPath string) error {
 		return err
 	}
 
-	return os.WriteFile(configPath, data, 0644)
+	return utils.WriteFileAtomic(configPath, data, 0644)
 }
 
 // validateConfig validates configuration
@@ -162,6 +230,20 @@ func validateConfig(config *Config) error {
 		return fmt.Errorf("Bedrock executable name cannot be empty")
 	}
 
+	if _, err := utils.ParseLogLevel(config.Logging.Level); err != nil {
+		return err
+	}
+
+	switch strings.ToLower(config.Logging.Format) {
+	case "", "text", "json":
+	default:
+		return fmt.Errorf("invalid log format: %s (use text or json)", config.Logging.Format)
+	}
+
+	if config.Logging.MaxSizeMB < 0 || config.Logging.MaxAgeDays < 0 {
+		return fmt.Errorf("logging max_size_mb and max_age_days cannot be negative")
+	}
+
 	return nil
 }
 
@@ -175,6 +257,32 @@ func (c *Config) GetBedrockExecutablePath() string {
 	return filepath.Join(c.Bedrock.Path, c.Bedrock.Executable)
 }
 
+// GetSessionLogDir gets the directory server session logs are written to
+func (c *Config) GetSessionLogDir() string {
+	if c.Logging.FilePath == "" {
+		return "./logs"
+	}
+	return filepath.Dir(c.Logging.FilePath)
+}
+
+// GetSessionLogPrefix gets the file name prefix of server session logs,
+// e.g. "server" for "./logs/server.log"
+func (c *Config) GetSessionLogPrefix() string {
+	base := filepath.Base(c.Logging.FilePath)
+	if c.Logging.FilePath == "" || base == "." {
+		return "server"
+	}
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// GetPanelLogPath gets the file the panel's own log is written to
+func (c *Config) GetPanelLogPath() string {
+	if c.Logging.PanelFilePath != "" {
+		return c.Logging.PanelFilePath
+	}
+	return filepath.Join(c.GetSessionLogDir(), "panel.log")
+}
+
 // GetBedrockPath gets Bedrock path
 func (c *Config) GetBedrockPath() string {
 	return c.Bedrock.Path
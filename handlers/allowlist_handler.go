@@ -1,6 +1,11 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
 	"strings"
 
 	"minecraft-easyserver/models"
@@ -9,6 +14,9 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// playerImportMaxSize largest player import accepted, as a request body or uploaded file
+const playerImportMaxSize = 1 << 20
+
 // AllowlistHandler allowlist handler
 type AllowlistHandler struct {
 	allowlistService *services.AllowlistService
@@ -48,19 +56,24 @@ func (h *AllowlistHandler) AddToAllowlist(c *gin.Context) {
 		IgnoresPlayerLimit: request.IgnoresPlayerLimit,
 	}
 
-	if err := h.allowlistService.AddToAllowlist(entry); err != nil {
+	live, err := h.allowlistService.AddToAllowlist(entry, currentUser(c))
+	if err != nil {
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(200, gin.H{"message": "Added to allowlist: " + request.Name})
+	c.JSON(200, gin.H{
+		"message": "Added to allowlist: " + request.Name,
+		"live":    live,
+	})
 }
 
 // RemoveFromAllowlist removes from allowlist
 func (h *AllowlistHandler) RemoveFromAllowlist(c *gin.Context) {
 	name := c.Param("name")
 
-	if err := h.allowlistService.RemoveFromAllowlist(name); err != nil {
+	live, err := h.allowlistService.RemoveFromAllowlist(name, currentUser(c))
+	if err != nil {
 		if strings.Contains(err.Error(), "not in allowlist") {
 			c.JSON(400, gin.H{"error": err.Error()})
 		} else {
@@ -69,5 +82,120 @@ func (h *AllowlistHandler) RemoveFromAllowlist(c *gin.Context) {
 		return
 	}
 
-	c.JSON(200, gin.H{"message": "Removed from allowlist: " + name})
-}
\ No newline at end of file
+	c.JSON(200, gin.H{
+		"message": "Removed from allowlist: " + name,
+		"live":    live,
+	})
+}
+
+// ImportPlayers imports allowlist entries and permissions from CSV or JSON.
+// The data is either the request body or an uploaded "file" form field.
+func (h *AllowlistHandler) ImportPlayers(c *gin.Context) {
+	format := strings.ToLower(c.Query("format"))
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, playerImportMaxSize)
+
+	var data []byte
+	file, err := c.FormFile("file")
+	if isTooLarge(err) {
+		importTooLarge(c)
+		return
+	}
+	if err == nil {
+		if format == "" {
+			format = strings.TrimPrefix(strings.ToLower(filepath.Ext(file.Filename)), ".")
+		}
+		reader, err := file.Open()
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Failed to read uploaded file"})
+			return
+		}
+		defer reader.Close()
+		// The upload is part of the body, so the body limit bounds it too
+		data, err = io.ReadAll(reader)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Failed to read uploaded file"})
+			return
+		}
+	} else {
+		data, err = io.ReadAll(c.Request.Body)
+		if isTooLarge(err) {
+			importTooLarge(c)
+			return
+		}
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid request data"})
+			return
+		}
+		if format == "" {
+			format = services.PlayerFormatJSON
+			if strings.Contains(c.ContentType(), "csv") {
+				format = services.PlayerFormatCSV
+			}
+		}
+	}
+
+	mode := c.DefaultQuery("mode", services.PlayerImportMerge)
+	dryRun := c.Query("dry_run") == "true"
+
+	result, err := h.allowlistService.ImportPlayers(data, format, mode, dryRun, currentUser(c))
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(400, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(500, gin.H{"error": "Failed to import players: " + err.Error()})
+		}
+		return
+	}
+
+	if len(result.Errors) > 0 {
+		c.JSON(400, gin.H{
+			"error":  fmt.Sprintf("Import has %d invalid rows, nothing was written", len(result.Errors)),
+			"result": result,
+		})
+		return
+	}
+
+	message := "Players imported"
+	if dryRun {
+		message = "Dry run, nothing was written"
+	}
+	c.JSON(200, gin.H{
+		"message": message,
+		"result":  result,
+	})
+}
+
+// isTooLarge reports whether err comes from reading past a request body limit
+func isTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
+
+// importTooLarge rejects a player import over playerImportMaxSize
+func importTooLarge(c *gin.Context) {
+	c.JSON(413, gin.H{"error": fmt.Sprintf("Import is larger than %d KB", playerImportMaxSize/1024)})
+}
+
+// ExportPlayers exports the allowlist combined with permissions as CSV or JSON
+func (h *AllowlistHandler) ExportPlayers(c *gin.Context) {
+	format := strings.ToLower(c.DefaultQuery("format", services.PlayerFormatJSON))
+
+	entries, err := h.allowlistService.ExportPlayers()
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Failed to export players: " + err.Error()})
+		return
+	}
+
+	data, err := services.EncodePlayers(entries, format)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	contentType := "application/json"
+	if format == services.PlayerFormatCSV {
+		contentType = "text/csv; charset=utf-8"
+	}
+	c.Header("Content-Disposition", "attachment; filename=players."+format)
+	c.Data(200, contentType, data)
+}
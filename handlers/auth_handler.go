@@ -13,8 +13,11 @@ import (
 
 // AuthHandler handles authentication requests
 type AuthHandler struct {
-	authService *services.AuthService
-	rateLimiter *services.RateLimiterService
+	authService     *services.AuthService
+	sessionService  *services.SessionService
+	apiTokenService *services.APITokenService
+	twoFactor       *services.TwoFactorService
+	rateLimiter     *services.RateLimiterService
 }
 
 // NewAuthHandler creates a new auth handler instance
@@ -22,8 +25,11 @@ func NewAuthHandler() *AuthHandler {
 	rateLimiter := services.NewRateLimiterService()
 	rateLimiter.StartCleanupRoutine()
 	return &AuthHandler{
-		authService: services.NewAuthService(),
-		rateLimiter: rateLimiter,
+		authService:     services.NewAuthService(),
+		sessionService:  services.NewSessionService(),
+		apiTokenService: services.NewAPITokenService(),
+		twoFactor:       services.NewTwoFactorService(),
+		rateLimiter:     rateLimiter,
 	}
 }
 
@@ -71,7 +77,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 
 	// Try authentication
-	response, err := h.authService.Login(req.Password)
+	response, err := h.authService.Login(req.Username, req.Password, clientIP, c.Request.UserAgent())
 	if err != nil {
 		// Record failed attempt
 		h.rateLimiter.RecordFailedAttempt(clientIP)
@@ -93,11 +99,95 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
+	// The password alone doesn't clear failed attempts when a two-factor code is still needed
+	if response.TwoFactorRequired {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
 	// Record successful attempt (clears failed attempts and any blocks)
 	h.rateLimiter.RecordSuccessfulAttempt(clientIP)
 	c.JSON(http.StatusOK, response)
 }
 
+// LoginTwoFactor handles POST /api/auth/login/2fa, the second login step
+func (h *AuthHandler) LoginTwoFactor(c *gin.Context) {
+	clientIP := h.getSecureClientIP(c)
+	if h.rejectBlocked(c, clientIP) {
+		return
+	}
+
+	var req models.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	response, err := h.authService.LoginTwoFactor(req.ChallengeToken, req.Code, clientIP, c.Request.UserAgent())
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			h.rejectTwoFactorCode(c, clientIP, http.StatusUnauthorized, err)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	h.rateLimiter.RecordSuccessfulAttempt(clientIP)
+	c.JSON(http.StatusOK, response)
+}
+
+// rejectBlocked responds with 429 if clientIP is blocked after too many failed attempts
+func (h *AuthHandler) rejectBlocked(c *gin.Context, clientIP string) bool {
+	if !h.rateLimiter.IsBlocked(clientIP) {
+		return false
+	}
+	blockTime := h.rateLimiter.GetBlockTimeRemaining(clientIP)
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":               "Too many failed login attempts. Please try again later.",
+		"blocked_until":       time.Now().Add(blockTime).Format(time.RFC3339),
+		"retry_after_seconds": int(blockTime.Seconds()),
+	})
+	return true
+}
+
+// rejectTwoFactorCode records a failed two-factor code against clientIP and responds
+// with err
+func (h *AuthHandler) rejectTwoFactorCode(c *gin.Context, clientIP string, status int, err error) {
+	h.rateLimiter.RecordFailedAttempt(clientIP)
+	remainingAttempts := h.rateLimiter.GetRemainingAttempts(clientIP)
+
+	errorResponse := gin.H{
+		"error": err.Error(),
+	}
+	if remainingAttempts <= 2 {
+		errorResponse["remaining_attempts"] = remainingAttempts
+	}
+	c.JSON(status, errorResponse)
+}
+
+// twoFactorError responds to an error from the two-factor service; wrong codes and
+// passwords count towards the login lockout
+func (h *AuthHandler) twoFactorError(c *gin.Context, clientIP string, err error) {
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "already enabled"):
+		c.JSON(http.StatusConflict, gin.H{"error": message})
+	case strings.Contains(message, "not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": message})
+	case strings.Contains(message, "invalid request"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": message})
+	case strings.Contains(message, "invalid"):
+		h.rejectTwoFactorCode(c, clientIP, http.StatusBadRequest, err)
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
 // ChangePassword handles password change requests
 func (h *AuthHandler) ChangePassword(c *gin.Context) {
 	var req models.ChangePasswordRequest
@@ -108,7 +198,15 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 		return
 	}
 
-	response, err := h.authService.ChangePassword(req.CurrentPassword, req.NewPassword)
+	user, ok := currentAccount(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Not logged in",
+		})
+		return
+	}
+
+	response, err := h.authService.ChangePassword(user.ID, req.CurrentPassword, req.NewPassword)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": err.Error(),
@@ -122,4 +220,303 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, response)
-}
\ No newline at end of file
+}
+
+// GetCurrentUser handles GET /api/auth/me
+func (h *AuthHandler) GetCurrentUser(c *gin.Context) {
+	user, ok := currentAccount(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Not logged in",
+		})
+		return
+	}
+
+	permissions := []string{}
+	for _, permission := range []string{models.PermissionView, models.PermissionModerate, models.PermissionControl, models.PermissionManage, models.PermissionUsers} {
+		if services.RoleHasPermission(user.Role, permission) {
+			permissions = append(permissions, permission)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user":        user,
+		"permissions": permissions,
+	})
+}
+
+// Refresh handles POST /api/auth/refresh
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req models.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	response, err := h.authService.Refresh(req.RefreshToken)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "invalid refresh token") {
+			status = http.StatusUnauthorized
+		}
+		c.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Logout handles POST /api/auth/logout, revoking the current session
+func (h *AuthHandler) Logout(c *gin.Context) {
+	user, _ := currentAccount(c)
+	if err := h.sessionService.RevokeSession(user.ID, currentSessionID(c)); err != nil && !strings.Contains(err.Error(), "not found") {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to log out: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Logged out",
+	})
+}
+
+// GetSessions handles GET /api/auth/sessions
+func (h *AuthHandler) GetSessions(c *gin.Context) {
+	user, _ := currentAccount(c)
+	sessions, err := h.sessionService.ListSessions(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to read sessions: " + err.Error(),
+		})
+		return
+	}
+
+	current := currentSessionID(c)
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == current
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"sessions": sessions,
+		"count":    len(sessions),
+	})
+}
+
+// RevokeSession handles DELETE /api/auth/sessions/:id
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	user, _ := currentAccount(c)
+	if err := h.sessionService.RevokeSession(user.ID, c.Param("id")); err != nil {
+		status := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "not found") {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Session revoked",
+	})
+}
+
+// RevokeAllSessions handles DELETE /api/auth/sessions, logging the user out everywhere
+func (h *AuthHandler) RevokeAllSessions(c *gin.Context) {
+	user, _ := currentAccount(c)
+	count, err := h.sessionService.RevokeUserSessions(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to revoke sessions: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "All sessions revoked",
+		"count":   count,
+	})
+}
+
+// GetAPITokens handles GET /api/auth/tokens
+func (h *AuthHandler) GetAPITokens(c *gin.Context) {
+	user, _ := currentAccount(c)
+	tokens, err := h.apiTokenService.ListTokens(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to read API tokens: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tokens": tokens,
+		"count":  len(tokens),
+	})
+}
+
+// CreateAPIToken handles POST /api/auth/tokens
+func (h *AuthHandler) CreateAPIToken(c *gin.Context) {
+	var req models.CreateAPITokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	user, _ := currentAccount(c)
+	token, plain, err := h.apiTokenService.CreateToken(user, req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "not allowed") {
+			status = http.StatusForbidden
+		} else if strings.Contains(err.Error(), "invalid") {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "API token created; copy it now, it won't be shown again",
+		"token":     plain,
+		"api_token": token,
+	})
+}
+
+// RevokeAPIToken handles DELETE /api/auth/tokens/:id
+func (h *AuthHandler) RevokeAPIToken(c *gin.Context) {
+	user, _ := currentAccount(c)
+	if err := h.apiTokenService.RevokeToken(user.ID, c.Param("id")); err != nil {
+		status := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "not found") {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "API token revoked",
+	})
+}
+
+// GetTwoFactorStatus handles GET /api/auth/2fa
+func (h *AuthHandler) GetTwoFactorStatus(c *gin.Context) {
+	user, _ := currentAccount(c)
+	enabled, remaining, err := h.twoFactor.Status(user.ID)
+	if err != nil {
+		h.twoFactorError(c, h.getSecureClientIP(c), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":                  enabled,
+		"recovery_codes_remaining": remaining,
+	})
+}
+
+// SetupTwoFactor handles POST /api/auth/2fa/setup, returning a new secret to confirm
+// with EnableTwoFactor
+func (h *AuthHandler) SetupTwoFactor(c *gin.Context) {
+	user, _ := currentAccount(c)
+	setup, err := h.twoFactor.BeginSetup(user.ID)
+	if err != nil {
+		h.twoFactorError(c, h.getSecureClientIP(c), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, setup)
+}
+
+// EnableTwoFactor handles POST /api/auth/2fa/enable
+func (h *AuthHandler) EnableTwoFactor(c *gin.Context) {
+	clientIP := h.getSecureClientIP(c)
+	if h.rejectBlocked(c, clientIP) {
+		return
+	}
+
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	user, _ := currentAccount(c)
+	codes, err := h.twoFactor.Enable(user.ID, req.Code)
+	if err != nil {
+		h.twoFactorError(c, clientIP, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Two-factor authentication enabled; store the recovery codes now, they won't be shown again",
+		"recovery_codes": codes,
+	})
+}
+
+// DisableTwoFactor handles POST /api/auth/2fa/disable
+func (h *AuthHandler) DisableTwoFactor(c *gin.Context) {
+	clientIP := h.getSecureClientIP(c)
+	if h.rejectBlocked(c, clientIP) {
+		return
+	}
+
+	var req models.DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	user, _ := currentAccount(c)
+	if err := h.twoFactor.Disable(user.ID, req.Password, req.Code); err != nil {
+		h.twoFactorError(c, clientIP, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Two-factor authentication disabled",
+	})
+}
+
+// RegenerateRecoveryCodes handles POST /api/auth/2fa/recovery-codes
+func (h *AuthHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	clientIP := h.getSecureClientIP(c)
+	if h.rejectBlocked(c, clientIP) {
+		return
+	}
+
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	user, _ := currentAccount(c)
+	codes, err := h.twoFactor.RegenerateRecoveryCodes(user.ID, req.Code)
+	if err != nil {
+		h.twoFactorError(c, clientIP, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Recovery codes replaced; the old codes no longer work",
+		"recovery_codes": codes,
+	})
+}
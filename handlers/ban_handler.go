@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"minecraft-easyserver/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BanHandler ban handler
+type BanHandler struct {
+	banService *services.BanService
+}
+
+// NewBanHandler creates a new ban handler
+func NewBanHandler() *BanHandler {
+	return &BanHandler{
+		banService: services.NewBanService(),
+	}
+}
+
+// GetBans gets active bans, or all bans including expired ones with ?all=true
+func (h *BanHandler) GetBans(c *gin.Context) {
+	bans, err := h.banService.GetBans(c.Query("all") == "true")
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Failed to read bans: " + err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"bans":  bans,
+		"count": len(bans),
+	})
+}
+
+// CreateBan bans a player by name and/or XUID
+func (h *BanHandler) CreateBan(c *gin.Context) {
+	var request struct {
+		Name     string `json:"name"`
+		Xuid     string `json:"xuid"`
+		Reason   string `json:"reason"`
+		Duration string `json:"duration"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	ban, err := h.banService.CreateBan(request.Name, request.Xuid, request.Reason, request.Duration, currentUser(c))
+	if err != nil {
+		if strings.Contains(err.Error(), "already banned") {
+			c.JSON(409, gin.H{"error": err.Error()})
+		} else if strings.Contains(err.Error(), "invalid") {
+			c.JSON(400, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(500, gin.H{"error": "Failed to save ban: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "Player banned",
+		"ban":     ban,
+	})
+}
+
+// UpdateBan changes the reason or duration of a ban
+func (h *BanHandler) UpdateBan(c *gin.Context) {
+	var request struct {
+		Reason   *string `json:"reason"`
+		Duration *string `json:"duration"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	ban, err := h.banService.UpdateBan(c.Param("id"), request.Reason, request.Duration, currentUser(c))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(404, gin.H{"error": err.Error()})
+		} else if strings.Contains(err.Error(), "invalid") {
+			c.JSON(400, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(500, gin.H{"error": "Failed to save ban: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "Ban updated",
+		"ban":     ban,
+	})
+}
+
+// DeleteBan lifts a ban
+func (h *BanHandler) DeleteBan(c *gin.Context) {
+	if err := h.banService.DeleteBan(c.Param("id"), currentUser(c)); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(404, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(500, gin.H{"error": "Failed to remove ban: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Ban removed"})
+}
+
+// GetAudit gets the ban audit trail
+func (h *BanHandler) GetAudit(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil {
+		limit = 100
+	}
+
+	audit, err := h.banService.GetAudit(limit)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Failed to read ban audit trail: " + err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"audit": audit,
+		"count": len(audit),
+	})
+}
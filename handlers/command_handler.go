@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"strings"
 
 	"minecraft-easyserver/models"
 	"minecraft-easyserver/services"
@@ -64,7 +65,12 @@ func (h *CommandHandler) ExecuteQuickCommand(c *gin.Context) {
 	}
 
 	// Execute command
-	if err := h.commandService.ExecuteQuickCommand(id); err != nil {
+	confirmed := c.Query("confirm") == "true"
+	if err := h.commandService.ExecuteQuickCommand(id, currentRole(c), confirmed); err != nil {
+		if strings.Contains(err.Error(), "validation failed") {
+			respondCommandRejected(c, err)
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": err.Error(),
 		})
@@ -1,6 +1,9 @@
 package handlers
 
 import (
+	"strconv"
+	"strings"
+
 	"minecraft-easyserver/models"
 	"minecraft-easyserver/services"
 
@@ -9,13 +12,17 @@ import (
 
 // ConfigHandler configuration handler
 type ConfigHandler struct {
-	configService *services.ConfigService
+	configService        *services.ConfigService
+	configHistoryService *services.ConfigHistoryService
+	presetService        *services.PresetService
 }
 
 // NewConfigHandler creates a new configuration handler
 func NewConfigHandler() *ConfigHandler {
 	return &ConfigHandler{
-		configService: services.NewConfigService(),
+		configService:        services.NewConfigService(),
+		configHistoryService: services.NewConfigHistoryService(),
+		presetService:        services.NewPresetService(),
 	}
 }
 
@@ -32,7 +39,126 @@ func (h *ConfigHandler) GetConfig(c *gin.Context) {
 // UpdateConfig updates server configuration
 func (h *ConfigHandler) UpdateConfig(c *gin.Context) {
 	var request struct {
-		Config models.ServerConfig `json:"config"`
+		Config models.ServerProperties `json:"config"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	result, err := h.configService.UpdateConfig(request.Config, currentUser(c))
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid configuration") {
+			c.JSON(400, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(500, gin.H{"error": "Failed to save configuration: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(200, configUpdateResponse("Configuration saved", result))
+}
+
+// configUpdateResponse builds the response for a server.properties update
+func configUpdateResponse(message string, result models.ConfigUpdateResult) gin.H {
+	restartRequired := len(result.RestartRequiredKeys) > 0
+	if restartRequired {
+		message += ", restart server to take effect"
+	}
+
+	return gin.H{
+		"message":               message,
+		"changed_keys":          result.ChangedKeys,
+		"applied_live":          result.AppliedLive,
+		"restart_required":      restartRequired,
+		"restart_required_keys": result.RestartRequiredKeys,
+	}
+}
+
+// GetSchema gets the server.properties schema
+func (h *ConfigHandler) GetSchema(c *gin.Context) {
+	c.JSON(200, gin.H{"schema": h.configService.GetSchema()})
+}
+
+// GetHistory gets recorded changes to server.properties, allowlist.json and permissions.json
+func (h *ConfigHandler) GetHistory(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil {
+		limit = 50
+	}
+
+	history, err := h.configHistoryService.GetHistory(c.Query("file"), limit)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Failed to read configuration history: " + err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"history": history,
+		"count":   len(history),
+	})
+}
+
+// GetHistoryDiff gets the line diff of a recorded change
+func (h *ConfigHandler) GetHistoryDiff(c *gin.Context) {
+	change, diff, err := h.configHistoryService.GetDiff(c.Param("id"))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(404, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(500, gin.H{"error": "Failed to read configuration change: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"change": change,
+		"diff":   diff,
+	})
+}
+
+// RollbackHistory restores a file to the content it had before a recorded change
+func (h *ConfigHandler) RollbackHistory(c *gin.Context) {
+	change, err := h.configHistoryService.Rollback(c.Param("id"), currentUser(c))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(404, gin.H{"error": err.Error()})
+		} else if strings.Contains(err.Error(), "cannot roll back") {
+			c.JSON(400, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(500, gin.H{"error": "Failed to roll back configuration change: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "Configuration change rolled back, restart server to take effect",
+		"change":  change,
+	})
+}
+
+// GetPresets gets all configuration presets
+func (h *ConfigHandler) GetPresets(c *gin.Context) {
+	presets, err := h.presetService.GetPresets()
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Failed to read presets: " + err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"presets": presets,
+		"count":   len(presets),
+	})
+}
+
+// CreatePreset creates a preset from explicit values or from keys of the current configuration
+func (h *ConfigHandler) CreatePreset(c *gin.Context) {
+	var request struct {
+		Name        string                  `json:"name" binding:"required"`
+		Description string                  `json:"description"`
+		Keys        []string                `json:"keys"`
+		Properties  models.ServerProperties `json:"properties"`
 	}
 
 	if err := c.ShouldBindJSON(&request); err != nil {
@@ -40,10 +166,69 @@ func (h *ConfigHandler) UpdateConfig(c *gin.Context) {
 		return
 	}
 
-	if err := h.configService.UpdateConfig(request.Config); err != nil {
-		c.JSON(500, gin.H{"error": "Failed to save configuration: " + err.Error()})
+	preset, err := h.presetService.CreatePreset(request.Name, request.Description, request.Keys, request.Properties)
+	if err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			c.JSON(409, gin.H{"error": err.Error()})
+		} else if strings.Contains(err.Error(), "invalid") {
+			c.JSON(400, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(500, gin.H{"error": "Failed to create preset: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "Preset created",
+		"preset":  preset,
+	})
+}
+
+// DeletePreset deletes a preset
+func (h *ConfigHandler) DeletePreset(c *gin.Context) {
+	if err := h.presetService.DeletePreset(c.Param("name")); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(404, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(500, gin.H{"error": "Failed to delete preset: " + err.Error()})
+		}
 		return
 	}
 
-	c.JSON(200, gin.H{"message": "Configuration saved, restart server to take effect"})
-}
\ No newline at end of file
+	c.JSON(200, gin.H{"message": "Preset deleted"})
+}
+
+// DiffPreset gets the preset values that differ from the live configuration
+func (h *ConfigHandler) DiffPreset(c *gin.Context) {
+	diff, err := h.presetService.DiffPreset(c.Param("name"))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(404, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(500, gin.H{"error": "Failed to compare preset: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"diff":  diff,
+		"count": len(diff),
+	})
+}
+
+// ApplyPreset applies a preset to server.properties
+func (h *ConfigHandler) ApplyPreset(c *gin.Context) {
+	result, err := h.presetService.ApplyPreset(c.Param("name"), currentUser(c))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(404, gin.H{"error": err.Error()})
+		} else if strings.Contains(err.Error(), "invalid configuration") {
+			c.JSON(400, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(500, gin.H{"error": "Failed to apply preset: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(200, configUpdateResponse("Preset applied", result))
+}
@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"minecraft-easyserver/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ConsoleHandler struct {
+	consoleService *services.ConsoleService
+}
+
+func NewConsoleHandler() *ConsoleHandler {
+	return &ConsoleHandler{
+		consoleService: services.NewConsoleService(),
+	}
+}
+
+// HandleConsole handles GET /api/websocket/console
+func (h *ConsoleHandler) HandleConsole(c *gin.Context) {
+	// Token validation is already done by WebSocketAuthMiddleware
+	matcher, ok := compileLogFilterQuery(c)
+	if !ok {
+		return
+	}
+	h.consoleService.HandleConsole(c.Writer, c.Request, currentStreamAuth(c), matcher)
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"minecraft-easyserver/models"
+	"minecraft-easyserver/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// currentAccount returns the user account making a request, as set by the auth middleware
+func currentAccount(c *gin.Context) (models.User, bool) {
+	value, exists := c.Get("user")
+	if !exists {
+		return models.User{}, false
+	}
+	user, ok := value.(models.User)
+	return user, ok
+}
+
+// currentSessionID returns the session the request's access token belongs to
+func currentSessionID(c *gin.Context) string {
+	if value, exists := c.Get("claims"); exists {
+		if claims, ok := value.(*models.JWTClaims); ok {
+			return claims.SessionID
+		}
+	}
+	return ""
+}
+
+// currentUser returns the name recorded as the acting user for changes made by a request
+func currentUser(c *gin.Context) string {
+	if user, ok := currentAccount(c); ok {
+		return user.Username
+	}
+	return "unknown"
+}
+
+// currentRole returns the role whose command policy applies to a request; requests
+// without an account have no role and can't send commands
+func currentRole(c *gin.Context) string {
+	if user, ok := currentAccount(c); ok {
+		return user.Role
+	}
+	return ""
+}
+
+// currentStreamAuth identifies the user and session or API token behind a request, for
+// WebSockets and event streams that check the user's access again while they stay open
+func currentStreamAuth(c *gin.Context) services.StreamAuth {
+	auth := services.StreamAuth{SessionID: currentSessionID(c)}
+	if user, ok := currentAccount(c); ok {
+		auth.UserID = user.ID
+	}
+	if value, exists := c.Get("api_token"); exists {
+		if apiToken, ok := value.(models.APIToken); ok {
+			auth.APITokenID = apiToken.ID
+		}
+	}
+	return auth
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"minecraft-easyserver/models"
+	"minecraft-easyserver/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// eventKeepAlive how often a comment is sent on an idle stream so proxies keep it open
+const eventKeepAlive = 15 * time.Second
+
+// eventBuffer events buffered per stream before the client is dropped for falling behind
+const eventBuffer = 256
+
+type EventHandler struct {
+	eventService *services.EventService
+}
+
+func NewEventHandler() *EventHandler {
+	return &EventHandler{
+		eventService: services.NewEventService(),
+	}
+}
+
+// StreamEvents handles GET /api/events
+func (h *EventHandler) StreamEvents(c *gin.Context) {
+	topics, err := services.ParseEventTopics(c.Query("topics"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// Browsers send Last-Event-ID when reconnecting; other clients may use the query parameter
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	var lastID int64
+	if lastEventID != "" {
+		lastID, err = strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil || lastID < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid Last-Event-ID",
+			})
+			return
+		}
+	}
+
+	// Subscribe before replaying so nothing published in between is missed
+	events, unsubscribe := h.eventService.Subscribe(eventBuffer)
+	defer unsubscribe()
+
+	// An ID from before a panel restart can't be resumed; start from the buffer
+	if lastID > h.eventService.LastID() {
+		lastID = 0
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // Disable nginx response buffering
+	c.Status(http.StatusOK)
+	fmt.Fprint(c.Writer, "retry: 3000\n\n")
+
+	backlog, sent := h.eventService.Since(lastID, topics)
+	for _, event := range backlog {
+		writeServerEvent(c, event.ID, event.Topic, event.Data)
+	}
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(eventKeepAlive)
+	defer keepAlive.Stop()
+
+	// The stream ends once the user's session or API token is revoked or they lose access
+	done, revoked := make(chan struct{}), make(chan struct{})
+	defer close(done)
+	go currentStreamAuth(c).Watch(models.PermissionView, done, func() { close(revoked) })
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				// Too slow to keep up; the client reconnects and resumes from its last ID
+				return
+			}
+			if event.ID <= sent || (len(topics) > 0 && !topics[event.Topic]) {
+				continue
+			}
+			writeServerEvent(c, event.ID, event.Topic, event.Data)
+			c.Writer.Flush()
+		case <-keepAlive.C:
+			fmt.Fprint(c.Writer, ": keepalive\n\n")
+			c.Writer.Flush()
+		case <-revoked:
+			return
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
+
+// writeServerEvent writes one event in text/event-stream format
+func writeServerEvent(c *gin.Context, id int64, topic string, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", id, topic, payload)
+}
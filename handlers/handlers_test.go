@@ -2,12 +2,17 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"minecraft-easyserver/models"
 	"minecraft-easyserver/services"
@@ -22,6 +27,7 @@ func setupTestEnvironment(t *testing.T) string {
 
 	// Set bedrock path for test environment
 	services.SetBedrockPath(tempDir)
+	services.SetDataDir(filepath.Join(tempDir, "data"))
 
 	t.Cleanup(func() {
 		os.RemoveAll(tempDir)
@@ -96,6 +102,8 @@ default-player-permission-level=member
 		configMap := config.(map[string]interface{})
 		assert.Equal(t, "Test Server", configMap["server-name"])
 		assert.Equal(t, "survival", configMap["gamemode"])
+		// Keys missing from the file get their defaults
+		assert.Equal(t, float64(32), configMap["view-distance"])
 	})
 
 	t.Run("UpdateConfig", func(t *testing.T) {
@@ -134,6 +142,191 @@ default-player-permission-level=member
 		assert.Contains(t, response["message"], "Configuration saved")
 	})
 
+	t.Run("UpdateConfig_AddsMissingKeyAndKeepsUnknown", func(t *testing.T) {
+		err := os.WriteFile(configPath, []byte(testConfig+"custom-key=keep-me\n"), 0644)
+		assert.NoError(t, err)
+
+		jsonBody := []byte(`{"config": {"view-distance": 16, "level-seed": "12345"}}`)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("PUT", "/api/config", bytes.NewBuffer(jsonBody))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		handler.UpdateConfig(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		data, err := os.ReadFile(configPath)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), "view-distance=16")
+		assert.Contains(t, string(data), "level-seed=12345")
+		assert.Contains(t, string(data), "custom-key=keep-me")
+
+		// The server is stopped, so nothing is applied live and no restart is pending
+		var response struct {
+			ChangedKeys     []string `json:"changed_keys"`
+			AppliedLive     []string `json:"applied_live"`
+			RestartRequired bool     `json:"restart_required"`
+		}
+		err = json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []string{"level-seed", "view-distance"}, response.ChangedKeys)
+		assert.Empty(t, response.AppliedLive)
+		assert.False(t, response.RestartRequired)
+	})
+
+	t.Run("UpdateConfig_ValidationError", func(t *testing.T) {
+		jsonBody := []byte(`{"config": {"tick-distance": 20, "gamemode": "hardcore"}}`)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("PUT", "/api/config", bytes.NewBuffer(jsonBody))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		handler.UpdateConfig(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "tick-distance")
+		assert.Contains(t, w.Body.String(), "gamemode")
+	})
+
+	t.Run("History_DiffAndRollback", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/api/config/history?file=server.properties", nil)
+
+		handler.GetHistory(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var historyResponse struct {
+			History []models.ConfigChange `json:"history"`
+		}
+		err := json.Unmarshal(w.Body.Bytes(), &historyResponse)
+		assert.NoError(t, err)
+		if !assert.NotEmpty(t, historyResponse.History) {
+			return
+		}
+		latest := historyResponse.History[0]
+
+		w = httptest.NewRecorder()
+		c, _ = gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "id", Value: latest.ID}}
+		c.Request = httptest.NewRequest("GET", "/api/config/history/"+latest.ID+"/diff", nil)
+
+		handler.GetHistoryDiff(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "view-distance=16")
+
+		w = httptest.NewRecorder()
+		c, _ = gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "id", Value: latest.ID}}
+		c.Request = httptest.NewRequest("POST", "/api/config/history/"+latest.ID+"/rollback", nil)
+
+		handler.RollbackHistory(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		data, err := os.ReadFile(configPath)
+		assert.NoError(t, err)
+		assert.NotContains(t, string(data), "view-distance=16")
+		assert.Contains(t, string(data), "custom-key=keep-me")
+	})
+
+	t.Run("GetSchema", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/api/config/schema", nil)
+
+		handler.GetSchema(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Schema []models.ServerPropertySchema `json:"schema"`
+		}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, response.Schema)
+	})
+
+	t.Run("Presets_CreateDiffApply", func(t *testing.T) {
+		jsonBody := []byte(`{"name": "creative build", "properties": {"gamemode": "creative", "allow-cheats": true}}`)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/api/config/presets", bytes.NewBuffer(jsonBody))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		handler.CreatePreset(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = httptest.NewRecorder()
+		c, _ = gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/api/config/presets", bytes.NewBuffer(jsonBody))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		handler.CreatePreset(c)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		err := os.WriteFile(configPath, []byte(testConfig), 0644)
+		assert.NoError(t, err)
+
+		w = httptest.NewRecorder()
+		c, _ = gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "name", Value: "creative build"}}
+		c.Request = httptest.NewRequest("GET", "/api/config/presets/creative%20build/diff", nil)
+
+		handler.DiffPreset(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var diffResponse struct {
+			Diff []models.ConfigPresetDiff `json:"diff"`
+		}
+		err = json.Unmarshal(w.Body.Bytes(), &diffResponse)
+		assert.NoError(t, err)
+		assert.Len(t, diffResponse.Diff, 2)
+
+		w = httptest.NewRecorder()
+		c, _ = gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "name", Value: "creative build"}}
+		c.Request = httptest.NewRequest("POST", "/api/config/presets/creative%20build/apply", nil)
+
+		handler.ApplyPreset(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var applyResponse struct {
+			ChangedKeys     []string `json:"changed_keys"`
+			RestartRequired bool     `json:"restart_required"`
+		}
+		err = json.Unmarshal(w.Body.Bytes(), &applyResponse)
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []string{"gamemode", "allow-cheats"}, applyResponse.ChangedKeys)
+		assert.False(t, applyResponse.RestartRequired)
+
+		data, err := os.ReadFile(configPath)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), "gamemode=creative")
+		assert.Contains(t, string(data), "allow-cheats=true")
+	})
+
+	t.Run("Presets_NotFound", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "name", Value: "missing"}}
+		c.Request = httptest.NewRequest("DELETE", "/api/config/presets/missing", nil)
+
+		handler.DeletePreset(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
 	t.Run("UpdateConfig_InvalidJSON", func(t *testing.T) {
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
@@ -244,6 +437,165 @@ func TestAllowlistHandler(t *testing.T) {
 	})
 }
 
+func TestAllowlistImportExport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tempDir := setupTestEnvironment(t)
+	allowlistPath := filepath.Join(tempDir, "allowlist.json")
+	permissionsPath := filepath.Join(tempDir, "permissions.json")
+
+	err := os.WriteFile(allowlistPath, []byte(`[{"name": "Existing", "ignoresPlayerLimit": false}]`), 0644)
+	assert.NoError(t, err)
+
+	handler := NewAllowlistHandler()
+
+	importCSV := func(query, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/api/allowlist/import?"+query, bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "text/csv")
+
+		handler.ImportPlayers(c)
+		return w
+	}
+
+	csvData := "name,xuid,ignoresPlayerLimit,permission\n" +
+		"Alice,2535400000000001,false,member\n" +
+		"Bob Builder,2535400000000002,true,operator\n"
+
+	t.Run("DryRun", func(t *testing.T) {
+		w := importCSV("dry_run=true", csvData)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Result models.PlayerImportResult `json:"result"`
+		}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, response.Result.AllowlistAdded)
+		assert.Equal(t, 2, response.Result.PermissionsSet)
+
+		data, _ := os.ReadFile(allowlistPath)
+		assert.NotContains(t, string(data), "Alice")
+	})
+
+	t.Run("RowErrors", func(t *testing.T) {
+		w := importCSV("", csvData+"\"Bad\"\"Name\",abc,false,owner\n")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "invalid name")
+		assert.Contains(t, w.Body.String(), "invalid xuid")
+		assert.Contains(t, w.Body.String(), "invalid permission level")
+
+		data, _ := os.ReadFile(allowlistPath)
+		assert.NotContains(t, string(data), "Alice")
+	})
+
+	t.Run("Merge", func(t *testing.T) {
+		w := importCSV("mode=merge", csvData)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		data, _ := os.ReadFile(allowlistPath)
+		assert.Contains(t, string(data), "Existing")
+		assert.Contains(t, string(data), "Bob Builder")
+
+		data, _ = os.ReadFile(permissionsPath)
+		assert.Contains(t, string(data), "2535400000000002")
+	})
+
+	t.Run("MergeKeepsXuid", func(t *testing.T) {
+		w := importCSV("mode=merge", "name,ignoresPlayerLimit\nAlice,false\n")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Result models.PlayerImportResult `json:"result"`
+		}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, response.Result.AllowlistUpdated)
+
+		data, _ := os.ReadFile(allowlistPath)
+		assert.Contains(t, string(data), "2535400000000001")
+	})
+
+	t.Run("ExportCSV", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/api/allowlist/export?format=csv", nil)
+
+		handler.ExportPlayers(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Bob Builder,2535400000000002,true,operator")
+	})
+
+	t.Run("Replace", func(t *testing.T) {
+		w := importCSV("mode=replace", "name\nOnly One\n")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Result models.PlayerImportResult `json:"result"`
+		}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, response.Result.AllowlistRemoved)
+		assert.Equal(t, 2, response.Result.PermissionsRemoved)
+
+		data, _ := os.ReadFile(allowlistPath)
+		assert.NotContains(t, string(data), "Existing")
+		assert.Contains(t, string(data), "Only One")
+	})
+
+	t.Run("TooLarge", func(t *testing.T) {
+		large := csvData + strings.Repeat("Filler,,false,\n", playerImportMaxSize/10)
+
+		w := importCSV("dry_run=true", large)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		part, err := writer.CreateFormFile("file", "players.csv")
+		assert.NoError(t, err)
+		part.Write([]byte(large))
+		assert.NoError(t, writer.Close())
+
+		w = httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/api/allowlist/import?dry_run=true", &body)
+		c.Request.Header.Set("Content-Type", writer.FormDataContentType())
+
+		handler.ImportPlayers(c)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+
+	t.Run("WriteFailure", func(t *testing.T) {
+		history, err := services.NewConfigHistoryService().GetHistory("", 0)
+		assert.NoError(t, err)
+
+		// A directory in place of the permissions backup makes the permissions write fail
+		assert.NoError(t, os.RemoveAll(permissionsPath+".bak"))
+		assert.NoError(t, os.MkdirAll(filepath.Join(permissionsPath+".bak", "blocked"), 0755))
+
+		w := importCSV("mode=merge", "name,xuid,permission\nLate Comer,2535400000000009,member\n")
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Contains(t, w.Body.String(), "allowlist.json was restored")
+
+		data, _ := os.ReadFile(allowlistPath)
+		assert.NotContains(t, string(data), "Late Comer")
+		assert.Contains(t, string(data), "Only One")
+
+		after, err := services.NewConfigHistoryService().GetHistory("", 0)
+		assert.NoError(t, err)
+		assert.Equal(t, len(history), len(after), "a failed import shouldn't be recorded")
+	})
+}
+
 func TestPermissionHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	
@@ -654,4 +1006,52 @@ func TestResourcePackHandler(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Contains(t, response["error"], "not found")
 	})
-}
\ No newline at end of file
+}
+
+func TestEventHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestEnvironment(t)
+
+	handler := NewEventHandler()
+	events := services.NewEventService()
+
+	services.NewLogService().AddLogEntry("INFO", "event stream test")
+	events.Publish(services.EventTopicStatus, models.ServerStatus{Status: "running"})
+	lastID := events.LastID()
+	events.Publish(services.EventTopicStatus, models.ServerStatus{Status: "stopped"})
+
+	stream := func(t *testing.T, target, lastEventID string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		c.Request = httptest.NewRequest("GET", target, nil).WithContext(ctx)
+		if lastEventID != "" {
+			c.Request.Header.Set("Last-Event-ID", lastEventID)
+		}
+
+		handler.StreamEvents(c)
+		return w
+	}
+
+	t.Run("ResumeFromLastEventID", func(t *testing.T) {
+		w := stream(t, "/api/events?topics=status", fmt.Sprint(lastID-1))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+		body := w.Body.String()
+		assert.Contains(t, body, fmt.Sprintf("id: %d\nevent: status\ndata: {", lastID))
+		assert.Contains(t, body, `"status":"stopped"`)
+		assert.NotContains(t, body, "event stream test")
+	})
+
+	t.Run("InvalidTopic", func(t *testing.T) {
+		w := stream(t, "/api/events?topics=chat", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("InvalidLastEventID", func(t *testing.T) {
+		w := stream(t, "/api/events", "abc")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
 	"minecraft-easyserver/models"
 	"minecraft-easyserver/services"
@@ -11,11 +12,13 @@ import (
 
 type InteractionHandler struct {
 	interactionService *services.InteractionService
+	policyService      *services.CommandPolicyService
 }
 
 func NewInteractionHandler() *InteractionHandler {
 	return &InteractionHandler{
 		interactionService: services.GetInteractionService(),
+		policyService:      services.NewCommandPolicyService(),
 	}
 }
 
@@ -45,10 +48,8 @@ func (h *InteractionHandler) SendCommand(c *gin.Context) {
 	}
 
 	// Validate command
-	if err := h.interactionService.ValidateCommand(req.Command); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+	if err := h.interactionService.ValidateCommand(req.Command, currentRole(c), req.Confirm); err != nil {
+		respondCommandRejected(c, err)
 		return
 	}
 
@@ -87,4 +88,106 @@ func (h *InteractionHandler) ClearHistory(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Command history cleared successfully",
 	})
-}
\ No newline at end of file
+}
+
+// GetCommandPolicy handles GET /api/interaction/policy
+func (h *InteractionHandler) GetCommandPolicy(c *gin.Context) {
+	policy, err := h.policyService.GetPolicy()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// UpdateCommandPolicy handles PUT /api/interaction/policy
+func (h *InteractionHandler) UpdateCommandPolicy(c *gin.Context) {
+	var policy models.CommandPolicy
+	if err := c.ShouldBindJSON(&policy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	if err := h.policyService.UpdatePolicy(policy); err != nil {
+		status := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "invalid") {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Command policy updated successfully",
+		"policy":  policy,
+	})
+}
+
+// ResetCommandPolicy handles POST /api/interaction/policy/reset
+func (h *InteractionHandler) ResetCommandPolicy(c *gin.Context) {
+	policy, err := h.policyService.ResetPolicy()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Command policy reset to defaults",
+		"policy":  policy,
+	})
+}
+
+// TestCommandPolicy handles POST /api/interaction/policy/test
+func (h *InteractionHandler) TestCommandPolicy(c *gin.Context) {
+	var req struct {
+		Command string `json:"command"`
+		Role    string `json:"role"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+	if req.Role == "" {
+		req.Role = currentRole(c)
+	}
+
+	decision, err := h.policyService.Evaluate(req.Command, req.Role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, decision)
+}
+
+// respondCommandRejected reports a command the command policy refused
+func respondCommandRejected(c *gin.Context, err error) {
+	if strings.Contains(err.Error(), "requires confirmation") {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":            err.Error(),
+			"confirm_required": true,
+		})
+		return
+	}
+
+	status := http.StatusBadRequest
+	if strings.Contains(err.Error(), "not allowed") {
+		status = http.StatusForbidden
+	}
+	c.JSON(status, gin.H{
+		"error": err.Error(),
+	})
+}
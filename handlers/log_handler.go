@@ -1,21 +1,26 @@
 package handlers
 
 import (
-	"log"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
 
+	"minecraft-easyserver/models"
 	"minecraft-easyserver/services"
 	"github.com/gin-gonic/gin"
 )
 
 type LogHandler struct {
-	logService *services.LogService
+	logService        *services.LogService
+	sessionLogService *services.SessionLogService
 }
 
 func NewLogHandler() *LogHandler {
 	return &LogHandler{
-		logService: services.NewLogService(),
+		logService:        services.NewLogService(),
+		sessionLogService: services.NewSessionLogService(),
 	}
 }
 
@@ -27,11 +32,21 @@ func (h *LogHandler) GetLogs(c *gin.Context) {
 		limit = 100
 	}
 
-	logs := h.logService.GetLogs(limit)
-	c.JSON(http.StatusOK, gin.H{
-		"logs": logs,
-		"count": len(logs),
-	})
+	result, err := h.logService.Search(logFilterFromQuery(c), c.Query("cursor"), limit)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "invalid") {
+			status = http.StatusBadRequest
+		} else if strings.Contains(err.Error(), "not found") {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
 }
 
 // ClearLogs handles DELETE /api/logs
@@ -46,17 +61,19 @@ func (h *LogHandler) ClearLogs(c *gin.Context) {
 func (h *LogHandler) HandleWebSocket(c *gin.Context) {
 	// Token validation is already done by middleware
 	// Just proceed with WebSocket upgrade
-	h.logService.HandleWebSocket(c.Writer, c.Request)
+	matcher, ok := compileLogFilterQuery(c)
+	if !ok {
+		return
+	}
+	h.logService.HandleWebSocket(c.Writer, c.Request, currentStreamAuth(c), matcher)
 }
 
 // HandleWebSocketWithAuth handles WebSocket connections with JWT authentication
 func (h *LogHandler) HandleWebSocketWithAuth(c *gin.Context) {
-	log.Printf("=== HandleWebSocketWithAuth called ===")
 	// Get token from query parameter
 	tokenString := c.Query("token")
-	log.Printf("WebSocket auth: received token: %s", tokenString[:20]+"...")
 	if tokenString == "" {
-		log.Printf("WebSocket auth: no token provided")
+		slog.DebugContext(c.Request.Context(), "WebSocket auth: no token provided")
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"error": "Token required in query parameter",
 		})
@@ -67,14 +84,103 @@ func (h *LogHandler) HandleWebSocketWithAuth(c *gin.Context) {
 	authService := services.NewAuthService()
 	claims, err := authService.ValidateJWT(tokenString)
 	if err != nil {
-		log.Printf("WebSocket auth: token validation failed: %v", err)
+		slog.DebugContext(c.Request.Context(), "WebSocket auth: token validation failed", "error", err)
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"error": "Invalid or expired token: " + err.Error(),
 		})
 		return
 	}
 
-	log.Printf("WebSocket auth: token validated successfully for claims: %+v", claims)
+	user, err := services.NewUserService().GetUser(claims.UserID)
+	if err != nil {
+		slog.DebugContext(c.Request.Context(), "WebSocket auth: unknown user", "error", err)
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User account no longer exists",
+		})
+		return
+	}
+
+	slog.DebugContext(c.Request.Context(), "WebSocket auth: token validated", "user", user.Username)
 	// If token is valid, proceed with WebSocket upgrade
-	h.logService.HandleWebSocket(c.Writer, c.Request)
-}
\ No newline at end of file
+	matcher, ok := compileLogFilterQuery(c)
+	if !ok {
+		return
+	}
+	auth := services.StreamAuth{UserID: user.ID, SessionID: claims.SessionID}
+	h.logService.HandleWebSocket(c.Writer, c.Request, auth, matcher)
+}
+
+// GetSessions handles GET /api/logs/sessions
+func (h *LogHandler) GetSessions(c *gin.Context) {
+	sessions, err := h.sessionLogService.ListSessions()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"sessions": sessions,
+		"count":    len(sessions),
+	})
+}
+
+// DownloadSession handles GET /api/logs/sessions/:id/download
+func (h *LogHandler) DownloadSession(c *gin.Context) {
+	id := c.Param("id")
+
+	c.Header("Content-Type", "text/plain; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"server-%s.log\"", id))
+
+	err := h.sessionLogService.WriteSession(id, c.Writer)
+	if err == nil {
+		return
+	}
+	if c.Writer.Written() {
+		// Too late to change the response
+		slog.ErrorContext(c.Request.Context(), "Failed to send session log", "session", id, "error", err)
+		return
+	}
+
+	c.Writer.Header().Del("Content-Disposition")
+	status := http.StatusInternalServerError
+	if strings.Contains(err.Error(), "invalid") {
+		status = http.StatusBadRequest
+	} else if strings.Contains(err.Error(), "not found") {
+		status = http.StatusNotFound
+	}
+	c.JSON(status, gin.H{
+		"error": err.Error(),
+	})
+}
+
+// logFilterFromQuery reads a log filter from the level, since, until, q, regex,
+// player and session query parameters
+func logFilterFromQuery(c *gin.Context) models.LogFilter {
+	filter := models.LogFilter{
+		Since:   c.Query("since"),
+		Until:   c.Query("until"),
+		Query:   c.Query("q"),
+		Regex:   c.Query("regex") == "true",
+		Player:  c.Query("player"),
+		Session: c.Query("session"),
+	}
+	if levels := c.Query("level"); levels != "" {
+		filter.Levels = strings.Split(levels, ",")
+	}
+	return filter
+}
+
+// compileLogFilterQuery compiles the log filter of a WebSocket request, responding
+// with 400 if it is invalid
+func compileLogFilterQuery(c *gin.Context) (*services.LogMatcher, bool) {
+	matcher, err := services.CompileLogFilter(logFilterFromQuery(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return nil, false
+	}
+	return matcher, true
+}
@@ -43,19 +43,24 @@ func (h *PermissionHandler) UpdatePermission(c *gin.Context) {
 		return
 	}
 
-	if err := h.permissionService.UpdatePermission(request.Xuid, request.Level); err != nil {
+	live, err := h.permissionService.UpdatePermission(request.Xuid, request.Level, currentUser(c))
+	if err != nil {
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(200, gin.H{"message": fmt.Sprintf("Set %s permission to %s", request.Xuid, request.Level)})
+	c.JSON(200, gin.H{
+		"message": fmt.Sprintf("Set %s permission to %s", request.Xuid, request.Level),
+		"live":    live,
+	})
 }
 
 // RemovePermission removes permission
 func (h *PermissionHandler) RemovePermission(c *gin.Context) {
 	xuid := c.Param("xuid")
 
-	if err := h.permissionService.RemovePermission(xuid); err != nil {
+	live, err := h.permissionService.RemovePermission(xuid, currentUser(c))
+	if err != nil {
 		if strings.Contains(err.Error(), "permission not found") {
 			c.JSON(400, gin.H{"error": err.Error()})
 		} else {
@@ -64,5 +69,8 @@ func (h *PermissionHandler) RemovePermission(c *gin.Context) {
 		return
 	}
 
-	c.JSON(200, gin.H{"message": "Permission removed: " + xuid})
+	c.JSON(200, gin.H{
+		"message": "Permission removed: " + xuid,
+		"live":    live,
+	})
 }
\ No newline at end of file
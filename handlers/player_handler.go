@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"minecraft-easyserver/models"
+	"minecraft-easyserver/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PlayerHandler player moderation handler
+type PlayerHandler struct {
+	playerService *services.PlayerService
+}
+
+// NewPlayerHandler creates a new player moderation handler
+func NewPlayerHandler() *PlayerHandler {
+	return &PlayerHandler{
+		playerService: services.NewPlayerService(),
+	}
+}
+
+// Kick kicks a player
+func (h *PlayerHandler) Kick(c *gin.Context) {
+	var request struct {
+		Reason string `json:"reason"`
+	}
+
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(400, gin.H{"error": "Invalid request data"})
+			return
+		}
+	}
+
+	result, err := h.playerService.Kick(c.Param("name"), request.Reason, currentUser(c))
+	respondModeration(c, "Kick sent", result, err)
+}
+
+// Tell sends a private message to a player
+func (h *PlayerHandler) Tell(c *gin.Context) {
+	var request struct {
+		Message string `json:"message" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	result, err := h.playerService.Tell(c.Param("name"), request.Message, currentUser(c))
+	respondModeration(c, "Message sent", result, err)
+}
+
+// Teleport teleports a player to coordinates or to another player
+func (h *PlayerHandler) Teleport(c *gin.Context) {
+	var request struct {
+		X      *float64 `json:"x"`
+		Y      *float64 `json:"y"`
+		Z      *float64 `json:"z"`
+		Target string   `json:"target"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	var result models.LiveSyncResult
+	var err error
+	switch {
+	case request.Target != "":
+		result, err = h.playerService.TeleportToPlayer(c.Param("name"), request.Target, currentUser(c))
+	case request.X != nil && request.Y != nil && request.Z != nil:
+		result, err = h.playerService.TeleportToPosition(c.Param("name"), *request.X, *request.Y, *request.Z, currentUser(c))
+	default:
+		c.JSON(400, gin.H{"error": "Either target or x, y and z are required"})
+		return
+	}
+	respondModeration(c, "Teleport sent", result, err)
+}
+
+// SetGamemode sets a player's game mode
+func (h *PlayerHandler) SetGamemode(c *gin.Context) {
+	var request struct {
+		Gamemode string `json:"gamemode" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	result, err := h.playerService.SetGamemode(c.Param("name"), request.Gamemode, currentUser(c))
+	respondModeration(c, "Gamemode change sent", result, err)
+}
+
+// GiveEffect gives a player a status effect
+func (h *PlayerHandler) GiveEffect(c *gin.Context) {
+	var request struct {
+		Effect        string `json:"effect" binding:"required"`
+		Duration      int    `json:"duration"`
+		Amplifier     int    `json:"amplifier"`
+		HideParticles bool   `json:"hide_particles"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request data"})
+		return
+	}
+	if request.Duration == 0 {
+		request.Duration = 30
+	}
+
+	result, err := h.playerService.GiveEffect(c.Param("name"), request.Effect, request.Duration, request.Amplifier, request.HideParticles, currentUser(c))
+	respondModeration(c, "Effect sent", result, err)
+}
+
+// ClearInventory clears a player's inventory
+func (h *PlayerHandler) ClearInventory(c *gin.Context) {
+	var request struct {
+		Item string `json:"item"`
+	}
+
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(400, gin.H{"error": "Invalid request data"})
+			return
+		}
+	}
+
+	result, err := h.playerService.ClearInventory(c.Param("name"), request.Item, currentUser(c))
+	respondModeration(c, "Clear sent", result, err)
+}
+
+// GetAudit gets the moderation audit trail
+func (h *PlayerHandler) GetAudit(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil {
+		limit = 100
+	}
+
+	audit, err := h.playerService.GetAudit(c.Query("player"), limit)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Failed to read moderation audit trail: " + err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"audit": audit,
+		"count": len(audit),
+	})
+}
+
+// respondModeration writes the response for a moderation action
+func respondModeration(c *gin.Context, message string, result models.LiveSyncResult, err error) {
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(400, gin.H{"error": err.Error()})
+		} else if strings.Contains(err.Error(), "not running") || strings.Contains(err.Error(), "not supported") {
+			c.JSON(409, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(500, gin.H{"error": "Failed to send command: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": message,
+		"result":  result,
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"minecraft-easyserver/services"
@@ -69,26 +70,74 @@ func (h *ResourcePackHandler) UploadResourcePack(c *gin.Context) {
 	}
 	out.Close()
 
-	// Upload and extract resource pack
-	packInfo, err := h.resourcePackService.UploadResourcePack(tempPath, filename)
+	// Upload and extract resource pack; "conflict" decides how an already installed UUID is handled
+	conflictMode := c.PostForm("conflict")
+	packInfo, err := h.resourcePackService.UploadResourcePack(tempPath, filename, conflictMode)
 	if err != nil {
 		// Clean up temporary file
 		os.Remove(tempPath)
-		c.JSON(500, gin.H{"error": "Failed to process resource pack: " + err.Error()})
+		if strings.Contains(err.Error(), "already installed") {
+			c.JSON(409, gin.H{
+				"error":   err.Error(),
+				"options": []string{services.PackConflictReplace, services.PackConflictKeep, services.PackConflictReject},
+			})
+		} else if strings.Contains(err.Error(), "invalid conflict mode") {
+			c.JSON(400, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(500, gin.H{"error": "Failed to process resource pack: " + err.Error()})
+		}
+		return
+	}
+
+	message := "Resource pack uploaded successfully"
+	if packInfo.PreviousVersion != nil {
+		message = "Resource pack replaced, restart server to take effect"
+	}
+
+	c.JSON(200, gin.H{
+		"message":       message,
+		"resource_pack": packInfo,
+	})
+}
+
+// RollbackResourcePack restores the previous version of a replaced resource pack
+func (h *ResourcePackHandler) RollbackResourcePack(c *gin.Context) {
+	packUUID := c.Param("uuid")
+
+	packInfo, err := h.resourcePackService.RollbackResourcePack(packUUID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(404, gin.H{"error": err.Error()})
+		} else if strings.Contains(err.Error(), "no previous version") {
+			c.JSON(400, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(500, gin.H{"error": "Failed to roll back resource pack: " + err.Error()})
+		}
 		return
 	}
 
 	c.JSON(200, gin.H{
-		"message":       "Resource pack uploaded successfully",
+		"message":       "Resource pack rolled back, restart server to take effect",
 		"resource_pack": packInfo,
 	})
 }
 
-// ActivateResourcePack activates resource pack
+// ActivateResourcePack activates resource pack, optionally with a chosen subpack
 func (h *ResourcePackHandler) ActivateResourcePack(c *gin.Context) {
 	packUUID := c.Param("uuid")
 
-	if err := h.resourcePackService.ActivateResourcePack(packUUID); err != nil {
+	// Request body is optional; it only carries the subpack selection
+	var request struct {
+		Subpack string `json:"subpack"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(400, gin.H{"error": "Invalid request data"})
+			return
+		}
+	}
+
+	if err := h.resourcePackService.ActivateResourcePack(packUUID, request.Subpack); err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			c.JSON(404, gin.H{"error": err.Error()})
 		} else if strings.Contains(err.Error(), "already activated") {
@@ -138,4 +187,69 @@ func (h *ResourcePackHandler) DeleteResourcePack(c *gin.Context) {
 	}
 
 	c.JSON(200, gin.H{"message": "Resource pack deleted successfully"})
-}
\ No newline at end of file
+}
+
+// GetPackSettings gets the server-side pack enforcement settings
+func (h *ResourcePackHandler) GetPackSettings(c *gin.Context) {
+	settings, err := h.resourcePackService.GetPackSettings()
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Failed to read resource pack settings: " + err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"settings": settings})
+}
+
+// UpdatePackSettings updates the server-side pack enforcement settings.
+// Only the fields present in the request are changed.
+func (h *ResourcePackHandler) UpdatePackSettings(c *gin.Context) {
+	var request struct {
+		TexturepackRequired   *bool `json:"texturepack_required"`
+		ContentLogFileEnabled *bool `json:"content_log_file_enabled"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	settings, err := h.resourcePackService.GetPackSettings()
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Failed to read resource pack settings: " + err.Error()})
+		return
+	}
+	if request.TexturepackRequired != nil {
+		settings.TexturepackRequired = *request.TexturepackRequired
+	}
+	if request.ContentLogFileEnabled != nil {
+		settings.ContentLogFileEnabled = *request.ContentLogFileEnabled
+	}
+
+	if err := h.resourcePackService.UpdatePackSettings(settings, currentUser(c)); err != nil {
+		c.JSON(500, gin.H{"error": "Failed to save resource pack settings: " + err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message":  "Resource pack settings saved, restart server to take effect",
+		"settings": settings,
+	})
+}
+
+// GetContentLog gets pack errors parsed from bedrock's content log
+func (h *ResourcePackHandler) GetContentLog(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "200"))
+	if err != nil {
+		limit = 200
+	}
+
+	entries, err := h.resourcePackService.GetContentLog(limit, c.Query("level"))
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Failed to read content log: " + err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"entries": entries,
+		"count":   len(entries),
+	})
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"minecraft-easyserver/models"
+	"minecraft-easyserver/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserHandler user account handler
+type UserHandler struct {
+	userService *services.UserService
+}
+
+// NewUserHandler creates a new user handler
+func NewUserHandler() *UserHandler {
+	return &UserHandler{
+		userService: services.NewUserService(),
+	}
+}
+
+// GetUsers handles GET /api/users
+func (h *UserHandler) GetUsers(c *gin.Context) {
+	users, err := h.userService.ListUsers()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read users: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"users": users,
+		"count": len(users),
+	})
+}
+
+// CreateUser handles POST /api/users
+func (h *UserHandler) CreateUser(c *gin.Context) {
+	var request models.CreateUserRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	actor, _ := currentAccount(c)
+	user, err := h.userService.CreateUser(request, actor)
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User created",
+		"user":    user,
+	})
+}
+
+// UpdateUser handles PUT /api/users/:id
+func (h *UserHandler) UpdateUser(c *gin.Context) {
+	var request models.UpdateUserRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	actor, _ := currentAccount(c)
+	user, err := h.userService.UpdateUser(c.Param("id"), request, actor)
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User updated",
+		"user":    user,
+	})
+}
+
+// DeleteUser handles DELETE /api/users/:id
+func (h *UserHandler) DeleteUser(c *gin.Context) {
+	actor, _ := currentAccount(c)
+	if err := h.userService.DeleteUser(c.Param("id"), actor); err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User deleted"})
+}
+
+// respondUserError maps a user service error to a response
+func respondUserError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		status = http.StatusNotFound
+	case strings.Contains(err.Error(), "already exists"):
+		status = http.StatusConflict
+	case strings.Contains(err.Error(), "not allowed"):
+		status = http.StatusForbidden
+	case strings.Contains(err.Error(), "invalid"):
+		status = http.StatusBadRequest
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
@@ -100,7 +101,7 @@ func (h *WorldHandler) UploadWorld(c *gin.Context) {
 		// Delete original compressed file after successful extraction
 		if err := os.Remove(uploadPath); err != nil {
 			// Log warning but don't affect main flow
-			fmt.Printf("Warning: Failed to delete compressed file: %v\n", err)
+			slog.WarnContext(c.Request.Context(), "Failed to delete uploaded world archive", "file", uploadPath, "error", err)
 		}
 
 		c.JSON(200, gin.H{"message": fmt.Sprintf("World file uploaded and extracted successfully: %s", extractedWorldName)})
@@ -113,7 +114,7 @@ func (h *WorldHandler) UploadWorld(c *gin.Context) {
 func (h *WorldHandler) DeleteWorld(c *gin.Context) {
 	worldName := c.Param("name")
 
-	if err := h.worldService.DeleteWorld(worldName); err != nil {
+	if err := h.worldService.DeleteWorld(worldName, currentUser(c)); err != nil {
 		// Return different status codes based on error type
 		if strings.Contains(err.Error(), "world not found") {
 			c.JSON(404, gin.H{"error": err.Error()})
@@ -132,7 +133,7 @@ func (h *WorldHandler) DeleteWorld(c *gin.Context) {
 func (h *WorldHandler) ActivateWorld(c *gin.Context) {
 	worldName := c.Param("name")
 
-	if err := h.worldService.ActivateWorld(worldName); err != nil {
+	if err := h.worldService.ActivateWorld(worldName, currentUser(c)); err != nil {
 		c.JSON(500, gin.H{"error": "Failed to activate world: " + err.Error()})
 		return
 	}
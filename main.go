@@ -4,11 +4,15 @@ import (
 	"embed"
 	"fmt"
 	"io/fs"
-	"log"
+	"log/slog"
 	"minecraft-easyserver/config"
+	"minecraft-easyserver/middleware"
 	"minecraft-easyserver/routes"
 	"minecraft-easyserver/services"
+	"minecraft-easyserver/utils"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -20,39 +24,83 @@ var webFS embed.FS
 func main() {	
 	// Load configuration
 	if err := config.LoadConfig(config.DefaultConfigPath); err != nil {
-		log.Fatalf("Failed to load configuration file 'config/config.yml': %v\nPlease ensure the config/config.yml file exists and is properly formatted.", err)
+		fatal("Failed to load configuration file 'config/config.yml'. Please ensure the config/config.yml file exists and is properly formatted.", "error", err)
 	}
-	log.Printf("Configuration loaded successfully. Server will run on port %d, debug mode: %v", 
-		config.AppConfig.Server.Port, config.AppConfig.Server.Debug)
+
+	// Set up the panel's own log before anything else logs
+	maxSizeMB := config.AppConfig.Logging.MaxSizeMB
+	if maxSizeMB == 0 {
+		maxSizeMB = 10
+	}
+	loggerOptions := utils.LoggerOptions{
+		Level:    config.AppConfig.Logging.Level,
+		Format:   config.AppConfig.Logging.Format,
+		MaxSize:  int64(maxSizeMB) * 1024 * 1024,
+		MaxAge:   time.Duration(config.AppConfig.Logging.MaxAgeDays) * 24 * time.Hour,
+		Compress: config.AppConfig.Logging.Compress,
+	}
+	if config.AppConfig.Logging.FileOutput {
+		loggerOptions.FilePath = config.AppConfig.GetPanelLogPath()
+	}
+	logFile, err := utils.SetupLogger(loggerOptions)
+	if err != nil {
+		fatal("Failed to set up logging", "error", err)
+	}
+	defer logFile.Close()
+
+	slog.Info("Configuration loaded successfully",
+		"port", config.AppConfig.Server.Port, "debug", config.AppConfig.Server.Debug)
 
 	// Set Gin mode based on configuration
 	if !config.AppConfig.Server.Debug {
-		log.Println("Setting Gin to release mode")
+		slog.Debug("Setting Gin to release mode")
 		gin.SetMode(gin.ReleaseMode)
 	} else {
-		log.Println("Running in debug mode")
+		slog.Info("Running in debug mode")
 	}
+	// Route gin's own output through the panel log
+	gin.DefaultWriter = utils.LogWriter(slog.LevelDebug)
+	gin.DefaultErrorWriter = utils.LogWriter(slog.LevelError)
 
 	// Initialize bedrock path (don't fail if path doesn't exist yet)
 	// Users can now select and activate versions through the web interface
 	bedrockPath := config.AppConfig.GetBedrockPath()
 	if bedrockPath != "" {
-		log.Printf("Configured bedrock path: %s", bedrockPath)
+		slog.Info("Configured bedrock path", "path", bedrockPath)
 		// Try to initialize, but don't fail if it doesn't exist
 		if err := services.InitBedrockPath(bedrockPath); err != nil {
-			log.Printf("Warning: Bedrock path initialization failed (%s). Users can download and activate versions through the web interface. Error: %v", bedrockPath, err)
+			slog.Warn("Bedrock path initialization failed. Users can download and activate versions through the web interface.", "path", bedrockPath, "error", err)
 			// Set an empty path to indicate no version is currently active
 			services.SetBedrockPath("")
 		} else {
-			log.Printf("Bedrock path initialized successfully: %s", bedrockPath)
+			slog.Info("Bedrock path initialized successfully", "path", bedrockPath)
 		}
 	} else {
-		log.Printf("No bedrock path configured. Users can download and activate versions through the web interface.")
+		slog.Info("No bedrock path configured. Users can download and activate versions through the web interface.")
 		services.SetBedrockPath("")
 	}
 
+	// Write each server session's output to its own log file
+	services.NewSessionLogService().Configure(services.SessionLogOptions{
+		Enabled:  config.AppConfig.Logging.FileOutput,
+		Dir:      config.AppConfig.GetSessionLogDir(),
+		Prefix:   config.AppConfig.GetSessionLogPrefix(),
+		MaxSize:  int64(maxSizeMB) * 1024 * 1024,
+		MaxAge:   time.Duration(config.AppConfig.Logging.MaxAgeDays) * 24 * time.Hour,
+		Compress: config.AppConfig.Logging.Compress,
+	})
+	if config.AppConfig.Logging.FileOutput {
+		slog.Info("Server session logs will be written", "dir", config.AppConfig.GetSessionLogDir())
+	}
+
+	// Load user accounts now, so a bootstrap password is printed at first start
+	if err := services.NewUserService().Init(); err != nil {
+		fatal("Failed to load user accounts", "error", err)
+	}
+
 	// Create Gin engine
-	r := gin.Default()
+	r := gin.New()
+	r.Use(middleware.RequestID(), middleware.RequestLogger(), gin.Recovery())
 
 	// Setup CORS middleware
 	r.Use(cors.New(cors.Config{
@@ -66,7 +114,7 @@ func main() {
 	// Setup embedded static files
 	webSubFS, err := fs.Sub(webFS, "web/dist")
 	if err != nil {
-		log.Fatalf("Failed to create web sub filesystem: %v\nThis indicates an issue with embedded files compilation.", err)
+		fatal("Failed to create web sub filesystem. This indicates an issue with embedded files compilation.", "error", err)
 	}
 
 	// Static file service using embedded files
@@ -77,7 +125,7 @@ func main() {
 	r.GET("/bundle.js", func(c *gin.Context) {
 		bundleFile, err := webSubFS.Open("bundle.js")
 		if err != nil {
-			log.Printf("Error serving bundle.js: %v", err)
+			slog.ErrorContext(c.Request.Context(), "Error serving bundle.js", "error", err)
 			c.String(404, "bundle.js not found")
 			return
 		}
@@ -89,7 +137,7 @@ func main() {
 	// Load HTML template from embedded files
 	tmpl, err := webSubFS.Open("index.html")
 	if err != nil {
-		log.Fatalf("Failed to open index.html: %v\nThis indicates missing index.html in embedded files.", err)
+		fatal("Failed to open index.html. This indicates missing index.html in embedded files.", "error", err)
 	}
 	tmpl.Close()
 
@@ -97,7 +145,7 @@ func main() {
 	r.GET("/", func(c *gin.Context) {
 		indexFile, err := webSubFS.Open("index.html")
 		if err != nil {
-			log.Printf("Error serving index.html: %v", err)
+			slog.ErrorContext(c.Request.Context(), "Error serving index.html", "error", err)
 			c.String(500, "Failed to load index.html")
 			return
 		}
@@ -120,7 +168,7 @@ func main() {
 		// Serve index.html for all other routes (SPA routing)
 		indexFile, err := webSubFS.Open("index.html")
 		if err != nil {
-			log.Printf("Error serving index.html for SPA route %s: %v", c.Request.URL.Path, err)
+			slog.ErrorContext(c.Request.Context(), "Error serving index.html for SPA route", "path", c.Request.URL.Path, "error", err)
 			c.String(500, "Failed to load index.html")
 			return
 		}
@@ -130,12 +178,18 @@ func main() {
 
 	// Start server
 	serverAddr := config.AppConfig.GetServerAddress()
-	log.Printf("Starting HTTP server on %s...", serverAddr)
-	log.Printf("Server will be accessible at http://%s", serverAddr)
+	slog.Info("Starting HTTP server", "address", serverAddr)
+	slog.Info("Server will be accessible", "url", "http://"+serverAddr)
 	
 	port := fmt.Sprintf(":%d", config.AppConfig.Server.Port)
 	
 	if err := r.Run(port); err != nil {
-		log.Fatalf("Failed to start server on port %s: %v\nPossible causes:\n- Port %d is already in use\n- Insufficient permissions\n- Invalid port number", port, err, config.AppConfig.Server.Port)
+		fatal("Failed to start server. Possible causes: the port is already in use, insufficient permissions or an invalid port number.", "port", config.AppConfig.Server.Port, "error", err)
 	}
 }
+
+// fatal logs an error and exits
+func fatal(message string, args ...any) {
+	slog.Error(message, args...)
+	os.Exit(1)
+}
@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"fmt"
+	"minecraft-easyserver/models"
 	"minecraft-easyserver/services"
 	"net/http"
 	"strings"
@@ -8,9 +10,11 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// JWTAuthMiddleware creates a JWT authentication middleware
+// JWTAuthMiddleware creates a JWT authentication middleware. It also accepts API
+// tokens; routes limit what those can do with RequireScope.
 func JWTAuthMiddleware() gin.HandlerFunc {
 	authService := services.NewAuthService()
+	apiTokenService := services.NewAPITokenService()
 
 	return func(c *gin.Context) {
 		// Get the Authorization header
@@ -42,6 +46,25 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// API tokens act as their user, limited to their scopes
+		if strings.HasPrefix(tokenString, services.APITokenPrefix) {
+			apiToken, err := apiTokenService.Authenticate(tokenString)
+			if err != nil {
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"error": "Invalid or expired token: " + err.Error(),
+				})
+				c.Abort()
+				return
+			}
+
+			if !setUser(c, apiToken.UserID) {
+				return
+			}
+			c.Set("api_token", apiToken)
+			c.Next()
+			return
+		}
+
 		// Validate the token
 		claims, err := authService.ValidateJWT(tokenString)
 		if err != nil {
@@ -52,7 +75,9 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// Store claims in context for use in handlers
+		if !setUser(c, claims.UserID) {
+			return
+		}
 		c.Set("claims", claims)
 		c.Next()
 	}
@@ -84,8 +109,86 @@ func WebSocketAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// Store claims in context for use in handlers
+		if !setUser(c, claims.UserID) {
+			return
+		}
 		c.Set("claims", claims)
 		c.Next()
 	}
-}
\ No newline at end of file
+}
+
+// RequirePermission creates a middleware that only lets users whose role grants
+// permission through. It must run after JWTAuthMiddleware or WebSocketAuthMiddleware.
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, _ := c.Get("user")
+		user, ok := value.(models.User)
+		if !ok || !services.RoleHasPermission(user.Role, permission) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": fmt.Sprintf("Permission denied: requires '%s' permission", permission),
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireScope creates a middleware that only lets API tokens with scope through.
+// Requests authenticated by logging in aren't limited by scopes.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, exists := c.Get("api_token")
+		if !exists {
+			c.Next()
+			return
+		}
+		apiToken, ok := value.(models.APIToken)
+		if !ok || !services.APITokenHasScope(apiToken, scope) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": fmt.Sprintf("Permission denied: API token requires '%s' scope", scope),
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireSession creates a middleware that rejects API tokens, for account routes
+// that need a logged-in user
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, exists := c.Get("api_token"); exists {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Permission denied: not available to API tokens, log in instead",
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// setUser stores the user making the request in the context for use in handlers.
+// The user is looked up on every request so role changes and deleted accounts take
+// effect immediately.
+func setUser(c *gin.Context, userID string) bool {
+	user, err := services.NewUserService().GetUser(userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User account no longer exists",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to load user account: " + err.Error(),
+			})
+		}
+		c.Abort()
+		return false
+	}
+
+	c.Set("user", user)
+	return true
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"regexp"
+	"time"
+
+	"minecraft-easyserver/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader header carrying the request ID in both directions
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDPattern request IDs accepted from clients
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestID creates a middleware giving every request an ID. A valid X-Request-ID
+// from the client is kept, otherwise one is generated. The ID is returned in the
+// response header and added to every log record written with the request context.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !requestIDPattern.MatchString(id) {
+			id = newRequestID()
+		}
+
+		c.Set("request_id", id)
+		c.Header(RequestIDHeader, id)
+		c.Request = c.Request.WithContext(utils.ContextWithRequestID(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+// RequestLogger creates a middleware logging each request once it completes.
+// Server errors are logged as errors and client errors as warnings.
+func RequestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		if status >= 500 {
+			level = slog.LevelError
+		} else if status >= 400 {
+			level = slog.LevelWarn
+		}
+
+		// Query strings may carry tokens; the logger redacts them
+		path := c.Request.URL.Path
+		if query := c.Request.URL.RawQuery; query != "" {
+			path += "?" + query
+		}
+
+		attrs := []any{
+			"method", c.Request.Method,
+			"path", path,
+			"status", status,
+			"latency", time.Since(start),
+			"client_ip", c.ClientIP(),
+			"size", c.Writer.Size(),
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, "errors", c.Errors.String())
+		}
+		slog.Log(c.Request.Context(), level, "HTTP request", attrs...)
+	}
+}
+
+// newRequestID generates a random request ID
+func newRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return time.Now().Format("20060102150405.000000000")
+	}
+	return hex.EncodeToString(b)
+}
@@ -1,15 +1,52 @@
 package models
 
-// LoginRequest login request structure
+// LoginRequest login request structure; without a username the owner account
+// created from the original single password is used
 type LoginRequest struct {
+	Username string `json:"username"`
 	Password string `json:"password" binding:"required"`
 }
 
-// LoginResponse login response structure
+// LoginResponse login response structure; also returned when tokens are refreshed
 type LoginResponse struct {
 	Token              string `json:"token"`
+	RefreshToken       string `json:"refresh_token"`
+	ExpiresIn          int64  `json:"expires_in"` // Access token lifetime in seconds
 	Message            string `json:"message"`
 	RequirePasswordChange bool   `json:"requirePasswordChange"`
+	User               *User  `json:"user,omitempty"`
+	// With two-factor authentication the password only earns a challenge token, which
+	// is exchanged for the tokens together with a code
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+	ChallengeToken    string `json:"challenge_token,omitempty"`
+}
+
+// TwoFactorLoginRequest second login step request structure
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"` // TOTP or recovery code
+}
+
+// TwoFactorCodeRequest request confirming a two-factor change with a code
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// DisableTwoFactorRequest disable two-factor authentication request structure
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"` // TOTP or recovery code
+}
+
+// TwoFactorSetup secret for enrolling an authenticator app
+type TwoFactorSetup struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// RefreshRequest refresh token request structure
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
 }
 
 // ChangePasswordRequest change password request structure
@@ -27,11 +64,263 @@ type ChangePasswordResponse struct {
 // JWTClaims JWT claims structure
 type JWTClaims struct {
 	Authorized bool   `json:"authorized"`
+	UserID     string `json:"user_id"`
+	SessionID  string `json:"jti"`
 	Exp        int64  `json:"exp"`
 	Iat        int64  `json:"iat"`
 }
 
-// ServerConfig server configuration structure
+// Session login session. Access tokens carry its ID as their jti claim, and its
+// refresh token is rotated every time it is used.
+type Session struct {
+	ID          string `json:"id"`
+	UserID      string `json:"user_id"`
+	IP          string `json:"ip"`
+	UserAgent   string `json:"user_agent"`
+	CreatedAt   string `json:"created_at"`
+	RefreshedAt string `json:"refreshed_at"`
+	ExpiresAt   string `json:"expires_at"`
+	Current     bool   `json:"current"`
+}
+
+// API token scopes. Each covers reading or changing one area of the API; a token
+// can only do what both its scopes and its user's role allow.
+const (
+	ScopeServerRead    = "server:read"    // Status, performance and event stream
+	ScopeServerControl = "server:control" // Start, stop and restart
+	ScopeConfigRead    = "config:read"    // Configuration, permissions, worlds, packs and versions
+	ScopeConfigWrite   = "config:write"
+	ScopePlayersRead   = "players:read" // Allowlist, bans and player audit
+	ScopePlayersWrite  = "players:write"
+	ScopeLogsRead      = "logs:read"
+	ScopeLogsWrite     = "logs:write"
+	ScopeConsoleRead   = "console:read" // Commands, command history and policy
+	ScopeConsoleWrite  = "console:write"
+	ScopeUsersRead     = "users:read"
+	ScopeUsersWrite    = "users:write"
+)
+
+// APIToken personal access token used by scripts and bots instead of logging in
+type APIToken struct {
+	ID         string   `json:"id"`
+	UserID     string   `json:"user_id"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix"` // Start of the token, to recognise it
+	Scopes     []string `json:"scopes"`
+	CreatedAt  string   `json:"created_at"`
+	ExpiresAt  string   `json:"expires_at,omitempty"` // Empty means it never expires
+	LastUsedAt string   `json:"last_used_at,omitempty"`
+}
+
+// CreateAPITokenRequest create API token request structure
+type CreateAPITokenRequest struct {
+	Name          string   `json:"name" binding:"required"`
+	Scopes        []string `json:"scopes" binding:"required"`
+	ExpiresInDays int      `json:"expires_in_days"` // 0 means it never expires
+}
+
+// User roles, from most to least privileged
+const (
+	RoleOwner     = "owner"
+	RoleAdmin     = "admin"
+	RoleModerator = "moderator"
+	RoleViewer    = "viewer"
+)
+
+// Permissions granted by roles
+const (
+	PermissionView     = "view"     // Read status, settings, logs and lists
+	PermissionModerate = "moderate" // Moderate players: kick, message, ban, allowlist, commands
+	PermissionControl  = "control"  // Start, stop and restart the server
+	PermissionManage   = "manage"   // Change settings, worlds, packs, versions and policies
+	PermissionUsers    = "users"    // Manage panel user accounts
+)
+
+// User panel user account
+type User struct {
+	ID               string `json:"id"`
+	Username         string `json:"username"`
+	Role             string `json:"role"`
+	TwoFactorEnabled bool   `json:"two_factor_enabled"`
+	CreatedAt        string `json:"created_at"`
+	UpdatedAt        string `json:"updated_at"`
+}
+
+// CreateUserRequest create user request structure
+type CreateUserRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	Role     string `json:"role" binding:"required"`
+}
+
+// UpdateUserRequest update user request structure; empty fields are left unchanged
+type UpdateUserRequest struct {
+	Role     string `json:"role,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// ServerProperties server.properties values keyed by property name,
+// typed according to the server properties schema
+type ServerProperties map[string]interface{}
+
+// Server property value types
+const (
+	PropertyTypeString = "string"
+	PropertyTypeInt    = "int"
+	PropertyTypeFloat  = "float"
+	PropertyTypeBool   = "bool"
+	PropertyTypeEnum   = "enum"
+)
+
+// ServerPropertySchema describes a single server.properties key
+type ServerPropertySchema struct {
+	Key         string   `json:"key"`
+	Type        string   `json:"type"`
+	Allowed     []string `json:"allowed,omitempty"`
+	Min         *float64 `json:"min,omitempty"`
+	Max         *float64 `json:"max,omitempty"`
+	Default     string   `json:"default"`
+	Description string   `json:"description"`
+}
+
+// ConfigUpdateResult outcome of a server.properties update
+type ConfigUpdateResult struct {
+	ChangedKeys         []string `json:"changed_keys"`
+	AppliedLive         []string `json:"applied_live"`
+	RestartRequiredKeys []string `json:"restart_required_keys"`
+}
+
+// LiveSyncResult outcome of pushing a change to the running server.
+// Confirmed is set once the server's console output acknowledged the command.
+type LiveSyncResult struct {
+	ServerRunning bool   `json:"server_running"`
+	Command       string `json:"command,omitempty"`
+	Confirmed     bool   `json:"confirmed"`
+	Response      string `json:"response,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+// PlayerAccessEntry combined allowlist and permission row used for bulk import/export.
+// Rows without a name only set a permission; rows without a permission only touch the allowlist.
+type PlayerAccessEntry struct {
+	Name               string `json:"name"`
+	Xuid               string `json:"xuid"`
+	IgnoresPlayerLimit bool   `json:"ignoresPlayerLimit"`
+	Permission         string `json:"permission"`
+}
+
+// PlayerImportRowError problem with a single row of a bulk import
+type PlayerImportRowError struct {
+	Row   int    `json:"row"`
+	Name  string `json:"name,omitempty"`
+	Error string `json:"error"`
+}
+
+// PlayerImportResult outcome or dry-run preview of a bulk import
+type PlayerImportResult struct {
+	Mode               string                 `json:"mode"`
+	DryRun             bool                   `json:"dry_run"`
+	Rows               int                    `json:"rows"`
+	AllowlistAdded     int                    `json:"allowlist_added"`
+	AllowlistUpdated   int                    `json:"allowlist_updated"`
+	AllowlistRemoved   int                    `json:"allowlist_removed"`
+	PermissionsSet     int                    `json:"permissions_set"`
+	PermissionsRemoved int                    `json:"permissions_removed"`
+	Errors             []PlayerImportRowError `json:"errors"`
+	Live               []LiveSyncResult       `json:"live,omitempty"`
+}
+
+// Ban panel-managed ban by player name and/or XUID.
+// ExpiresAt is empty for permanent bans.
+type Ban struct {
+	ID        string `json:"id"`
+	Name      string `json:"name,omitempty"`
+	Xuid      string `json:"xuid,omitempty"`
+	Reason    string `json:"reason"`
+	IssuedBy  string `json:"issued_by"`
+	CreatedAt string `json:"created_at"`
+	ExpiresAt string `json:"expires_at,omitempty"`
+	Active    bool   `json:"active"`
+}
+
+// BanAuditEntry record of a ban being created, changed, lifted or enforced
+type BanAuditEntry struct {
+	Timestamp string `json:"timestamp"`
+	Action    string `json:"action"`
+	User      string `json:"user"`
+	BanID     string `json:"ban_id"`
+	Name      string `json:"name,omitempty"`
+	Xuid      string `json:"xuid,omitempty"`
+	Details   string `json:"details,omitempty"`
+}
+
+// ModerationAuditEntry record of a moderation action sent to the server
+type ModerationAuditEntry struct {
+	Timestamp string `json:"timestamp"`
+	Action    string `json:"action"`
+	User      string `json:"user"`
+	Player    string `json:"player"`
+	Command   string `json:"command"`
+	Confirmed bool   `json:"confirmed"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Command policy rule actions
+const (
+	CommandRuleAllow   = "allow"
+	CommandRuleDeny    = "deny"
+	CommandRuleConfirm = "confirm"
+)
+
+// CommandRule console command policy rule. Commands lists command names ("*" matches
+// any command); ArgsPattern is an optional regular expression matched against the arguments.
+type CommandRule struct {
+	ID          string   `json:"id"`
+	Action      string   `json:"action"`
+	Commands    []string `json:"commands"`
+	ArgsPattern string   `json:"args_pattern,omitempty"`
+	Description string   `json:"description,omitempty"`
+}
+
+// CommandRolePolicy ordered rules for one role; the first matching rule wins
+type CommandRolePolicy struct {
+	DefaultAction string        `json:"default_action"`
+	Rules         []CommandRule `json:"rules"`
+}
+
+// CommandPolicy console command policy. Owners and admins without their own entry use
+// "default"; moderators and viewers without one get the built-in rules.
+type CommandPolicy struct {
+	MaxLength int                          `json:"max_length"`
+	Roles     map[string]CommandRolePolicy `json:"roles"`
+}
+
+// CommandDecision result of checking a command against the policy
+type CommandDecision struct {
+	Command string `json:"command"`
+	Role    string `json:"role"`
+	Action  string `json:"action"`
+	RuleID  string `json:"rule_id,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// ConfigPreset named partial set of server.properties values saved by the panel
+type ConfigPreset struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Properties  ServerProperties `json:"properties"`
+	CreatedAt   string           `json:"created_at"`
+}
+
+// ConfigPresetDiff difference between a preset value and the live server.properties value.
+// Current is nil when the key is missing from server.properties.
+type ConfigPresetDiff struct {
+	Key     string      `json:"key"`
+	Current interface{} `json:"current"`
+	Preset  interface{} `json:"preset"`
+}
+
+// ServerConfig typed view of the server.properties keys used by the panel itself
 type ServerConfig struct {
 	ServerName              string `json:"server-name"`
 	Gamemode                string `json:"gamemode"`
@@ -45,9 +334,27 @@ type ServerConfig struct {
 	DefaultPlayerPermission string `json:"default-player-permission-level"`
 }
 
+// ConfigChange recorded change to server.properties, allowlist.json or permissions.json
+type ConfigChange struct {
+	ID         string `json:"id"`
+	File       string `json:"file"`
+	User       string `json:"user"`
+	Timestamp  string `json:"timestamp"`
+	Added      int    `json:"added"`
+	Removed    int    `json:"removed"`
+	RollbackOf string `json:"rollback_of,omitempty"`
+}
+
+// ConfigDiffLine single line of a config change diff; Op is "+", "-" or " "
+type ConfigDiffLine struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
 // AllowlistEntry allowlist entry
 type AllowlistEntry struct {
 	Name               string `json:"name"`
+	Xuid               string `json:"xuid,omitempty"`
 	IgnoresPlayerLimit bool   `json:"ignoresPlayerLimit"`
 }
 
@@ -68,6 +375,10 @@ type ServerStatus struct {
 	Status  string `json:"status"`
 	Message string `json:"message"`
 	PID     int    `json:"pid,omitempty"`
+
+	// Set while config changes are waiting for a restart to take effect
+	RestartPending     bool     `json:"restart_pending"`
+	RestartPendingKeys []string `json:"restart_pending_keys,omitempty"`
 }
 
 // ResourcePackManifest resource pack manifest structure
@@ -111,11 +422,34 @@ type ResourcePackInfo struct {
 	Description string `json:"description"`
 	FolderName  string `json:"folder_name"`
 	Active      bool   `json:"active"`
+	// PreviousVersion is the version a rollback would restore, if one is kept
+	PreviousVersion *[3]int `json:"previous_version,omitempty"`
+	// Subpacks lists the selectable subpacks declared in the manifest
+	Subpacks      []ResourcePackSubpack `json:"subpacks,omitempty"`
+	ActiveSubpack string                `json:"active_subpack,omitempty"`
+}
+
+// ResourcePackSettings server-side pack enforcement settings from server.properties
+type ResourcePackSettings struct {
+	TexturepackRequired   bool `json:"texturepack_required"`
+	ContentLogFileEnabled bool `json:"content_log_file_enabled"`
+}
+
+// ContentLogEntry entry parsed from bedrock's content log
+type ContentLogEntry struct {
+	Timestamp string `json:"timestamp"`
+	Category  string `json:"category"`
+	Level     string `json:"level"`
+	Message   string `json:"message"`
+	File      string `json:"file"`
+	PackUUID  string `json:"pack_uuid,omitempty"`
+	PackName  string `json:"pack_name,omitempty"`
 }
 
 // WorldResourcePack world resource pack configuration entry
 type WorldResourcePack struct {
 	PackID  string `json:"pack_id"`
+	Subpack string `json:"subpack,omitempty"`
 	Version [3]int `json:"version"`
 }
 
@@ -157,15 +491,97 @@ type DownloadProgress struct {
 
 // ServerLogEntry server log entry
 type ServerLogEntry struct {
+	ID        int64  `json:"id,omitempty"`
 	Timestamp string `json:"timestamp"`
 	Level     string `json:"level"`
 	Message   string `json:"message"`
+	Source    string `json:"source,omitempty"`
+	Category  string `json:"category,omitempty"`
+	Event     string `json:"event,omitempty"`
+	Player    string `json:"player,omitempty"`
+	Xuid      string `json:"xuid,omitempty"`
+}
+
+// LogFilter criteria for searching and streaming log entries; empty fields match everything
+type LogFilter struct {
+	Levels  []string `json:"levels,omitempty"`
+	Since   string   `json:"since,omitempty"`
+	Until   string   `json:"until,omitempty"`
+	Query   string   `json:"query,omitempty"`
+	Regex   bool     `json:"regex,omitempty"`
+	Player  string   `json:"player,omitempty"`
+	Session string   `json:"session,omitempty"`
+}
+
+// LogSearchResult one page of log search results, oldest first
+type LogSearchResult struct {
+	Logs       []ServerLogEntry `json:"logs"`
+	Count      int              `json:"count"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+}
+
+// LogSession log files of one server session
+type LogSession struct {
+	ID            string `json:"id"`
+	StartedAt     string `json:"started_at"`
+	LastWrittenAt string `json:"last_written_at"`
+	Files         int    `json:"files"`
+	Size          int64  `json:"size"`
+	Active        bool   `json:"active"`
+}
+
+// ServerEvent event published to event stream clients. IDs increase across all topics.
+type ServerEvent struct {
+	ID        int64       `json:"id"`
+	Topic     string      `json:"topic"`
+	Timestamp string      `json:"timestamp"`
+	Data      interface{} `json:"data"`
 }
 
 // ServerCommand server command structure
 type ServerCommand struct {
 	Command   string `json:"command"`
 	Timestamp string `json:"timestamp"`
+	Confirm   bool   `json:"confirm,omitempty"`
+}
+
+// Console WebSocket message types
+const (
+	ConsoleMessageLog           = "log"
+	ConsoleMessageCommand       = "command"
+	ConsoleMessageCommandResult = "command_result"
+	ConsoleMessageStatus        = "status"
+	ConsoleMessagePlayer        = "player"
+	ConsoleMessagePing          = "ping"
+	ConsoleMessagePong          = "pong"
+	ConsoleMessageError         = "error"
+)
+
+// ConsoleMessage message exchanged over the console WebSocket. Clients set ID on
+// commands and pings; the matching command_result or pong carries the same ID.
+type ConsoleMessage struct {
+	Type      string      `json:"type"`
+	ID        string      `json:"id,omitempty"`
+	Timestamp string      `json:"timestamp,omitempty"`
+	Command   string      `json:"command,omitempty"`
+	Confirm   bool        `json:"confirm,omitempty"`
+	Data      interface{} `json:"data,omitempty"`
+	Error     string      `json:"error,omitempty"`
+}
+
+// ConsoleCommandResult outcome of a command sent over the console WebSocket
+type ConsoleCommandResult struct {
+	Command         string `json:"command"`
+	Success         bool   `json:"success"`
+	Response        string `json:"response,omitempty"`
+	ConfirmRequired bool   `json:"confirm_required,omitempty"`
+}
+
+// PlayerEvent player joining or leaving the server
+type PlayerEvent struct {
+	Event string `json:"event"`
+	Name  string `json:"name"`
+	Xuid  string `json:"xuid,omitempty"`
 }
 
 // ServerCommandResponse server command response
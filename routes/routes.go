@@ -3,10 +3,39 @@ package routes
 import (
 	"minecraft-easyserver/handlers"
 	"minecraft-easyserver/middleware"
+	"minecraft-easyserver/models"
 
 	"github.com/gin-gonic/gin"
 )
 
+// Per-route permission checks; they run after the JWT middleware has loaded the user
+var (
+	canView     = middleware.RequirePermission(models.PermissionView)
+	canModerate = middleware.RequirePermission(models.PermissionModerate)
+	canControl  = middleware.RequirePermission(models.PermissionControl)
+	canManage   = middleware.RequirePermission(models.PermissionManage)
+	canUsers    = middleware.RequirePermission(models.PermissionUsers)
+
+	// API tokens also need the scope covering a route; they can't use account routes
+	needsSession = middleware.RequireSession()
+)
+
+// Scope checks for API tokens, by area and access
+var (
+	serverRead    = middleware.RequireScope(models.ScopeServerRead)
+	serverControl = middleware.RequireScope(models.ScopeServerControl)
+	configRead    = middleware.RequireScope(models.ScopeConfigRead)
+	configWrite   = middleware.RequireScope(models.ScopeConfigWrite)
+	playersRead   = middleware.RequireScope(models.ScopePlayersRead)
+	playersWrite  = middleware.RequireScope(models.ScopePlayersWrite)
+	logsRead      = middleware.RequireScope(models.ScopeLogsRead)
+	logsWrite     = middleware.RequireScope(models.ScopeLogsWrite)
+	consoleRead   = middleware.RequireScope(models.ScopeConsoleRead)
+	consoleWrite  = middleware.RequireScope(models.ScopeConsoleWrite)
+	usersRead     = middleware.RequireScope(models.ScopeUsersRead)
+	usersWrite    = middleware.RequireScope(models.ScopeUsersWrite)
+)
+
 // SetupRoutes sets up all API routes
 func SetupRoutes(r *gin.Engine) {
 	// Create handler instances
@@ -14,30 +43,51 @@ func SetupRoutes(r *gin.Engine) {
 	configHandler := handlers.NewConfigHandler()
 	allowlistHandler := handlers.NewAllowlistHandler()
 	permissionHandler := handlers.NewPermissionHandler()
+	banHandler := handlers.NewBanHandler()
+	playerHandler := handlers.NewPlayerHandler()
 	worldHandler := handlers.NewWorldHandler()
 	resourcePackHandler := handlers.NewResourcePackHandler()
 	serverVersionHandler := handlers.NewServerVersionHandler()
 	logHandler := handlers.NewLogHandler()
+	consoleHandler := handlers.NewConsoleHandler()
+	eventHandler := handlers.NewEventHandler()
 	interactionHandler := handlers.NewInteractionHandler()
 	commandHandler := handlers.NewCommandHandler()
 	performanceMonitoringHandler := handlers.NewPerformanceMonitoringHandler()
 	authHandler := handlers.NewAuthHandler()
+	userHandler := handlers.NewUserHandler()
 
 	// API routes
 	api := r.Group("/api")
 	{
 		// Public routes (no authentication required)
 		api.POST("/auth/login", authHandler.Login)
+		api.POST("/auth/login/2fa", authHandler.LoginTwoFactor)
+		api.POST("/auth/refresh", authHandler.Refresh)
 		
 		// Auth routes (authentication required)
 		auth := api.Group("/auth")
 		auth.Use(middleware.JWTAuthMiddleware())
 		{
-			auth.POST("/change-password", authHandler.ChangePassword)
+			auth.POST("/change-password", needsSession, authHandler.ChangePassword)
+			auth.GET("/me", authHandler.GetCurrentUser)
+			auth.POST("/logout", needsSession, authHandler.Logout)
+			auth.GET("/sessions", needsSession, authHandler.GetSessions)
+			auth.DELETE("/sessions", needsSession, authHandler.RevokeAllSessions)
+			auth.DELETE("/sessions/:id", needsSession, authHandler.RevokeSession)
+			auth.GET("/tokens", needsSession, authHandler.GetAPITokens)
+			auth.POST("/tokens", needsSession, authHandler.CreateAPIToken)
+			auth.DELETE("/tokens/:id", needsSession, authHandler.RevokeAPIToken)
+			auth.GET("/2fa", needsSession, authHandler.GetTwoFactorStatus)
+			auth.POST("/2fa/setup", needsSession, authHandler.SetupTwoFactor)
+			auth.POST("/2fa/enable", needsSession, authHandler.EnableTwoFactor)
+			auth.POST("/2fa/disable", needsSession, authHandler.DisableTwoFactor)
+			auth.POST("/2fa/recovery-codes", needsSession, authHandler.RegenerateRecoveryCodes)
 		}
 		
 		// WebSocket route with built-in authentication (must be before protected routes)
 		api.GET("/websocket/logs", logHandler.HandleWebSocketWithAuth)
+		api.GET("/websocket/console", middleware.WebSocketAuthMiddleware(), canView, consoleHandler.HandleConsole)
 		
 		// Protected routes (authentication required)
 		protected := api.Group("/")
@@ -54,6 +104,12 @@ func SetupRoutes(r *gin.Engine) {
 			
 			// Permission routes
 			setupPermissionRoutes(protected, permissionHandler)
+
+			// Ban routes
+			setupBanRoutes(protected, banHandler)
+
+			// Player moderation routes
+			setupPlayerRoutes(protected, playerHandler)
 			
 			// World routes
 			setupWorldRoutes(protected, worldHandler)
@@ -66,6 +122,9 @@ func SetupRoutes(r *gin.Engine) {
 			
 			// Log routes
 			setupLogRoutes(protected, logHandler)
+
+			// Event stream routes
+			setupEventRoutes(protected, eventHandler)
 			
 			// Interaction routes
 			setupInteractionRoutes(protected, interactionHandler)
@@ -75,88 +134,145 @@ func SetupRoutes(r *gin.Engine) {
 
 			// Performace monitoring routes
 			setupPerformanceMonitoringRoutes(protected, performanceMonitoringHandler)
+
+			// User management routes
+			setupUserRoutes(protected, userHandler)
 		}
 	}
 }
 
 // setupServerRoutes sets up server control routes
 func setupServerRoutes(api *gin.RouterGroup, handler *handlers.ServerHandler) {
-	api.GET("/status", handler.GetStatus)
-	api.POST("/start", handler.StartServer)
-	api.POST("/stop", handler.StopServer)
-	api.POST("/restart", handler.RestartServer)
+	api.GET("/status", canView, serverRead, handler.GetStatus)
+	api.POST("/start", canControl, serverControl, handler.StartServer)
+	api.POST("/stop", canControl, serverControl, handler.StopServer)
+	api.POST("/restart", canControl, serverControl, handler.RestartServer)
 }
 
 // setupConfigRoutes sets up configuration routes
 func setupConfigRoutes(api *gin.RouterGroup, handler *handlers.ConfigHandler) {
-	api.GET("/config", handler.GetConfig)
-	api.PUT("/config", handler.UpdateConfig)
+	api.GET("/config", canView, configRead, handler.GetConfig)
+	api.PUT("/config", canManage, configWrite, handler.UpdateConfig)
+	api.GET("/config/schema", canView, configRead, handler.GetSchema)
+	api.GET("/config/history", canView, configRead, handler.GetHistory)
+	api.GET("/config/history/:id/diff", canView, configRead, handler.GetHistoryDiff)
+	api.POST("/config/history/:id/rollback", canManage, configWrite, handler.RollbackHistory)
+	api.GET("/config/presets", canView, configRead, handler.GetPresets)
+	api.POST("/config/presets", canManage, configWrite, handler.CreatePreset)
+	api.DELETE("/config/presets/:name", canManage, configWrite, handler.DeletePreset)
+	api.GET("/config/presets/:name/diff", canView, configRead, handler.DiffPreset)
+	api.POST("/config/presets/:name/apply", canManage, configWrite, handler.ApplyPreset)
 }
 
 // setupAllowlistRoutes sets up allowlist routes
 func setupAllowlistRoutes(api *gin.RouterGroup, handler *handlers.AllowlistHandler) {
-	api.GET("/allowlist", handler.GetAllowlist)
-	api.POST("/allowlist", handler.AddToAllowlist)
-	api.DELETE("/allowlist/:name", handler.RemoveFromAllowlist)
+	api.GET("/allowlist", canView, playersRead, handler.GetAllowlist)
+	api.POST("/allowlist", canModerate, playersWrite, handler.AddToAllowlist)
+	api.DELETE("/allowlist/:name", canModerate, playersWrite, handler.RemoveFromAllowlist)
+	api.POST("/allowlist/import", canModerate, playersWrite, handler.ImportPlayers)
+	api.GET("/allowlist/export", canView, playersRead, handler.ExportPlayers)
 }
 
 // setupPermissionRoutes sets up permission routes
 func setupPermissionRoutes(api *gin.RouterGroup, handler *handlers.PermissionHandler) {
-	api.GET("/permissions", handler.GetPermissions)
-	api.PUT("/permissions", handler.UpdatePermission)
-	api.DELETE("/permissions/:xuid", handler.RemovePermission)
+	api.GET("/permissions", canView, configRead, handler.GetPermissions)
+	api.PUT("/permissions", canManage, configWrite, handler.UpdatePermission)
+	api.DELETE("/permissions/:xuid", canManage, configWrite, handler.RemovePermission)
+}
+
+// setupBanRoutes sets up ban routes
+func setupBanRoutes(api *gin.RouterGroup, handler *handlers.BanHandler) {
+	api.GET("/bans", canView, playersRead, handler.GetBans)
+	api.POST("/bans", canModerate, playersWrite, handler.CreateBan)
+	api.GET("/bans/audit", canView, playersRead, handler.GetAudit)
+	api.PUT("/bans/:id", canModerate, playersWrite, handler.UpdateBan)
+	api.DELETE("/bans/:id", canModerate, playersWrite, handler.DeleteBan)
+}
+
+// setupPlayerRoutes sets up player moderation routes
+func setupPlayerRoutes(api *gin.RouterGroup, handler *handlers.PlayerHandler) {
+	api.GET("/players/audit", canView, playersRead, handler.GetAudit)
+	api.POST("/players/:name/kick", canModerate, playersWrite, handler.Kick)
+	api.POST("/players/:name/tell", canModerate, playersWrite, handler.Tell)
+	api.POST("/players/:name/teleport", canModerate, playersWrite, handler.Teleport)
+	api.POST("/players/:name/gamemode", canModerate, playersWrite, handler.SetGamemode)
+	api.POST("/players/:name/effect", canModerate, playersWrite, handler.GiveEffect)
+	api.POST("/players/:name/clear", canModerate, playersWrite, handler.ClearInventory)
 }
 
 // setupWorldRoutes sets up world routes
 func setupWorldRoutes(api *gin.RouterGroup, handler *handlers.WorldHandler) {
-	api.GET("/worlds", handler.GetWorlds)
-	api.POST("/worlds/upload", handler.UploadWorld)
-	api.DELETE("/worlds/:name", handler.DeleteWorld)
-	api.PUT("/worlds/:name/activate", handler.ActivateWorld)
+	api.GET("/worlds", canView, configRead, handler.GetWorlds)
+	api.POST("/worlds/upload", canManage, configWrite, handler.UploadWorld)
+	api.DELETE("/worlds/:name", canManage, configWrite, handler.DeleteWorld)
+	api.PUT("/worlds/:name/activate", canManage, configWrite, handler.ActivateWorld)
 }
 
 // setupResourcePackRoutes sets up resource pack routes
 func setupResourcePackRoutes(api *gin.RouterGroup, handler *handlers.ResourcePackHandler) {
-	api.GET("/resource-packs", handler.GetResourcePacks)
-	api.POST("/resource-packs/upload", handler.UploadResourcePack)
-	api.PUT("/resource-packs/:uuid/activate", handler.ActivateResourcePack)
-	api.PUT("/resource-packs/:uuid/deactivate", handler.DeactivateResourcePack)
-	api.DELETE("/resource-packs/:uuid", handler.DeleteResourcePack)
+	api.GET("/resource-packs", canView, configRead, handler.GetResourcePacks)
+	api.GET("/resource-packs/settings", canView, configRead, handler.GetPackSettings)
+	api.PUT("/resource-packs/settings", canManage, configWrite, handler.UpdatePackSettings)
+	api.GET("/resource-packs/content-log", canView, configRead, handler.GetContentLog)
+	api.POST("/resource-packs/upload", canManage, configWrite, handler.UploadResourcePack)
+	api.PUT("/resource-packs/:uuid/activate", canManage, configWrite, handler.ActivateResourcePack)
+	api.PUT("/resource-packs/:uuid/deactivate", canManage, configWrite, handler.DeactivateResourcePack)
+	api.DELETE("/resource-packs/:uuid", canManage, configWrite, handler.DeleteResourcePack)
+	api.POST("/resource-packs/:uuid/rollback", canManage, configWrite, handler.RollbackResourcePack)
 }
 
 // setupServerVersionRoutes sets up server version routes
 func setupServerVersionRoutes(api *gin.RouterGroup, handler *handlers.ServerVersionHandler) {
-	api.GET("/server-versions", handler.GetVersions)
-	api.POST("/server-versions/:version/download", handler.DownloadVersion)
-	api.GET("/server-versions/:version/progress", handler.GetDownloadProgress)
-	api.POST("/server-versions/:version/activate", handler.ActivateVersion)
-	api.POST("/server-versions/update-config", handler.UpdateVersionConfig)
+	api.GET("/server-versions", canView, configRead, handler.GetVersions)
+	api.POST("/server-versions/:version/download", canManage, configWrite, handler.DownloadVersion)
+	api.GET("/server-versions/:version/progress", canView, configRead, handler.GetDownloadProgress)
+	api.POST("/server-versions/:version/activate", canManage, configWrite, handler.ActivateVersion)
+	api.POST("/server-versions/update-config", canManage, configWrite, handler.UpdateVersionConfig)
 }
 
 // setupLogRoutes sets up log routes
 func setupLogRoutes(api *gin.RouterGroup, handler *handlers.LogHandler) {
-	api.GET("/logs", handler.GetLogs)
-	api.DELETE("/logs", handler.ClearLogs)
+	api.GET("/logs", canView, logsRead, handler.GetLogs)
+	api.DELETE("/logs", canManage, logsWrite, handler.ClearLogs)
+	api.GET("/logs/sessions", canView, logsRead, handler.GetSessions)
+	api.GET("/logs/sessions/:id/download", canView, logsRead, handler.DownloadSession)
+}
+
+// setupEventRoutes sets up event stream routes
+func setupEventRoutes(api *gin.RouterGroup, handler *handlers.EventHandler) {
+	api.GET("/events", canView, serverRead, handler.StreamEvents)
 }
 
 // setupInteractionRoutes sets up interaction routes
 func setupInteractionRoutes(api *gin.RouterGroup, handler *handlers.InteractionHandler) {
-	api.GET("/interaction/status", handler.GetStatus)
-	api.POST("/interaction/command", handler.SendCommand)
-	api.GET("/interaction/history", handler.GetCommandHistory)
-	api.DELETE("/interaction/history", handler.ClearHistory)
+	api.GET("/interaction/status", canView, consoleRead, handler.GetStatus)
+	api.POST("/interaction/command", canModerate, consoleWrite, handler.SendCommand)
+	api.GET("/interaction/history", canView, consoleRead, handler.GetCommandHistory)
+	api.DELETE("/interaction/history", canManage, consoleWrite, handler.ClearHistory)
+	api.GET("/interaction/policy", canView, consoleRead, handler.GetCommandPolicy)
+	api.PUT("/interaction/policy", canManage, consoleWrite, handler.UpdateCommandPolicy)
+	api.POST("/interaction/policy/test", canView, consoleRead, handler.TestCommandPolicy)
+	api.POST("/interaction/policy/reset", canManage, consoleWrite, handler.ResetCommandPolicy)
 }
 
 // setupCommandRoutes sets up command routes
 func setupCommandRoutes(api *gin.RouterGroup, handler *handlers.CommandHandler) {
-	api.GET("/commands", handler.GetQuickCommands)
-	api.GET("/commands/categories", handler.GetCategories)
-	api.POST("/commands/:id/execute", handler.ExecuteQuickCommand)
-	api.POST("/commands", handler.AddQuickCommand)
-	api.DELETE("/commands/:id", handler.RemoveQuickCommand)
+	api.GET("/commands", canView, consoleRead, handler.GetQuickCommands)
+	api.GET("/commands/categories", canView, consoleRead, handler.GetCategories)
+	api.POST("/commands/:id/execute", canModerate, consoleWrite, handler.ExecuteQuickCommand)
+	api.POST("/commands", canManage, consoleWrite, handler.AddQuickCommand)
+	api.DELETE("/commands/:id", canManage, consoleWrite, handler.RemoveQuickCommand)
 }
 
 // setupPerformanceMonitoringRoutes sets up performance monitoring routes
 func setupPerformanceMonitoringRoutes(api *gin.RouterGroup, handler *handlers.PerformanceMonitoringHandler) {
-	api.GET("/monitor/performance", handler.GetPerformanceMonitoringData)
-}
\ No newline at end of file
+	api.GET("/monitor/performance", canView, serverRead, handler.GetPerformanceMonitoringData)
+}
+
+// setupUserRoutes sets up user management routes
+func setupUserRoutes(api *gin.RouterGroup, handler *handlers.UserHandler) {
+	api.GET("/users", canUsers, usersRead, handler.GetUsers)
+	api.POST("/users", canUsers, usersWrite, handler.CreateUser)
+	api.PUT("/users/:id", canUsers, usersWrite, handler.UpdateUser)
+	api.DELETE("/users/:id", canUsers, usersWrite, handler.DeleteUser)
+}
@@ -0,0 +1,232 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"minecraft-easyserver/config"
+	"minecraft-easyserver/models"
+	"minecraft-easyserver/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBedrockServer answers kick commands the way bedrock does
+const fakeBedrockServer = `#!/bin/sh
+while read -r line; do
+  case "$line" in
+    kick*) echo "Kicked Steve from the game" ;;
+  esac
+done
+`
+
+// setupTestRouter builds the full route table against a temporary data directory
+func setupTestRouter(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	tempDir := t.TempDir()
+	services.SetBedrockPath(tempDir)
+	services.SetDataDir(filepath.Join(tempDir, "data"))
+
+	originalConfig := config.AppConfig
+	config.AppConfig = &config.Config{}
+	config.AppConfig.Auth.JWTSecret = "test-secret"
+	t.Cleanup(func() { config.AppConfig = originalConfig })
+
+	r := gin.New()
+	SetupRoutes(r)
+	return r
+}
+
+// createTestUser creates a user with role and returns an access token for them
+func createTestUser(t *testing.T, username, role string) (models.User, string) {
+	password := strings.ToUpper(username[:1]) + username[1:] + "-pass1"
+	user, err := services.NewUserService().CreateUser(models.CreateUserRequest{
+		Username: username,
+		Password: password,
+		Role:     role,
+	}, models.User{Role: models.RoleOwner})
+	require.NoError(t, err)
+
+	login, err := services.NewAuthService().Login(username, password, "127.0.0.1", "test")
+	require.NoError(t, err)
+	return user, login.Token
+}
+
+// startFakeServer runs a script standing in for bedrock_server, so commands sent by
+// moderation routes get a response
+func startFakeServer(t *testing.T) {
+	if runtime.GOOS == "windows" || !services.GetInteractionService().IsEnabled() {
+		t.Skip("Server interaction is not supported on this platform")
+	}
+
+	exePath := filepath.Join(services.GetBedrockPath(), "bedrock_server")
+	require.NoError(t, os.WriteFile(exePath, []byte(fakeBedrockServer), 0755))
+
+	server := services.NewServerService()
+	require.NoError(t, server.Start())
+	t.Cleanup(func() { server.Stop() })
+}
+
+// request sends a request with a bearer token through the router
+func request(r *gin.Engine, method, path, token, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestRoutePermissions(t *testing.T) {
+	r := setupTestRouter(t)
+
+	_, ownerToken := createTestUser(t, "owner", models.RoleOwner)
+	_, moderatorToken := createTestUser(t, "moderator", models.RoleModerator)
+	_, viewerToken := createTestUser(t, "viewer", models.RoleViewer)
+
+	t.Run("RequiresLogin", func(t *testing.T) {
+		w := request(r, "GET", "/api/status", "", "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("ModeratorCannotManage", func(t *testing.T) {
+		w := request(r, "DELETE", "/api/worlds/Bedrock%20level", moderatorToken, "")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "requires 'manage' permission")
+
+		w = request(r, "POST", "/api/server-versions/1.21.0.03/activate", moderatorToken, "")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = request(r, "POST", "/api/start", moderatorToken, "")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "requires 'control' permission")
+
+		w = request(r, "GET", "/api/users", moderatorToken, "")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("ViewerCannotWrite", func(t *testing.T) {
+		writes := []struct {
+			method string
+			path   string
+			body   string
+		}{
+			{"POST", "/api/allowlist", `{"name":"Steve"}`},
+			{"DELETE", "/api/allowlist/Steve", ""},
+			{"POST", "/api/bans", `{"player":"Steve"}`},
+			{"POST", "/api/players/Steve/kick", ""},
+			{"POST", "/api/interaction/command", `{"command":"list"}`},
+			{"POST", "/api/commands/list/execute", ""},
+			{"PUT", "/api/config", `{"config":{}}`},
+			{"DELETE", "/api/worlds/Bedrock%20level", ""},
+			{"POST", "/api/stop", ""},
+			{"DELETE", "/api/logs", ""},
+		}
+		for _, write := range writes {
+			w := request(r, write.method, write.path, viewerToken, write.body)
+			assert.Equal(t, http.StatusForbidden, w.Code, "%s %s", write.method, write.path)
+		}
+
+		w := request(r, "GET", "/api/allowlist", viewerToken, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("OwnerPassesPermissionChecks", func(t *testing.T) {
+		w := request(r, "DELETE", "/api/worlds/missing", ownerToken, "")
+		assert.NotEqual(t, http.StatusForbidden, w.Code)
+
+		w = request(r, "GET", "/api/users", ownerToken, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("ModeratorCanKick", func(t *testing.T) {
+		startFakeServer(t)
+
+		w := request(r, "POST", "/api/players/Steve/kick", moderatorToken, "")
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Contains(t, w.Body.String(), "Kick sent")
+	})
+}
+
+func TestRouteScopes(t *testing.T) {
+	r := setupTestRouter(t)
+
+	owner, ownerToken := createTestUser(t, "owner", models.RoleOwner)
+
+	createToken := func(scopes ...string) string {
+		_, plain, err := services.NewAPITokenService().CreateToken(owner, models.CreateAPITokenRequest{
+			Name:   "test",
+			Scopes: scopes,
+		})
+		require.NoError(t, err)
+		return plain
+	}
+	readOnly := createToken(models.ScopePlayersRead)
+	moderation := createToken(models.ScopePlayersRead, models.ScopePlayersWrite)
+
+	t.Run("MissingScope", func(t *testing.T) {
+		w := request(r, "GET", "/api/allowlist", readOnly, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = request(r, "POST", "/api/allowlist", readOnly, `{"name":"Steve"}`)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "requires 'players:write' scope")
+
+		w = request(r, "GET", "/api/status", readOnly, "")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "requires 'server:read' scope")
+
+		w = request(r, "GET", "/api/users", moderation, "")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		// Logging in isn't limited by scopes
+		w = request(r, "GET", "/api/status", ownerToken, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("SessionOnlyRoutes", func(t *testing.T) {
+		w := request(r, "GET", "/api/auth/me", readOnly, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		sessionOnly := []struct {
+			method string
+			path   string
+			body   string
+		}{
+			{"GET", "/api/auth/sessions", ""},
+			{"POST", "/api/auth/tokens", `{"name":"escalate","scopes":["users:write"]}`},
+			{"POST", "/api/auth/change-password", `{"current_password":"x","new_password":"y"}`},
+			{"POST", "/api/auth/2fa/setup", ""},
+		}
+		for _, route := range sessionOnly {
+			w := request(r, route.method, route.path, moderation, route.body)
+			assert.Equal(t, http.StatusForbidden, w.Code, "%s %s", route.method, route.path)
+			assert.Contains(t, w.Body.String(), "not available to API tokens")
+		}
+
+		w = request(r, "GET", "/api/auth/sessions", ownerToken, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("ScopeGranted", func(t *testing.T) {
+		startFakeServer(t)
+
+		w := request(r, "POST", "/api/players/Steve/kick", readOnly, "")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = request(r, "POST", "/api/players/Steve/kick", moderation, "")
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	})
+}
@@ -0,0 +1,457 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"minecraft-easyserver/models"
+	"minecraft-easyserver/utils"
+)
+
+// Import modes for ImportPlayers
+const (
+	PlayerImportMerge   = "merge"
+	PlayerImportReplace = "replace"
+)
+
+// Formats for ImportPlayers and ExportPlayers
+const (
+	PlayerFormatCSV  = "csv"
+	PlayerFormatJSON = "json"
+)
+
+// playerCSVHeader column order used for CSV export
+var playerCSVHeader = []string{"name", "xuid", "ignoresPlayerLimit", "permission"}
+
+// xuidPattern XUIDs are decimal numbers
+var xuidPattern = regexp.MustCompile(`^[0-9]{1,20}$`)
+
+// ExportPlayers returns the allowlist joined with permissions by XUID.
+// Permissions for players not on the allowlist are exported as rows without a name.
+func (a *AllowlistService) ExportPlayers() ([]models.PlayerAccessEntry, error) {
+	entries := []models.PlayerAccessEntry{}
+
+	// If no server version is active, return empty list
+	if bedrockPath == "" {
+		return entries, nil
+	}
+
+	allowlist, err := readAllowlist(filepath.Join(bedrockPath, "allowlist.json"))
+	if err != nil {
+		return nil, err
+	}
+	permissions, err := readPermissions(filepath.Join(bedrockPath, "permissions.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	levels := make(map[string]string)
+	for _, permission := range permissions {
+		xuid, _ := permission["xuid"].(string)
+		level, _ := permission["permission"].(string)
+		if xuid != "" {
+			levels[xuid] = level
+		}
+	}
+
+	exported := make(map[string]bool)
+	for _, entry := range allowlist {
+		row := models.PlayerAccessEntry{
+			Name:               entry.Name,
+			Xuid:               entry.Xuid,
+			IgnoresPlayerLimit: entry.IgnoresPlayerLimit,
+		}
+		if entry.Xuid != "" {
+			row.Permission = levels[entry.Xuid]
+			exported[entry.Xuid] = true
+		}
+		entries = append(entries, row)
+	}
+
+	var remaining []string
+	for xuid := range levels {
+		if !exported[xuid] {
+			remaining = append(remaining, xuid)
+		}
+	}
+	sort.Strings(remaining)
+	for _, xuid := range remaining {
+		entries = append(entries, models.PlayerAccessEntry{Xuid: xuid, Permission: levels[xuid]})
+	}
+
+	return entries, nil
+}
+
+// EncodePlayers encodes exported players as CSV or JSON
+func EncodePlayers(entries []models.PlayerAccessEntry, format string) ([]byte, error) {
+	switch format {
+	case PlayerFormatJSON:
+		return json.MarshalIndent(entries, "", "  ")
+	case PlayerFormatCSV:
+		var buffer bytes.Buffer
+		writer := csv.NewWriter(&buffer)
+		writer.Write(playerCSVHeader)
+		for _, entry := range entries {
+			writer.Write([]string{
+				entry.Name,
+				entry.Xuid,
+				strconv.FormatBool(entry.IgnoresPlayerLimit),
+				entry.Permission,
+			})
+		}
+		writer.Flush()
+		return buffer.Bytes(), writer.Error()
+	default:
+		return nil, fmt.Errorf("invalid format: %s. Valid formats are: csv, json", format)
+	}
+}
+
+// ImportPlayers imports allowlist entries and permission levels in bulk.
+// In merge mode existing players are updated and new ones added; in replace mode
+// both files are replaced by the import. Nothing is written when dryRun is set or
+// when any row has an error, so an import is applied completely or not at all.
+func (a *AllowlistService) ImportPlayers(data []byte, format, mode string, dryRun bool, user string) (*models.PlayerImportResult, error) {
+	// If no server version is active, return error
+	if bedrockPath == "" {
+		return nil, fmt.Errorf("no server version is currently active. Please download and activate a server version first")
+	}
+
+	if mode != PlayerImportMerge && mode != PlayerImportReplace {
+		return nil, fmt.Errorf("invalid import mode: %s. Valid modes are: merge, replace", mode)
+	}
+
+	rows, err := parsePlayerImport(data, format)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.PlayerImportResult{
+		Mode:   mode,
+		DryRun: dryRun,
+		Rows:   len(rows),
+		Errors: validatePlayerRows(rows),
+	}
+
+	allowlistPath := filepath.Join(bedrockPath, "allowlist.json")
+	permissionsPath := filepath.Join(bedrockPath, "permissions.json")
+
+	allowlist, err := readAllowlist(allowlistPath)
+	if err != nil {
+		return nil, err
+	}
+	permissions, err := readPermissions(permissionsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	newAllowlist, newPermissions := mergePlayerRows(allowlist, permissions, rows, mode, result)
+
+	if dryRun || len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	if err := writePlayerFiles(allowlistPath, newAllowlist, permissionsPath, newPermissions, user); err != nil {
+		return nil, err
+	}
+
+	result.Live = []models.LiveSyncResult{
+		reloadLiveFile("allowlist.json"),
+		reloadLiveFile("permissions.json"),
+	}
+	return result, nil
+}
+
+// writePlayerFiles writes the allowlist and permissions files together. Both are encoded
+// before either is written; if the second write fails the first file is put back, and
+// history is only recorded once both are written.
+func writePlayerFiles(allowlistPath string, allowlist []models.AllowlistEntry, permissionsPath string, permissions []map[string]interface{}, user string) error {
+	allowlistData, err := json.MarshalIndent(allowlist, "", "  ")
+	if err != nil {
+		return err
+	}
+	permissionsData, err := json.MarshalIndent(permissions, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	previousAllowlist, err := os.ReadFile(allowlistPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	previousPermissions, err := os.ReadFile(permissionsPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := utils.WriteFileAtomic(allowlistPath, allowlistData, 0644); err != nil {
+		return fmt.Errorf("failed to write allowlist.json, nothing was imported: %v", err)
+	}
+	if err := utils.WriteFileAtomic(permissionsPath, permissionsData, 0644); err != nil {
+		// Put the allowlist back so the two files stay consistent; this isn't a change
+		// by the user, so it isn't recorded in the history
+		var restoreErr error
+		if previousAllowlist != nil {
+			restoreErr = utils.WriteFileAtomic(allowlistPath, previousAllowlist, 0644)
+		} else {
+			restoreErr = os.Remove(allowlistPath)
+		}
+		if restoreErr != nil {
+			return fmt.Errorf("import partially applied: allowlist.json was written but permissions.json failed (%v) and restoring allowlist.json failed (%v)", err, restoreErr)
+		}
+		return fmt.Errorf("failed to write permissions.json, allowlist.json was restored and nothing was imported: %v", err)
+	}
+
+	recordFileChange(allowlistPath, previousAllowlist, allowlistData, user)
+	recordFileChange(permissionsPath, previousPermissions, permissionsData, user)
+	return nil
+}
+
+// parsePlayerImport decodes import rows from CSV or JSON
+func parsePlayerImport(data []byte, format string) ([]models.PlayerAccessEntry, error) {
+	switch format {
+	case PlayerFormatJSON:
+		var rows []models.PlayerAccessEntry
+		if err := json.Unmarshal(data, &rows); err != nil {
+			return nil, fmt.Errorf("invalid import data: %v", err)
+		}
+		return rows, nil
+	case PlayerFormatCSV:
+		return parsePlayerCSV(data)
+	default:
+		return nil, fmt.Errorf("invalid format: %s. Valid formats are: csv, json", format)
+	}
+}
+
+// parsePlayerCSV decodes CSV rows. The first line is a header naming the columns;
+// unknown columns are ignored.
+func parsePlayerCSV(data []byte) ([]models.PlayerAccessEntry, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("invalid import data: missing CSV header")
+	}
+
+	columns := make(map[string]int)
+	for i, column := range header {
+		switch strings.ToLower(strings.TrimSpace(column)) {
+		case "name", "player":
+			columns["name"] = i
+		case "xuid":
+			columns["xuid"] = i
+		case "ignoresplayerlimit", "ignores_player_limit":
+			columns["ignoresPlayerLimit"] = i
+		case "permission", "level":
+			columns["permission"] = i
+		}
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("invalid import data: CSV header must contain name, xuid, ignoresPlayerLimit or permission")
+	}
+
+	field := func(record []string, column string) string {
+		if i, exists := columns[column]; exists && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var rows []models.PlayerAccessEntry
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid import data: %v", err)
+		}
+
+		ignoresPlayerLimit := false
+		switch strings.ToLower(field(record, "ignoresPlayerLimit")) {
+		case "true", "1", "yes":
+			ignoresPlayerLimit = true
+		}
+
+		rows = append(rows, models.PlayerAccessEntry{
+			Name:               field(record, "name"),
+			Xuid:               field(record, "xuid"),
+			IgnoresPlayerLimit: ignoresPlayerLimit,
+			Permission:         strings.ToLower(field(record, "permission")),
+		})
+	}
+	return rows, nil
+}
+
+// validatePlayerRows checks every import row and returns the problems found.
+// Row numbers start at 1 for the first data row.
+func validatePlayerRows(rows []models.PlayerAccessEntry) []models.PlayerImportRowError {
+	errors := []models.PlayerImportRowError{}
+	seenNames := make(map[string]int)
+	seenXuids := make(map[string]int)
+
+	for i, row := range rows {
+		number := i + 1
+		addError := func(message string) {
+			errors = append(errors, models.PlayerImportRowError{Row: number, Name: row.Name, Error: message})
+		}
+
+		if row.Name == "" && row.Xuid == "" {
+			addError("name or xuid is required")
+			continue
+		}
+		if row.Name != "" {
+			if _, err := QuoteCommandArgument(row.Name); err != nil {
+				addError("invalid name: " + err.Error())
+			}
+			if previous, exists := seenNames[strings.ToLower(row.Name)]; exists {
+				addError(fmt.Sprintf("duplicate name, already on row %d", previous))
+			}
+			seenNames[strings.ToLower(row.Name)] = number
+		}
+		if row.Xuid != "" {
+			if !xuidPattern.MatchString(row.Xuid) {
+				addError("invalid xuid: " + row.Xuid)
+			}
+			if previous, exists := seenXuids[row.Xuid]; exists {
+				addError(fmt.Sprintf("duplicate xuid, already on row %d", previous))
+			}
+			seenXuids[row.Xuid] = number
+		}
+		if row.Permission != "" {
+			switch row.Permission {
+			case "visitor", "member", "operator":
+			default:
+				addError(fmt.Sprintf("invalid permission level: %s. Valid levels are: visitor, member, operator", row.Permission))
+			}
+			if row.Xuid == "" {
+				addError("permission requires an xuid")
+			}
+		}
+		if row.Name == "" && row.Permission == "" {
+			addError("row without a name must set a permission")
+		}
+	}
+	return errors
+}
+
+// mergePlayerRows applies import rows to the current allowlist and permissions
+// and counts the changes in result
+func mergePlayerRows(allowlist []models.AllowlistEntry, permissions []map[string]interface{}, rows []models.PlayerAccessEntry, mode string, result *models.PlayerImportResult) ([]models.AllowlistEntry, []map[string]interface{}) {
+	newAllowlist := []models.AllowlistEntry{}
+	newPermissions := []map[string]interface{}{}
+	if mode == PlayerImportMerge {
+		newAllowlist = append(newAllowlist, allowlist...)
+		newPermissions = append(newPermissions, permissions...)
+	}
+
+	importedNames := make(map[string]bool)
+	importedXuids := make(map[string]bool)
+	for _, row := range rows {
+		if row.Name != "" {
+			importedNames[strings.ToLower(row.Name)] = true
+			entry := models.AllowlistEntry{
+				Name:               row.Name,
+				Xuid:               row.Xuid,
+				IgnoresPlayerLimit: row.IgnoresPlayerLimit,
+			}
+
+			found := false
+			for i := range newAllowlist {
+				if strings.EqualFold(newAllowlist[i].Name, row.Name) {
+					// A row without an xuid keeps the one already recorded for the player
+					if entry.Xuid == "" {
+						entry.Xuid = newAllowlist[i].Xuid
+					}
+					if newAllowlist[i] != entry {
+						newAllowlist[i] = entry
+						result.AllowlistUpdated++
+					}
+					found = true
+					break
+				}
+			}
+			if !found {
+				newAllowlist = append(newAllowlist, entry)
+				// In replace mode the entry may already have been on the old allowlist
+				if previous, exists := findAllowlistEntry(allowlist, row.Name); !exists {
+					result.AllowlistAdded++
+				} else if previous != entry {
+					result.AllowlistUpdated++
+				}
+			}
+		}
+
+		if row.Permission != "" {
+			importedXuids[row.Xuid] = true
+
+			found := false
+			for i := range newPermissions {
+				if newPermissions[i]["xuid"] == row.Xuid {
+					if newPermissions[i]["permission"] != row.Permission {
+						newPermissions[i] = map[string]interface{}{"xuid": row.Xuid, "permission": row.Permission}
+						result.PermissionsSet++
+					}
+					found = true
+					break
+				}
+			}
+			if !found {
+				newPermissions = append(newPermissions, map[string]interface{}{
+					"xuid":       row.Xuid,
+					"permission": row.Permission,
+				})
+				if permissionLevel(permissions, row.Xuid) != row.Permission {
+					result.PermissionsSet++
+				}
+			}
+		}
+	}
+
+	if mode == PlayerImportReplace {
+		for _, entry := range allowlist {
+			if !importedNames[strings.ToLower(entry.Name)] {
+				result.AllowlistRemoved++
+			}
+		}
+		for _, permission := range permissions {
+			xuid, _ := permission["xuid"].(string)
+			if !importedXuids[xuid] {
+				result.PermissionsRemoved++
+			}
+		}
+	}
+
+	return newAllowlist, newPermissions
+}
+
+// findAllowlistEntry finds an allowlist entry by name, ignoring case
+func findAllowlistEntry(allowlist []models.AllowlistEntry, name string) (models.AllowlistEntry, bool) {
+	for _, entry := range allowlist {
+		if strings.EqualFold(entry.Name, name) {
+			return entry, true
+		}
+	}
+	return models.AllowlistEntry{}, false
+}
+
+// permissionLevel returns the permission level set for an XUID, or "" if none
+func permissionLevel(permissions []map[string]interface{}, xuid string) string {
+	for _, permission := range permissions {
+		if permission["xuid"] == xuid {
+			level, _ := permission["permission"].(string)
+			return level
+		}
+	}
+	return ""
+}
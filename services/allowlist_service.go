@@ -33,23 +33,23 @@ func (a *AllowlistService) GetAllowlist() ([]models.AllowlistEntry, error) {
 	return allowlist, nil
 }
 
-// AddToAllowlist adds to allowlist
-func (a *AllowlistService) AddToAllowlist(entry models.AllowlistEntry) error {
+// AddToAllowlist adds to allowlist and to the running server's allowlist
+func (a *AllowlistService) AddToAllowlist(entry models.AllowlistEntry, user string) (models.LiveSyncResult, error) {
 	// If no server version is active, return error
 	if bedrockPath == "" {
-		return fmt.Errorf("no server version is currently active. Please download and activate a server version first")
+		return models.LiveSyncResult{}, fmt.Errorf("no server version is currently active. Please download and activate a server version first")
 	}
 	
 	allowlistPath := filepath.Join(bedrockPath, "allowlist.json")
 	allowlist, err := readAllowlist(allowlistPath)
 	if err != nil {
-		return err
+		return models.LiveSyncResult{}, err
 	}
 
 	// Check if already exists
 	for _, existingEntry := range allowlist {
 		if existingEntry.Name == entry.Name {
-			return fmt.Errorf("player %s is already in allowlist", entry.Name)
+			return models.LiveSyncResult{}, fmt.Errorf("player %s is already in allowlist", entry.Name)
 		}
 	}
 
@@ -60,20 +60,29 @@ func (a *AllowlistService) AddToAllowlist(entry models.AllowlistEntry) error {
 	}
 	allowlist = append(allowlist, newEntry)
 
-	return writeAllowlist(allowlistPath, allowlist)
+	if err := writeAllowlist(allowlistPath, allowlist, user); err != nil {
+		return models.LiveSyncResult{}, err
+	}
+
+	// "allowlist add" can't set ignoresPlayerLimit, so reload the file for those entries
+	name, err := QuoteCommandArgument(entry.Name)
+	if err != nil || entry.IgnoresPlayerLimit {
+		return reloadLiveFile("allowlist.json"), nil
+	}
+	return syncLive("allowlist add "+name, []string{"Player added to allowlist", "Player already in allowlist"}), nil
 }
 
-// RemoveFromAllowlist removes from allowlist
-func (a *AllowlistService) RemoveFromAllowlist(name string) error {
+// RemoveFromAllowlist removes from allowlist and from the running server's allowlist
+func (a *AllowlistService) RemoveFromAllowlist(name, user string) (models.LiveSyncResult, error) {
 	// If no server version is active, return error
 	if bedrockPath == "" {
-		return fmt.Errorf("no server version is currently active. Please download and activate a server version first")
+		return models.LiveSyncResult{}, fmt.Errorf("no server version is currently active. Please download and activate a server version first")
 	}
 	
 	allowlistPath := filepath.Join(bedrockPath, "allowlist.json")
 	allowlist, err := readAllowlist(allowlistPath)
 	if err != nil {
-		return err
+		return models.LiveSyncResult{}, err
 	}
 
 	// Find and remove entry
@@ -87,10 +96,18 @@ func (a *AllowlistService) RemoveFromAllowlist(name string) error {
 	}
 
 	if !found {
-		return fmt.Errorf("player %s not in allowlist", name)
+		return models.LiveSyncResult{}, fmt.Errorf("player %s not in allowlist", name)
 	}
 
-	return writeAllowlist(allowlistPath, allowlist)
+	if err := writeAllowlist(allowlistPath, allowlist, user); err != nil {
+		return models.LiveSyncResult{}, err
+	}
+
+	quotedName, err := QuoteCommandArgument(name)
+	if err != nil {
+		return reloadLiveFile("allowlist.json"), nil
+	}
+	return syncLive("allowlist remove "+quotedName, []string{"Player removed from allowlist"}, "Player not in allowlist"), nil
 }
 
 // Read allowlist.json
@@ -115,11 +132,11 @@ func readAllowlist(path string) ([]models.AllowlistEntry, error) {
 }
 
 // Write allowlist.json
-func writeAllowlist(path string, allowlist []models.AllowlistEntry) error {
+func writeAllowlist(path string, allowlist []models.AllowlistEntry, user string) error {
 	data, err := json.MarshalIndent(allowlist, "", "  ")
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(path, data, 0644)
+	return writeVersionedFile(path, data, user)
 }
\ No newline at end of file
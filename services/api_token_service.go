@@ -0,0 +1,310 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"minecraft-easyserver/models"
+	"minecraft-easyserver/utils"
+)
+
+// APITokenPrefix starts every API token, which tells them apart from access tokens
+const APITokenPrefix = "mcs_"
+
+// apiTokenTimeFormat format of API token timestamps
+const apiTokenTimeFormat = "2006-01-02 15:04:05"
+
+// apiTokenLastUsedInterval how often the last-used time is written, so busy tokens
+// don't rewrite the token file on every request
+const apiTokenLastUsedInterval = time.Minute
+
+// apiTokenScopes scopes API tokens can have, and the permission a role needs to grant each
+var apiTokenScopes = map[string]string{
+	models.ScopeServerRead:    models.PermissionView,
+	models.ScopeServerControl: models.PermissionControl,
+	models.ScopeConfigRead:    models.PermissionView,
+	models.ScopeConfigWrite:   models.PermissionManage,
+	models.ScopePlayersRead:   models.PermissionView,
+	models.ScopePlayersWrite:  models.PermissionModerate,
+	models.ScopeLogsRead:      models.PermissionView,
+	models.ScopeLogsWrite:     models.PermissionManage,
+	models.ScopeConsoleRead:   models.PermissionView,
+	models.ScopeConsoleWrite:  models.PermissionModerate,
+	models.ScopeUsersRead:     models.PermissionUsers,
+	models.ScopeUsersWrite:    models.PermissionUsers,
+}
+
+// storedAPIToken API token as saved, with the hash of its secret
+type storedAPIToken struct {
+	models.APIToken
+	Hash string `json:"hash"`
+}
+
+// APITokenService manages personal access tokens
+type APITokenService struct {
+	mutex sync.Mutex
+}
+
+var apiTokenService *APITokenService
+
+// NewAPITokenService returns the global API token service instance
+func NewAPITokenService() *APITokenService {
+	if apiTokenService == nil {
+		apiTokenService = &APITokenService{}
+	}
+	return apiTokenService
+}
+
+// apiTokensPath returns the file API tokens are stored in
+func apiTokensPath() string {
+	return filepath.Join(dataDir, "api_tokens.json")
+}
+
+// APITokenHasScope reports whether token was given scope
+func APITokenHasScope(token models.APIToken, scope string) bool {
+	for _, granted := range token.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateToken creates an API token for user. The token itself is only returned here;
+// just its hash is kept.
+func (s *APITokenService) CreateToken(user models.User, request models.CreateAPITokenRequest) (models.APIToken, string, error) {
+	name := strings.TrimSpace(request.Name)
+	if name == "" || len(name) > 64 {
+		return models.APIToken{}, "", fmt.Errorf("invalid token name: use 1-64 characters")
+	}
+	if request.ExpiresInDays < 0 {
+		return models.APIToken{}, "", fmt.Errorf("invalid expiry: expires_in_days cannot be negative")
+	}
+	scopes, err := checkAPITokenScopes(user, request.Scopes)
+	if err != nil {
+		return models.APIToken{}, "", err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return models.APIToken{}, "", fmt.Errorf("failed to generate token: %v", err)
+	}
+
+	now := time.Now()
+	token := storedAPIToken{
+		APIToken: models.APIToken{
+			ID:        newRandomID(),
+			UserID:    user.ID,
+			Name:      name,
+			Scopes:    scopes,
+			CreatedAt: now.Format(apiTokenTimeFormat),
+		},
+	}
+	if request.ExpiresInDays > 0 {
+		token.ExpiresAt = now.AddDate(0, 0, request.ExpiresInDays).Format(apiTokenTimeFormat)
+	}
+	plain := APITokenPrefix + token.ID + "_" + base64.RawURLEncoding.EncodeToString(secret)
+	token.Prefix = plain[:len(APITokenPrefix)+len(token.ID)+5]
+	token.Hash = hashSecretToken(plain)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tokens, err := readAPITokens()
+	if err != nil {
+		return models.APIToken{}, "", err
+	}
+	if err := writeAPITokens(append(tokens, token)); err != nil {
+		return models.APIToken{}, "", err
+	}
+	return token.APIToken, plain, nil
+}
+
+// ListTokens lists a user's API tokens, newest first
+func (s *APITokenService) ListTokens(userID string) ([]models.APIToken, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tokens, err := readAPITokens()
+	if err != nil {
+		return nil, err
+	}
+
+	result := []models.APIToken{}
+	for i := len(tokens) - 1; i >= 0; i-- {
+		if tokens[i].UserID == userID {
+			result = append(result, tokens[i].APIToken)
+		}
+	}
+	return result, nil
+}
+
+// RevokeToken deletes one of a user's API tokens
+func (s *APITokenService) RevokeToken(userID, id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tokens, err := readAPITokens()
+	if err != nil {
+		return err
+	}
+	for i, token := range tokens {
+		if token.ID == id && token.UserID == userID {
+			return writeAPITokens(append(tokens[:i], tokens[i+1:]...))
+		}
+	}
+	return fmt.Errorf("api token %s not found", id)
+}
+
+// RevokeUserTokens deletes all of a user's API tokens and returns how many there were
+func (s *APITokenService) RevokeUserTokens(userID string) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tokens, err := readAPITokens()
+	if err != nil {
+		return 0, err
+	}
+	kept := tokens[:0]
+	for _, token := range tokens {
+		if token.UserID != userID {
+			kept = append(kept, token)
+		}
+	}
+	revoked := len(tokens) - len(kept)
+	if revoked == 0 {
+		return 0, nil
+	}
+	return revoked, writeAPITokens(kept)
+}
+
+// ValidateToken checks that an API token still exists, belongs to userID and hasn't expired
+func (s *APITokenService) ValidateToken(id, userID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tokens, err := readAPITokens()
+	if err != nil {
+		return err
+	}
+	for _, token := range tokens {
+		if token.ID != id || token.UserID != userID {
+			continue
+		}
+		if token.ExpiresAt != "" {
+			expiresAt, err := time.ParseInLocation(apiTokenTimeFormat, token.ExpiresAt, time.Local)
+			if err != nil || !time.Now().Before(expiresAt) {
+				return fmt.Errorf("api token expired")
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("api token has been revoked")
+}
+
+// Authenticate checks an API token and records that it was used
+func (s *APITokenService) Authenticate(plain string) (models.APIToken, error) {
+	id, _, found := strings.Cut(strings.TrimPrefix(plain, APITokenPrefix), "_")
+	if !strings.HasPrefix(plain, APITokenPrefix) || !found {
+		return models.APIToken{}, fmt.Errorf("invalid api token")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tokens, err := readAPITokens()
+	if err != nil {
+		return models.APIToken{}, err
+	}
+	for i := range tokens {
+		token := &tokens[i]
+		if token.ID != id {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(hashSecretToken(plain)), []byte(token.Hash)) != 1 {
+			break
+		}
+
+		now := time.Now()
+		if token.ExpiresAt != "" {
+			expiresAt, err := time.ParseInLocation(apiTokenTimeFormat, token.ExpiresAt, time.Local)
+			if err != nil || !now.Before(expiresAt) {
+				return models.APIToken{}, fmt.Errorf("api token expired")
+			}
+		}
+
+		lastUsed, err := time.ParseInLocation(apiTokenTimeFormat, token.LastUsedAt, time.Local)
+		if err != nil || now.Sub(lastUsed) >= apiTokenLastUsedInterval {
+			token.LastUsedAt = now.Format(apiTokenTimeFormat)
+			if err := writeAPITokens(tokens); err != nil {
+				return models.APIToken{}, err
+			}
+		}
+		return token.APIToken, nil
+	}
+	return models.APIToken{}, fmt.Errorf("invalid api token")
+}
+
+// checkAPITokenScopes checks that scopes exist and user's role can grant them, and
+// returns them without duplicates
+func checkAPITokenScopes(user models.User, scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("invalid scopes: at least one scope is required")
+	}
+
+	result := []string{}
+	seen := map[string]bool{}
+	for _, scope := range scopes {
+		permission, exists := apiTokenScopes[scope]
+		if !exists {
+			return nil, fmt.Errorf("invalid scope: %s", scope)
+		}
+		if !RoleHasPermission(user.Role, permission) {
+			return nil, fmt.Errorf("not allowed to grant the %s scope: requires '%s' permission", scope, permission)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			result = append(result, scope)
+		}
+	}
+	return result, nil
+}
+
+// readAPITokens reads the API tokens; callers must hold the mutex
+func readAPITokens() ([]storedAPIToken, error) {
+	data, err := os.ReadFile(apiTokensPath())
+	if os.IsNotExist(err) {
+		return []storedAPIToken{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var tokens []storedAPIToken
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, fmt.Errorf("failed to parse api tokens: %v", err)
+	}
+	return tokens, nil
+}
+
+// writeAPITokens writes the API tokens; callers must hold the mutex
+func writeAPITokens(tokens []storedAPIToken) error {
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Token hashes are only readable by the panel's own user
+	return utils.WriteFileAtomic(apiTokensPath(), data, 0600)
+}
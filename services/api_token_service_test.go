@@ -0,0 +1,120 @@
+package services
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"minecraft-easyserver/models"
+)
+
+func TestAPITokens(t *testing.T) {
+	originalDataDir := dataDir
+	SetDataDir(t.TempDir())
+	t.Cleanup(func() { SetDataDir(originalDataDir) })
+
+	tokens := NewAPITokenService()
+	admin := models.User{ID: "admin1", Role: models.RoleAdmin}
+	viewer := models.User{ID: "viewer1", Role: models.RoleViewer}
+
+	var created models.APIToken
+	var plain string
+	t.Run("CreateToken", func(t *testing.T) {
+		var err error
+		created, plain, err = tokens.CreateToken(admin, models.CreateAPITokenRequest{
+			Name:          " CI deploy ",
+			Scopes:        []string{models.ScopeServerControl, models.ScopeLogsRead, models.ScopeLogsRead},
+			ExpiresInDays: 30,
+		})
+		if err != nil {
+			t.Fatal("Failed to create token:", err)
+		}
+		if !strings.HasPrefix(plain, APITokenPrefix) || !strings.HasPrefix(plain, created.Prefix) {
+			t.Errorf("Unexpected token %q with prefix %q", plain, created.Prefix)
+		}
+		if created.Name != "CI deploy" || len(created.Scopes) != 2 || created.ExpiresAt == "" {
+			t.Errorf("Unexpected token: %+v", created)
+		}
+
+		data, _ := os.ReadFile(apiTokensPath())
+		if strings.Contains(string(data), plain) {
+			t.Error("Expected only the token's hash to be stored")
+		}
+		if info, err := os.Stat(apiTokensPath()); err != nil || info.Mode().Perm() != 0600 {
+			t.Errorf("Expected the token file to be private, got %v, %v", info, err)
+		}
+	})
+
+	t.Run("RejectsInvalidRequests", func(t *testing.T) {
+		cases := []struct {
+			user    models.User
+			request models.CreateAPITokenRequest
+			want    string
+		}{
+			{admin, models.CreateAPITokenRequest{Name: "bot", Scopes: []string{"backups:write"}}, "invalid scope"},
+			{admin, models.CreateAPITokenRequest{Name: "bot"}, "invalid scopes"},
+			{admin, models.CreateAPITokenRequest{Name: " ", Scopes: []string{models.ScopeLogsRead}}, "invalid token name"},
+			{admin, models.CreateAPITokenRequest{Name: "bot", Scopes: []string{models.ScopeLogsRead}, ExpiresInDays: -1}, "invalid expiry"},
+			{viewer, models.CreateAPITokenRequest{Name: "bot", Scopes: []string{models.ScopeServerControl}}, "not allowed"},
+		}
+		for _, tc := range cases {
+			if _, _, err := tokens.CreateToken(tc.user, tc.request); err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("Expected %q for %+v, got %v", tc.want, tc.request, err)
+			}
+		}
+	})
+
+	t.Run("Authenticate", func(t *testing.T) {
+		token, err := tokens.Authenticate(plain)
+		if err != nil {
+			t.Fatal("Failed to authenticate:", err)
+		}
+		if token.UserID != admin.ID || token.LastUsedAt == "" {
+			t.Errorf("Expected the admin's token with a last-used time, got %+v", token)
+		}
+		if !APITokenHasScope(token, models.ScopeLogsRead) || APITokenHasScope(token, models.ScopeLogsWrite) {
+			t.Errorf("Unexpected scopes: %v", token.Scopes)
+		}
+
+		for _, bad := range []string{plain + "x", APITokenPrefix + created.ID, "not-a-token", APITokenPrefix + "unknown_secret"} {
+			if _, err := tokens.Authenticate(bad); err == nil {
+				t.Errorf("Expected %q to be rejected", bad)
+			}
+		}
+	})
+
+	t.Run("ExpiredToken", func(t *testing.T) {
+		_, expired, err := tokens.CreateToken(viewer, models.CreateAPITokenRequest{Name: "old", Scopes: []string{models.ScopeLogsRead}, ExpiresInDays: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		stored, _ := readAPITokens()
+		stored[len(stored)-1].ExpiresAt = time.Now().Add(-time.Minute).Format(apiTokenTimeFormat)
+		writeAPITokens(stored)
+
+		if _, err := tokens.Authenticate(expired); err == nil || !strings.Contains(err.Error(), "expired") {
+			t.Errorf("Expected the token to have expired, got %v", err)
+		}
+	})
+
+	t.Run("RevokeToken", func(t *testing.T) {
+		if err := tokens.RevokeToken(viewer.ID, created.ID); err == nil || !strings.Contains(err.Error(), "not found") {
+			t.Errorf("Expected other users' tokens to be hidden, got %v", err)
+		}
+		if err := tokens.RevokeToken(admin.ID, created.ID); err != nil {
+			t.Fatal("Failed to revoke token:", err)
+		}
+		if _, err := tokens.Authenticate(plain); err == nil {
+			t.Error("Expected a revoked token to be rejected")
+		}
+
+		list, _ := tokens.ListTokens(viewer.ID)
+		if len(list) != 1 {
+			t.Errorf("Expected the viewer's token to be kept, got %+v", list)
+		}
+		if count, err := tokens.RevokeUserTokens(viewer.ID); err != nil || count != 1 {
+			t.Errorf("Expected to revoke the viewer's token, got %d, %v", count, err)
+		}
+	})
+}
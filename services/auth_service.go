@@ -1,18 +1,15 @@
 package services
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
 	"errors"
 	"fmt"
 	"minecraft-easyserver/config"
 	"minecraft-easyserver/models"
-	"os"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
-	"gopkg.in/yaml.v3"
 )
 
 // AuthService handles authentication operations
@@ -23,48 +20,114 @@ func NewAuthService() *AuthService {
 	return &AuthService{}
 }
 
-// hashPassword creates SHA256 hash of password
-func (s *AuthService) hashPassword(password string) string {
-	hash := sha256.Sum256([]byte(password))
-	return hex.EncodeToString(hash[:])
+// Login validates a username and password and starts a session for the user, returning
+// an access token and the session's refresh token. Users with two-factor authentication
+// get a challenge token instead, to complete with LoginTwoFactor.
+func (s *AuthService) Login(username, password, clientIP, userAgent string) (*models.LoginResponse, error) {
+	if username == "" {
+		username = DefaultUsername
+	}
+
+	user, mustChangePassword, err := NewUserService().Authenticate(username, password)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TwoFactorEnabled {
+		challenge, err := NewTwoFactorService().CreateChallenge(user.ID, mustChangePassword)
+		if err != nil {
+			return nil, err
+		}
+		return &models.LoginResponse{
+			Message:           "Two-factor code required",
+			TwoFactorRequired: true,
+			ChallengeToken:    challenge,
+		}, nil
+	}
+
+	return s.startSession(user, mustChangePassword, clientIP, userAgent)
 }
 
-// Login validates password and generates JWT token
-func (s *AuthService) Login(password string) (*models.LoginResponse, error) {
-	// Hash the input password and compare with stored hash
-	hashedPassword := s.hashPassword(password)
-	if hashedPassword != config.AppConfig.Auth.Password {
-		return nil, errors.New("invalid password")
+// LoginTwoFactor completes a login challenge with a TOTP or recovery code
+func (s *AuthService) LoginTwoFactor(challenge, code, clientIP, userAgent string) (*models.LoginResponse, error) {
+	userID, mustChangePassword, err := NewTwoFactorService().CompleteChallenge(challenge, code)
+	if err != nil {
+		return nil, err
 	}
 
-	// Generate JWT token
-	token, err := s.generateJWT()
+	user, err := NewUserService().GetUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.startSession(user, mustChangePassword, clientIP, userAgent)
+}
+
+// startSession creates a session for a user who has logged in and issues its tokens
+func (s *AuthService) startSession(user models.User, mustChangePassword bool, clientIP, userAgent string) (*models.LoginResponse, error) {
+	session, refreshToken, err := NewSessionService().CreateSession(user.ID, clientIP, userAgent)
 	if err != nil {
 		return nil, err
 	}
 
-	// Check if using default password (compare with hash of "admin123")
-	defaultPasswordHash := s.hashPassword("admin123")
-	requirePasswordChange := hashedPassword == defaultPasswordHash
+	// Generate JWT token
+	token, err := s.generateJWT(user.ID, session.ID)
+	if err != nil {
+		return nil, err
+	}
 
 	return &models.LoginResponse{
 		Token:                 token,
+		RefreshToken:          refreshToken,
+		ExpiresIn:             int64(AccessTokenTTL.Seconds()),
 		Message:               "Login successful",
-		RequirePasswordChange: requirePasswordChange,
+		RequirePasswordChange: mustChangePassword,
+		User:                  &user,
+	}, nil
+}
+
+// Refresh rotates a refresh token and issues a new access token for its session
+func (s *AuthService) Refresh(refreshToken string) (*models.LoginResponse, error) {
+	sessions := NewSessionService()
+	session, newRefreshToken, err := sessions.Refresh(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := NewUserService().GetUser(session.UserID)
+	if err != nil {
+		sessions.RevokeUserSessions(session.UserID)
+		return nil, fmt.Errorf("invalid refresh token: user account no longer exists")
+	}
+
+	token, err := s.generateJWT(user.ID, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.LoginResponse{
+		Token:        token,
+		RefreshToken: newRefreshToken,
+		ExpiresIn:    int64(AccessTokenTTL.Seconds()),
+		Message:      "Token refreshed",
+		User:         &user,
 	}, nil
 }
 
-// generateJWT creates a new JWT token
-func (s *AuthService) generateJWT() (string, error) {
+// generateJWT creates a new access token for a user's session
+func (s *AuthService) generateJWT(userID, sessionID string) (string, error) {
 	now := time.Now()
 	claims := &models.JWTClaims{
 		Authorized: true,
-		Exp:        now.Add(24 * time.Hour).Unix(), // Token expires in 24 hours
+		UserID:     userID,
+		SessionID:  sessionID,
+		Exp:        now.Add(AccessTokenTTL).Unix(),
 		Iat:        now.Unix(),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"authorized": claims.Authorized,
+		"user_id":    claims.UserID,
+		"jti":        claims.SessionID,
 		"exp":        claims.Exp,
 		"iat":        claims.Iat,
 	})
@@ -98,8 +161,22 @@ func (s *AuthService) ValidateJWT(tokenString string) (*models.JWTClaims, error)
 			}
 		}
 
+		// Tokens issued before user accounts and sessions existed don't name them
+		userID, _ := claims["user_id"].(string)
+		sessionID, _ := claims["jti"].(string)
+		if userID == "" || sessionID == "" {
+			return nil, errors.New("token has no session, please log in again")
+		}
+
+		// Revoked sessions invalidate their access tokens immediately
+		if err := NewSessionService().ValidateSession(sessionID, userID); err != nil {
+			return nil, err
+		}
+
 		return &models.JWTClaims{
 			Authorized: claims["authorized"].(bool),
+			UserID:     userID,
+			SessionID:  sessionID,
 			Exp:        int64(claims["exp"].(float64)),
 			Iat:        int64(claims["iat"].(float64)),
 		}, nil
@@ -141,29 +218,17 @@ func (s *AuthService) ValidatePasswordStrength(password string) error {
 	return nil
 }
 
-// ChangePassword changes the user password
-func (s *AuthService) ChangePassword(currentPassword, newPassword string) (*models.ChangePasswordResponse, error) {
-	// Validate current password by comparing hashes
-	currentPasswordHash := s.hashPassword(currentPassword)
-	if currentPasswordHash != config.AppConfig.Auth.Password {
-		return &models.ChangePasswordResponse{
-			Message: "当前密码不正确",
-			Success: false,
-		}, nil
-	}
-
-	// Validate new password strength
-	if err := s.ValidatePasswordStrength(newPassword); err != nil {
-		return &models.ChangePasswordResponse{
-			Message: err.Error(),
-			Success: false,
-		}, nil
-	}
-
-	// Update password in config (store as hash)
-	if err := s.updatePasswordInConfig(newPassword); err != nil {
+// ChangePassword changes a user's password
+func (s *AuthService) ChangePassword(userID, currentPassword, newPassword string) (*models.ChangePasswordResponse, error) {
+	if err := NewUserService().ChangePassword(userID, currentPassword, newPassword); err != nil {
+		message := "更新密码失败: " + err.Error()
+		if strings.Contains(err.Error(), "current password") {
+			message = "当前密码不正确"
+		} else if strings.HasPrefix(err.Error(), "invalid password: ") {
+			message = strings.TrimPrefix(err.Error(), "invalid password: ")
+		}
 		return &models.ChangePasswordResponse{
-			Message: "更新密码失败: " + err.Error(),
+			Message: message,
 			Success: false,
 		}, nil
 	}
@@ -173,44 +238,3 @@ func (s *AuthService) ChangePassword(currentPassword, newPassword string) (*mode
 		Success: true,
 	}, nil
 }
-
-// updatePasswordInConfig updates password in config file
-func (s *AuthService) updatePasswordInConfig(newPassword string) error {
-	// Hash the new password before storing
-	hashedPassword := s.hashPassword(newPassword)
-
-	// Read current config file
-	configPath := config.DefaultConfigPath
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		return fmt.Errorf("failed to read config file: %v", err)
-	}
-
-	// Parse config
-	var configData map[string]interface{}
-	if err := yaml.Unmarshal(data, &configData); err != nil {
-		return fmt.Errorf("failed to parse config file: %v", err)
-	}
-
-	// Update password with hash
-	if auth, ok := configData["auth"].(map[string]interface{}); ok {
-		auth["password"] = hashedPassword
-	} else {
-		return fmt.Errorf("auth section not found in config")
-	}
-
-	// Write back to file
-	updatedData, err := yaml.Marshal(configData)
-	if err != nil {
-		return fmt.Errorf("failed to marshal config: %v", err)
-	}
-
-	if err := os.WriteFile(configPath, updatedData, 0644); err != nil {
-		return fmt.Errorf("failed to write config file: %v", err)
-	}
-
-	// Update in-memory config with hash
-	config.AppConfig.Auth.Password = hashedPassword
-
-	return nil
-}
\ No newline at end of file
@@ -0,0 +1,476 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"minecraft-easyserver/models"
+	"minecraft-easyserver/utils"
+)
+
+// Ban audit actions
+const (
+	BanActionCreate = "ban"
+	BanActionUpdate = "update"
+	BanActionDelete = "unban"
+	BanActionKick   = "kick"
+
+	// BanActionKickFailed a banned player's kick was never confirmed by the server
+	BanActionKickFailed = "kick_failed"
+)
+
+// banKickAttempts how often a kick the server doesn't answer is sent again before it is
+// recorded as failed
+const banKickAttempts = 3
+
+// banKickNotOnline bedrock's response to kicking a player who isn't online
+const banKickNotOnline = "No targets matched selector"
+
+// banTimeFormat format of ban timestamps
+const banTimeFormat = "2006-01-02 15:04:05"
+
+// BanService keeps the panel's ban list and kicks banned players when they join
+type BanService struct {
+	mutex       sync.Mutex
+	maxAudit    int
+	watchOnce   sync.Once
+	interaction *InteractionService
+}
+
+var banService *BanService
+
+// NewBanService returns the global ban service instance
+func NewBanService() *BanService {
+	if banService == nil {
+		banService = &BanService{
+			maxAudit:    1000, // Keep last 1000 audit entries
+			interaction: GetInteractionService(),
+		}
+	}
+	return banService
+}
+
+// bansPath returns the file bans are stored in
+func bansPath() string {
+	return filepath.Join(dataDir, "bans.json")
+}
+
+// banAuditPath returns the file the ban audit trail is stored in
+func banAuditPath() string {
+	return filepath.Join(dataDir, "ban_audit.json")
+}
+
+// GetBans gets bans, newest first. Expired bans are only included if all is set.
+func (b *BanService) GetBans(all bool) ([]models.Ban, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	bans, err := readBans()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	result := []models.Ban{}
+	for i := len(bans) - 1; i >= 0; i-- {
+		bans[i].Active = banActive(bans[i], now)
+		if all || bans[i].Active {
+			result = append(result, bans[i])
+		}
+	}
+	return result, nil
+}
+
+// CreateBan bans a player by name and/or XUID. duration is empty for a permanent ban,
+// otherwise a Go duration or a number of days such as "7d". Online players are kicked.
+func (b *BanService) CreateBan(name, xuid, reason, duration, user string) (*models.Ban, error) {
+	name = strings.TrimSpace(name)
+	xuid = strings.TrimSpace(xuid)
+	if name == "" && xuid == "" {
+		return nil, fmt.Errorf("invalid ban: name or xuid is required")
+	}
+	if name != "" {
+		if _, err := QuoteCommandArgument(name); err != nil {
+			return nil, fmt.Errorf("invalid ban: %v", err)
+		}
+	}
+	if xuid != "" && !xuidPattern.MatchString(xuid) {
+		return nil, fmt.Errorf("invalid ban: invalid xuid: %s", xuid)
+	}
+
+	now := time.Now()
+	expiresAt, err := banExpiry(duration, now)
+	if err != nil {
+		return nil, err
+	}
+
+	b.mutex.Lock()
+	bans, err := readBans()
+	if err != nil {
+		b.mutex.Unlock()
+		return nil, err
+	}
+	for _, existing := range bans {
+		if banActive(existing, now) && banMatches(existing, name, xuid) {
+			b.mutex.Unlock()
+			return nil, fmt.Errorf("player is already banned: %s", existing.ID)
+		}
+	}
+
+	ban := models.Ban{
+		ID:        strconv.FormatInt(now.UnixNano(), 10),
+		Name:      name,
+		Xuid:      xuid,
+		Reason:    strings.TrimSpace(reason),
+		IssuedBy:  user,
+		CreatedAt: now.Format(banTimeFormat),
+		ExpiresAt: expiresAt,
+		Active:    true,
+	}
+	bans = append(bans, ban)
+	if err := writeBans(bans); err != nil {
+		b.mutex.Unlock()
+		return nil, err
+	}
+	b.audit(BanActionCreate, user, ban, describeBan(ban))
+	b.mutex.Unlock()
+
+	// Kick the player if they are online right now
+	if serverRunning() {
+		go b.kick(ban, name, xuid, "kicked when banned")
+	}
+	return &ban, nil
+}
+
+// UpdateBan changes the reason and/or duration of a ban.
+// A nil argument leaves the field unchanged; an empty duration makes the ban permanent.
+func (b *BanService) UpdateBan(id string, reason, duration *string, user string) (*models.Ban, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	bans, err := readBans()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range bans {
+		if bans[i].ID != id {
+			continue
+		}
+
+		if reason != nil {
+			bans[i].Reason = strings.TrimSpace(*reason)
+		}
+		if duration != nil {
+			expiresAt, err := banExpiry(*duration, time.Now())
+			if err != nil {
+				return nil, err
+			}
+			bans[i].ExpiresAt = expiresAt
+		}
+		bans[i].Active = banActive(bans[i], time.Now())
+
+		if err := writeBans(bans); err != nil {
+			return nil, err
+		}
+		b.audit(BanActionUpdate, user, bans[i], describeBan(bans[i]))
+		return &bans[i], nil
+	}
+	return nil, fmt.Errorf("ban not found: %s", id)
+}
+
+// DeleteBan lifts a ban
+func (b *BanService) DeleteBan(id, user string) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	bans, err := readBans()
+	if err != nil {
+		return err
+	}
+
+	for i, ban := range bans {
+		if ban.ID == id {
+			bans = append(bans[:i], bans[i+1:]...)
+			if err := writeBans(bans); err != nil {
+				return err
+			}
+			b.audit(BanActionDelete, user, ban, "")
+			return nil
+		}
+	}
+	return fmt.Errorf("ban not found: %s", id)
+}
+
+// FindActiveBan returns the active ban matching a player name or XUID, or nil
+func (b *BanService) FindActiveBan(name, xuid string) (*models.Ban, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	bans, err := readBans()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for i := range bans {
+		if banActive(bans[i], now) && banMatches(bans[i], name, xuid) {
+			bans[i].Active = true
+			return &bans[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// GetAudit returns the ban audit trail, newest first
+func (b *BanService) GetAudit(limit int) ([]models.BanAuditEntry, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	entries, err := readBanAudit()
+	if err != nil {
+		return nil, err
+	}
+
+	result := []models.BanAuditEntry{}
+	for i := len(entries) - 1; i >= 0; i-- {
+		result = append(result, entries[i])
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+// StartWatching watches the server log for joining players and kicks banned ones.
+// It is safe to call on every server start; only one watcher is started. The watcher
+// is a log hook rather than a subscription, so joins aren't missed during log bursts.
+func (b *BanService) StartWatching() {
+	b.watchOnce.Do(func() {
+		NewLogService().AddHook(func(entry models.ServerLogEntry) {
+			if entry.Event == LogEventPlayerConnected {
+				go b.handleLogEntry(entry)
+			}
+		})
+	})
+}
+
+// handleLogEntry kicks the player if the entry reports a banned player joining
+func (b *BanService) handleLogEntry(entry models.ServerLogEntry) {
+	if entry.Event != LogEventPlayerConnected {
+		return
+	}
+	name, xuid := entry.Player, entry.Xuid
+
+	ban, err := b.FindActiveBan(name, xuid)
+	if err != nil {
+		slog.Warn("Failed to check bans", "player", name, "error", err)
+		return
+	}
+	if ban != nil {
+		go b.kick(*ban, name, xuid, "kicked on join")
+	}
+}
+
+// kick removes a banned player from the server and records it in the audit trail once
+// the server confirms the kick. A kick that is never confirmed is recorded as failed;
+// nothing is recorded if the player isn't online.
+func (b *BanService) kick(ban models.Ban, name, xuid, details string) {
+	target := xuid
+	if name != "" {
+		quoted, err := QuoteCommandArgument(name)
+		if err != nil {
+			return
+		}
+		target = quoted
+	}
+	if target == "" {
+		return
+	}
+
+	message := "You are banned from this server"
+	if ban.Reason != "" {
+		message += ": " + ban.Reason
+	}
+	if ban.ExpiresAt != "" {
+		message += " (until " + ban.ExpiresAt + ")"
+	}
+	// Keep the reason on a single line
+	message = strings.NewReplacer("\r", " ", "\n", " ").Replace(message)
+
+	command := "kick " + target + " " + message
+	kicked, notOnline := commandResponse(command, name), responseIs(banKickNotOnline)
+	var response string
+	var err error
+	for attempt := 1; attempt <= banKickAttempts; attempt++ {
+		response, err = b.interaction.SendCommandAndWait(command, func(line string) bool {
+			return kicked(line) || notOnline(line)
+		}, liveCommandTimeout)
+		// Only a kick the server didn't answer is worth sending again
+		if err == nil || !strings.Contains(err.Error(), "timed out") {
+			break
+		}
+	}
+	if err == nil && notOnline(response) {
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	player := ban
+	player.Name, player.Xuid = name, xuid
+	if err != nil {
+		slog.Warn("Failed to kick banned player", "player", target, "error", err)
+		b.audit(BanActionKickFailed, "system", player, details+": "+err.Error())
+		return
+	}
+	b.audit(BanActionKick, "system", player, details)
+}
+
+// audit appends an entry to the audit trail; callers must hold the mutex
+func (b *BanService) audit(action, user string, ban models.Ban, details string) {
+	entries, err := readBanAudit()
+	if err != nil {
+		slog.Warn("Failed to read ban audit trail", "error", err)
+		return
+	}
+
+	entries = append(entries, models.BanAuditEntry{
+		Timestamp: time.Now().Format(banTimeFormat),
+		Action:    action,
+		User:      user,
+		BanID:     ban.ID,
+		Name:      ban.Name,
+		Xuid:      ban.Xuid,
+		Details:   details,
+	})
+
+	// Keep only the last maxAudit entries
+	if len(entries) > b.maxAudit {
+		entries = entries[len(entries)-b.maxAudit:]
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err == nil {
+		err = utils.WriteFileAtomic(banAuditPath(), data, 0644)
+	}
+	if err != nil {
+		slog.Warn("Failed to write ban audit trail", "error", err)
+	}
+}
+
+// banExpiry converts a ban duration to an expiry timestamp; empty means permanent
+func banExpiry(duration string, now time.Time) (string, error) {
+	duration = strings.TrimSpace(duration)
+	if duration == "" || duration == "permanent" {
+		return "", nil
+	}
+
+	var length time.Duration
+	if strings.HasSuffix(duration, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(duration, "d"))
+		if err != nil {
+			return "", fmt.Errorf("invalid ban duration: %s", duration)
+		}
+		length = time.Duration(days) * 24 * time.Hour
+	} else {
+		parsed, err := time.ParseDuration(duration)
+		if err != nil {
+			return "", fmt.Errorf("invalid ban duration: %s", duration)
+		}
+		length = parsed
+	}
+
+	if length <= 0 {
+		return "", fmt.Errorf("invalid ban duration: %s", duration)
+	}
+	return now.Add(length).Format(banTimeFormat), nil
+}
+
+// banActive reports whether a ban has not expired yet
+func banActive(ban models.Ban, now time.Time) bool {
+	if ban.ExpiresAt == "" {
+		return true
+	}
+	expiresAt, err := time.ParseInLocation(banTimeFormat, ban.ExpiresAt, time.Local)
+	if err != nil {
+		return true
+	}
+	return now.Before(expiresAt)
+}
+
+// banMatches reports whether a ban covers a player name or XUID
+func banMatches(ban models.Ban, name, xuid string) bool {
+	if ban.Xuid != "" && xuid != "" && ban.Xuid == xuid {
+		return true
+	}
+	return ban.Name != "" && name != "" && strings.EqualFold(ban.Name, name)
+}
+
+// describeBan summarizes a ban for the audit trail
+func describeBan(ban models.Ban) string {
+	expiry := "permanent"
+	if ban.ExpiresAt != "" {
+		expiry = "until " + ban.ExpiresAt
+	}
+	if ban.Reason == "" {
+		return expiry
+	}
+	return expiry + ", reason: " + ban.Reason
+}
+
+// readBans reads bans from disk; callers must hold the mutex
+func readBans() ([]models.Ban, error) {
+	var bans []models.Ban
+
+	data, err := os.ReadFile(bansPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return bans, nil
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &bans); err != nil {
+		return nil, fmt.Errorf("failed to parse bans: %v", err)
+	}
+	return bans, nil
+}
+
+// writeBans writes bans to disk; callers must hold the mutex
+func writeBans(bans []models.Ban) error {
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(bans, "", "  ")
+	if err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(bansPath(), data, 0644)
+}
+
+// readBanAudit reads the audit trail; callers must hold the mutex
+func readBanAudit() ([]models.BanAuditEntry, error) {
+	var entries []models.BanAuditEntry
+
+	data, err := os.ReadFile(banAuditPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return entries, nil
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse ban audit trail: %v", err)
+	}
+	return entries, nil
+}
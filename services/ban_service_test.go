@@ -0,0 +1,149 @@
+package services
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"minecraft-easyserver/models"
+)
+
+func TestBanService(t *testing.T) {
+	originalDataDir := dataDir
+	SetDataDir(t.TempDir())
+	t.Cleanup(func() { SetDataDir(originalDataDir) })
+
+	bans := NewBanService()
+
+	t.Run("CreateAndFind", func(t *testing.T) {
+		ban, err := bans.CreateBan("Griefer", "", "broke the spawn", "7d", "admin")
+		if err != nil {
+			t.Fatal("Failed to create ban:", err)
+		}
+		if ban.ExpiresAt == "" {
+			t.Error("Expected temporary ban to have an expiry")
+		}
+
+		found, err := bans.FindActiveBan("griefer", "")
+		if err != nil || found == nil || found.ID != ban.ID {
+			t.Errorf("Expected ban to match name case-insensitively, got %v, %v", found, err)
+		}
+
+		if _, err := bans.CreateBan("GRIEFER", "", "", "", "admin"); err == nil || !strings.Contains(err.Error(), "already banned") {
+			t.Errorf("Expected duplicate ban error, got %v", err)
+		}
+	})
+
+	t.Run("InvalidDuration", func(t *testing.T) {
+		if _, err := bans.CreateBan("Someone", "", "", "forever", "admin"); err == nil || !strings.Contains(err.Error(), "invalid ban duration") {
+			t.Errorf("Expected invalid duration error, got %v", err)
+		}
+	})
+
+	t.Run("ExpiredBanIsInactive", func(t *testing.T) {
+		ban, err := bans.CreateBan("", "2535400000000009", "", "1h", "admin")
+		if err != nil {
+			t.Fatal("Failed to create ban:", err)
+		}
+
+		// Move the expiry into the past
+		stored, _ := readBans()
+		for i := range stored {
+			if stored[i].ID == ban.ID {
+				stored[i].ExpiresAt = time.Now().Add(-time.Minute).Format(banTimeFormat)
+			}
+		}
+		if err := writeBans(stored); err != nil {
+			t.Fatal("Failed to write bans:", err)
+		}
+
+		if found, _ := bans.FindActiveBan("", "2535400000000009"); found != nil {
+			t.Error("Expected expired ban to be ignored")
+		}
+		active, _ := bans.GetBans(false)
+		all, _ := bans.GetBans(true)
+		if len(all) != len(active)+1 {
+			t.Errorf("Expected expired ban only in full list, got %d active and %d total", len(active), len(all))
+		}
+	})
+
+	t.Run("KicksBannedPlayerOnJoin", func(t *testing.T) {
+		interaction := GetInteractionService()
+		if !interaction.IsEnabled() {
+			t.Skip("Server interaction is not supported on this platform")
+		}
+
+		var mutex sync.Mutex
+		var commands []string
+		interaction.SetStdin(&fakeServerStdin{respond: func(command string) string {
+			mutex.Lock()
+			commands = append(commands, command)
+			mutex.Unlock()
+			if strings.HasPrefix(command, "kick Griefer") {
+				return "Kicked Griefer from the game"
+			}
+			return ""
+		}})
+		t.Cleanup(func() { interaction.SetStdin(nil) })
+
+		bans.StartWatching()
+		NewLogService().AddServerOutput(LogSourceStdout, "[2025-08-07 18:40:12:123 INFO] Player connected: Friendly, xuid: 2535400000000001")
+		// A burst of output must not make the watcher miss a join
+		for i := 0; i < 1000; i++ {
+			NewLogService().AddServerOutput(LogSourceStdout, "[2025-08-07 18:40:13:000 INFO] Running AutoCompaction...")
+		}
+		NewLogService().AddServerOutput(LogSourceStdout, "[2025-08-07 18:40:13:456 INFO] Player connected: Griefer, xuid: 2535400000000002")
+
+		var kicks []models.BanAuditEntry
+		for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+			audit, _ := bans.GetAudit(0)
+			kicks = kicks[:0]
+			for _, entry := range audit {
+				if entry.Action == BanActionKick {
+					kicks = append(kicks, entry)
+				}
+			}
+			if len(kicks) > 0 {
+				break
+			}
+		}
+
+		if len(kicks) != 1 || kicks[0].Name != "Griefer" {
+			t.Fatalf("Expected one kick for Griefer in audit trail, got %+v", kicks)
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		if len(commands) != 1 || !strings.Contains(commands[0], "broke the spawn") {
+			t.Errorf("Expected a single kick command with the ban reason, got %v", commands)
+		}
+	})
+
+	t.Run("RecordsFailedKick", func(t *testing.T) {
+		if !GetInteractionService().IsEnabled() {
+			t.Skip("Server interaction is not supported on this platform")
+		}
+		if _, err := bans.CreateBan("Hacker", "", "", "", "admin"); err != nil {
+			t.Fatal("Failed to create ban:", err)
+		}
+
+		// Without a server to answer, the kick can't be confirmed
+		bans.StartWatching()
+		NewLogService().AddServerOutput(LogSourceStdout, "[2025-08-07 18:41:00:000 INFO] Player connected: Hacker, xuid: 2535400000000003")
+
+		var failed []models.BanAuditEntry
+		for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline) && len(failed) == 0; time.Sleep(20 * time.Millisecond) {
+			audit, _ := bans.GetAudit(0)
+			for _, entry := range audit {
+				if entry.Action == BanActionKickFailed {
+					failed = append(failed, entry)
+				}
+			}
+		}
+
+		if len(failed) != 1 || failed[0].Name != "Hacker" || !strings.Contains(failed[0].Details, "not running") {
+			t.Fatalf("Expected a failed kick for Hacker in audit trail, got %+v", failed)
+		}
+	})
+}
@@ -0,0 +1,429 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"minecraft-easyserver/models"
+	"minecraft-easyserver/utils"
+)
+
+// DefaultCommandRole role whose policy applies to owners and admins without a policy of
+// their own. Other roles without a policy can't send commands.
+const DefaultCommandRole = "default"
+
+// CommandPolicyService decides which console commands may be sent through the panel
+type CommandPolicyService struct {
+	mutex  sync.RWMutex
+	loaded *loadedCommandPolicy
+}
+
+// loadedCommandPolicy a policy as read from disk, with its argument patterns compiled,
+// so commands aren't checked against a policy that is read and compiled every time
+type loadedCommandPolicy struct {
+	path     string
+	modTime  time.Time
+	policy   models.CommandPolicy
+	patterns map[string]*regexp.Regexp // Compiled args_pattern of each rule, by pattern
+}
+
+var commandPolicyService *CommandPolicyService
+
+// NewCommandPolicyService returns the global command policy service instance
+func NewCommandPolicyService() *CommandPolicyService {
+	if commandPolicyService == nil {
+		commandPolicyService = &CommandPolicyService{}
+	}
+	return commandPolicyService
+}
+
+// commandPolicyPath returns the file the command policy is stored in
+func commandPolicyPath() string {
+	return filepath.Join(dataDir, "command_policy.json")
+}
+
+// defaultCommandPolicy policy used until one is saved. Owners and admins use the
+// default role, which keeps the commands the panel used to block and asks for
+// confirmation before commands that hit everyone.
+func defaultCommandPolicy() models.CommandPolicy {
+	return models.CommandPolicy{
+		MaxLength: 256,
+		Roles: map[string]models.CommandRolePolicy{
+			DefaultCommandRole: {
+				DefaultAction: models.CommandRuleAllow,
+				Rules: []models.CommandRule{
+					{
+						ID:          "deny-lifecycle",
+						Action:      models.CommandRuleDeny,
+						Commands:    []string{"stop", "restart", "shutdown", "exit", "quit", "reload"},
+						Description: "Use the panel's start/stop/restart controls instead",
+					},
+					{
+						ID:          "deny-operator",
+						Action:      models.CommandRuleDeny,
+						Commands:    []string{"op", "deop"},
+						Description: "Use the permissions page instead",
+					},
+					{
+						ID:          "deny-bans",
+						Action:      models.CommandRuleDeny,
+						Commands:    []string{"ban", "ban-ip", "pardon", "pardon-ip"},
+						Description: "Use the panel's ban list instead",
+					},
+					{
+						ID:          "deny-whitelist",
+						Action:      models.CommandRuleDeny,
+						Commands:    []string{"whitelist", "allowlist"},
+						Description: "Use the allowlist page instead",
+					},
+					confirmMassTargets,
+				},
+			},
+			// Moderators can look after players but not change the world
+			models.RoleModerator: {
+				DefaultAction: models.CommandRuleDeny,
+				Rules: []models.CommandRule{
+					confirmMassTargets,
+					{
+						ID:          "allow-moderation",
+						Action:      models.CommandRuleAllow,
+						Commands:    []string{"list", "say", "tell", "msg", "w", "kick", "tp", "teleport", "gamemode", "effect", "clear"},
+						Description: "Player moderation",
+					},
+				},
+			},
+			// Viewers can only look
+			models.RoleViewer: {
+				DefaultAction: models.CommandRuleDeny,
+				Rules: []models.CommandRule{
+					{
+						ID:          "allow-list",
+						Action:      models.CommandRuleAllow,
+						Commands:    []string{"list"},
+						Description: "List online players",
+					},
+				},
+			},
+		},
+	}
+}
+
+// confirmMassTargets asks for confirmation before commands that hit every player or
+// entity. Selectors with arguments such as @e[type=!player] still hit many at once.
+var confirmMassTargets = models.CommandRule{
+	ID:          "confirm-mass-targets",
+	Action:      models.CommandRuleConfirm,
+	Commands:    []string{"kill", "clear", "gamemode", "tp", "teleport"},
+	ArgsPattern: `(^|\s)@[ae](\[|\s|$)`,
+	Description: "Affects every player or entity",
+}
+
+// legacyMassTargetsPattern pattern of confirm-mass-targets in policies saved before it
+// covered selectors with arguments
+const legacyMassTargetsPattern = `(^|\s)@[ae](\s|$)`
+
+// GetPolicy gets the current command policy
+func (p *CommandPolicyService) GetPolicy() (models.CommandPolicy, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return readCommandPolicy()
+}
+
+// UpdatePolicy validates and saves a new command policy
+func (p *CommandPolicyService) UpdatePolicy(policy models.CommandPolicy) error {
+	if err := validateCommandPolicy(policy); err != nil {
+		return err
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(policy, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Read the new policy on the next command even if its modification time looks unchanged
+	p.loaded = nil
+	return utils.WriteFileAtomic(commandPolicyPath(), data, 0644)
+}
+
+// ResetPolicy restores the default command policy
+func (p *CommandPolicyService) ResetPolicy() (models.CommandPolicy, error) {
+	policy := defaultCommandPolicy()
+	return policy, p.UpdatePolicy(policy)
+}
+
+// Evaluate checks a command against the policy for a role
+func (p *CommandPolicyService) Evaluate(command, role string) (models.CommandDecision, error) {
+	loaded, err := p.load()
+	if err != nil {
+		return models.CommandDecision{}, err
+	}
+	policy := loaded.policy
+
+	command = strings.TrimSpace(command)
+	decision := models.CommandDecision{Command: command, Role: role}
+
+	if command == "" {
+		decision.Action = models.CommandRuleDeny
+		decision.Reason = "command cannot be empty"
+		return decision, nil
+	}
+	if policy.MaxLength > 0 && len(command) > policy.MaxLength {
+		decision.Action = models.CommandRuleDeny
+		decision.Reason = fmt.Sprintf("command too long (max %d characters)", policy.MaxLength)
+		return decision, nil
+	}
+	// A line break would let one request send several commands
+	if strings.ContainsAny(command, "\r\n") {
+		decision.Action = models.CommandRuleDeny
+		decision.Reason = "command cannot contain line breaks"
+		return decision, nil
+	}
+
+	rolePolicy, exists := policy.Roles[role]
+	if !exists && (role == models.RoleOwner || role == models.RoleAdmin) {
+		rolePolicy, exists = policy.Roles[DefaultCommandRole]
+	}
+	if !exists {
+		decision.Action = models.CommandRuleDeny
+		decision.Reason = fmt.Sprintf("no command policy for role '%s'", role)
+		return decision, nil
+	}
+
+	name, args := splitCommand(command)
+	for _, rule := range rolePolicy.Rules {
+		if commandRuleMatches(rule, loaded.patterns[rule.ArgsPattern], name, args) {
+			decision.Action = rule.Action
+			decision.RuleID = rule.ID
+			decision.Reason = rule.Description
+			return decision, nil
+		}
+	}
+
+	decision.Action = rolePolicy.DefaultAction
+	return decision, nil
+}
+
+// load returns the current policy with its patterns compiled, reading it again only
+// after the saved policy changed
+func (p *CommandPolicyService) load() (*loadedCommandPolicy, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	path := commandPolicyPath()
+	var modTime time.Time
+	if info, err := os.Stat(path); err == nil {
+		modTime = info.ModTime()
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	if p.loaded != nil && p.loaded.path == path && p.loaded.modTime.Equal(modTime) {
+		return p.loaded, nil
+	}
+
+	policy, err := readCommandPolicy()
+	if err != nil {
+		return nil, err
+	}
+	loaded := &loadedCommandPolicy{
+		path:     path,
+		modTime:  modTime,
+		policy:   policy,
+		patterns: make(map[string]*regexp.Regexp),
+	}
+	for _, rolePolicy := range policy.Roles {
+		for _, rule := range rolePolicy.Rules {
+			if rule.ArgsPattern == "" {
+				continue
+			}
+			// Patterns are checked on save; one that still doesn't compile never matches
+			if pattern, err := regexp.Compile(rule.ArgsPattern); err == nil {
+				loaded.patterns[rule.ArgsPattern] = pattern
+			}
+		}
+	}
+	p.loaded = loaded
+	return loaded, nil
+}
+
+// readCommandPolicy reads the saved policy, falling back to the default; callers must hold the mutex
+func readCommandPolicy() (models.CommandPolicy, error) {
+	data, err := os.ReadFile(commandPolicyPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return defaultCommandPolicy(), nil
+		}
+		return models.CommandPolicy{}, err
+	}
+
+	var policy models.CommandPolicy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return models.CommandPolicy{}, fmt.Errorf("failed to parse command policy: %v", err)
+	}
+
+	// Policies saved before roles existed, or without these roles, get the built-in
+	// rules rather than leaving moderators and viewers without a policy
+	builtIn := defaultCommandPolicy()
+	if policy.Roles == nil {
+		policy.Roles = make(map[string]models.CommandRolePolicy)
+	}
+	for _, role := range []string{models.RoleModerator, models.RoleViewer} {
+		if _, exists := policy.Roles[role]; !exists {
+			policy.Roles[role] = builtIn.Roles[role]
+		}
+	}
+	upgradeCommandRules(policy)
+	return policy, nil
+}
+
+// upgradeCommandRules fixes built-in rules in policies saved by older versions:
+// confirm-mass-targets missed selectors with arguments, and deny-whitelist missed
+// the allowlist alias of the whitelist command
+func upgradeCommandRules(policy models.CommandPolicy) {
+	for _, rolePolicy := range policy.Roles {
+		for i := range rolePolicy.Rules {
+			rule := &rolePolicy.Rules[i]
+			switch {
+			case rule.ID == confirmMassTargets.ID && rule.ArgsPattern == legacyMassTargetsPattern:
+				rule.ArgsPattern = confirmMassTargets.ArgsPattern
+			case rule.ID == "deny-whitelist" && ruleNamesCommand(*rule, "whitelist") && !ruleNamesCommand(*rule, "allowlist"):
+				rule.Commands = append(rule.Commands, "allowlist")
+			}
+		}
+	}
+}
+
+// ruleNamesCommand reports whether a rule lists a command by name
+func ruleNamesCommand(rule models.CommandRule, name string) bool {
+	for _, command := range rule.Commands {
+		if strings.EqualFold(command, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// CommandDecisionError converts a decision into the error returned to the caller,
+// or nil if the command may be sent. confirmed acknowledges confirm rules.
+func CommandDecisionError(decision models.CommandDecision, confirmed bool) error {
+	name, _ := splitCommand(decision.Command)
+
+	switch decision.Action {
+	case models.CommandRuleAllow:
+		return nil
+	case models.CommandRuleConfirm:
+		if confirmed {
+			return nil
+		}
+		return fmt.Errorf("command '%s' requires confirmation (rule '%s'%s)", name, decision.RuleID, describeReason(decision.Reason))
+	}
+
+	if decision.RuleID != "" {
+		return fmt.Errorf("command '%s' is not allowed through web interface (rule '%s'%s)", name, decision.RuleID, describeReason(decision.Reason))
+	}
+	if decision.Reason != "" {
+		return fmt.Errorf("command is not allowed: %s", decision.Reason)
+	}
+	return fmt.Errorf("command '%s' is not allowed through web interface (default policy for role '%s')", name, decision.Role)
+}
+
+// describeReason formats a rule description for an error message
+func describeReason(reason string) string {
+	if reason == "" {
+		return ""
+	}
+	return ": " + reason
+}
+
+// splitCommand splits a command into its lower-case name and its arguments
+func splitCommand(command string) (string, string) {
+	command = strings.TrimPrefix(strings.TrimSpace(command), "/")
+	parts := strings.SplitN(command, " ", 2)
+	name := strings.ToLower(parts[0])
+	if len(parts) == 1 {
+		return name, ""
+	}
+	return name, strings.TrimSpace(parts[1])
+}
+
+// commandRuleMatches reports whether a rule applies to a command; pattern is the
+// rule's compiled args_pattern
+func commandRuleMatches(rule models.CommandRule, pattern *regexp.Regexp, name, args string) bool {
+	nameMatches := false
+	for _, command := range rule.Commands {
+		if command == "*" || strings.EqualFold(command, name) {
+			nameMatches = true
+			break
+		}
+	}
+	if !nameMatches {
+		return false
+	}
+
+	if rule.ArgsPattern == "" {
+		return true
+	}
+	return pattern != nil && pattern.MatchString(args)
+}
+
+// validateCommandPolicy checks a policy before it is saved
+func validateCommandPolicy(policy models.CommandPolicy) error {
+	var problems []string
+
+	if policy.MaxLength < 0 {
+		problems = append(problems, "max_length cannot be negative")
+	}
+	if len(policy.Roles) == 0 {
+		problems = append(problems, "at least one role policy is required")
+	}
+
+	validActions := map[string]bool{
+		models.CommandRuleAllow:   true,
+		models.CommandRuleDeny:    true,
+		models.CommandRuleConfirm: true,
+	}
+
+	for role, rolePolicy := range policy.Roles {
+		if !validActions[rolePolicy.DefaultAction] {
+			problems = append(problems, fmt.Sprintf("role %s: default_action must be allow, deny or confirm", role))
+		}
+
+		ids := make(map[string]bool)
+		for i, rule := range rolePolicy.Rules {
+			if rule.ID == "" {
+				problems = append(problems, fmt.Sprintf("role %s: rule %d has no id", role, i+1))
+			} else if ids[rule.ID] {
+				problems = append(problems, fmt.Sprintf("role %s: duplicate rule id %s", role, rule.ID))
+			}
+			ids[rule.ID] = true
+
+			if !validActions[rule.Action] {
+				problems = append(problems, fmt.Sprintf("role %s: rule %s action must be allow, deny or confirm", role, rule.ID))
+			}
+			if len(rule.Commands) == 0 {
+				problems = append(problems, fmt.Sprintf("role %s: rule %s must list at least one command", role, rule.ID))
+			}
+			if rule.ArgsPattern != "" {
+				if _, err := regexp.Compile(rule.ArgsPattern); err != nil {
+					problems = append(problems, fmt.Sprintf("role %s: rule %s has an invalid args_pattern: %v", role, rule.ID, err))
+				}
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid command policy: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"minecraft-easyserver/models"
+)
+
+func TestCommandPolicy(t *testing.T) {
+	originalDataDir := dataDir
+	SetDataDir(t.TempDir())
+	t.Cleanup(func() { SetDataDir(originalDataDir) })
+
+	policies := NewCommandPolicyService()
+	interaction := GetInteractionService()
+
+	t.Run("DefaultPolicy", func(t *testing.T) {
+		allowed := []string{
+			"say Hello (everyone)",
+			`tellraw @a {"rawtext":[{"text":"Welcome $player"}]}`,
+			"/time set day",
+			"kill Steve",
+			"gamemode creative Steve",
+		}
+		for _, command := range allowed {
+			if err := interaction.ValidateCommand(command, "admin", false); err != nil {
+				t.Errorf("Expected %q to be allowed, got %v", command, err)
+			}
+		}
+
+		err := interaction.ValidateCommand("/STOP", "admin", false)
+		if err == nil || !strings.Contains(err.Error(), "deny-lifecycle") {
+			t.Errorf("Expected stop to be denied by deny-lifecycle, got %v", err)
+		}
+
+		err = interaction.ValidateCommand("allowlist add Steve", "admin", false)
+		if err == nil || !strings.Contains(err.Error(), "deny-whitelist") {
+			t.Errorf("Expected the allowlist alias to be denied like whitelist, got %v", err)
+		}
+
+		err = interaction.ValidateCommand("op Steve", "admin", false)
+		if err == nil || !strings.Contains(err.Error(), "deny-operator") {
+			t.Errorf("Expected op to be denied by deny-operator, got %v", err)
+		}
+
+		if err := interaction.ValidateCommand("say hi\nstop", "admin", true); err == nil {
+			t.Error("Expected line breaks to be rejected")
+		}
+		if err := interaction.ValidateCommand("", "admin", false); err == nil {
+			t.Error("Expected empty command to be rejected")
+		}
+	})
+
+	t.Run("ConfirmRequired", func(t *testing.T) {
+		err := interaction.ValidateCommand("kill @a", "admin", false)
+		if err == nil || !strings.Contains(err.Error(), "requires confirmation") || !strings.Contains(err.Error(), "confirm-mass-targets") {
+			t.Errorf("Expected kill @a to require confirmation, got %v", err)
+		}
+		if err := interaction.ValidateCommand("kill @a", "admin", true); err != nil {
+			t.Errorf("Expected confirmed kill @a to be allowed, got %v", err)
+		}
+
+		// Selectors with arguments still hit many players or entities
+		for _, command := range []string{"kill @e[type=!player]", "tp @a[tag=x] Steve", "clear @a[r=10]"} {
+			if err := interaction.ValidateCommand(command, "admin", false); err == nil || !strings.Contains(err.Error(), "requires confirmation") {
+				t.Errorf("Expected %q to require confirmation, got %v", command, err)
+			}
+		}
+	})
+
+	t.Run("PerRolePolicy", func(t *testing.T) {
+		policy := defaultCommandPolicy()
+		policy.Roles["viewer"] = models.CommandRolePolicy{
+			DefaultAction: models.CommandRuleDeny,
+			Rules: []models.CommandRule{
+				{ID: "viewer-list", Action: models.CommandRuleAllow, Commands: []string{"list"}},
+				{ID: "viewer-say", Action: models.CommandRuleAllow, Commands: []string{"say"}, ArgsPattern: `^[^@]*$`},
+			},
+		}
+		if err := policies.UpdatePolicy(policy); err != nil {
+			t.Fatal("Failed to update policy:", err)
+		}
+
+		if err := interaction.ValidateCommand("list", "viewer", false); err != nil {
+			t.Errorf("Expected viewer to run list, got %v", err)
+		}
+		if err := interaction.ValidateCommand("say hello", "viewer", false); err != nil {
+			t.Errorf("Expected viewer to say plain text, got %v", err)
+		}
+		err := interaction.ValidateCommand("say hi @a", "viewer", false)
+		if err == nil || !strings.Contains(err.Error(), "default policy for role 'viewer'") {
+			t.Errorf("Expected viewer say with selector to hit the default deny, got %v", err)
+		}
+
+		// Roles without their own policy fall back to the default role
+		if err := interaction.ValidateCommand("time set day", "admin", false); err != nil {
+			t.Errorf("Expected admin to use the default policy, got %v", err)
+		}
+	})
+
+	t.Run("InvalidPolicy", func(t *testing.T) {
+		policy := models.CommandPolicy{
+			Roles: map[string]models.CommandRolePolicy{
+				DefaultCommandRole: {
+					DefaultAction: "maybe",
+					Rules: []models.CommandRule{
+						{ID: "bad", Action: models.CommandRuleDeny, Commands: []string{"say"}, ArgsPattern: "("},
+						{ID: "bad", Action: models.CommandRuleAllow},
+					},
+				},
+			},
+		}
+		err := policies.UpdatePolicy(policy)
+		if err == nil || !strings.Contains(err.Error(), "invalid command policy") {
+			t.Fatalf("Expected invalid policy error, got %v", err)
+		}
+		for _, problem := range []string{"default_action", "args_pattern", "duplicate rule id", "at least one command"} {
+			if !strings.Contains(err.Error(), problem) {
+				t.Errorf("Expected error to mention %q, got %v", problem, err)
+			}
+		}
+
+		// The previous policy is kept
+		if _, err := policies.Evaluate("list", "viewer"); err != nil {
+			t.Errorf("Expected saved policy to remain readable, got %v", err)
+		}
+		decision, _ := policies.Evaluate("list", "viewer")
+		if decision.RuleID != "viewer-list" {
+			t.Errorf("Expected saved policy to be unchanged, got %+v", decision)
+		}
+	})
+
+	t.Run("SavedPolicyWithoutRoles", func(t *testing.T) {
+		// As saved before user roles existed, or by an admin editing only "default"
+		policy := defaultCommandPolicy()
+		delete(policy.Roles, models.RoleViewer)
+		delete(policy.Roles, models.RoleModerator)
+		if err := policies.UpdatePolicy(policy); err != nil {
+			t.Fatal("Failed to update policy:", err)
+		}
+
+		for _, command := range []string{"give Steve diamond 64", "kill Steve", "time set day"} {
+			if err := interaction.ValidateCommand(command, models.RoleViewer, true); err == nil {
+				t.Errorf("Expected viewer to be denied %q", command)
+			}
+		}
+		if err := interaction.ValidateCommand("time set day", models.RoleModerator, false); err == nil {
+			t.Error("Expected moderator to be denied time set")
+		}
+		if err := interaction.ValidateCommand("kick Steve", models.RoleModerator, false); err != nil {
+			t.Errorf("Expected moderator to get the built-in rules, got %v", err)
+		}
+		if err := interaction.ValidateCommand("time set day", models.RoleAdmin, false); err != nil {
+			t.Errorf("Expected admin to use the default policy, got %v", err)
+		}
+
+		err := interaction.ValidateCommand("list", "", false)
+		if err == nil || !strings.Contains(err.Error(), "no command policy") {
+			t.Errorf("Expected a request without a role to be denied, got %v", err)
+		}
+	})
+
+	t.Run("SavedLegacyRules", func(t *testing.T) {
+		// Saved by an older version, written straight to disk
+		policy := defaultCommandPolicy()
+		rules := policy.Roles[DefaultCommandRole].Rules
+		for i := range rules {
+			switch rules[i].ID {
+			case "confirm-mass-targets":
+				rules[i].ArgsPattern = legacyMassTargetsPattern
+			case "deny-whitelist":
+				rules[i].Commands = []string{"whitelist"}
+			}
+		}
+		data, _ := json.Marshal(policy)
+		if err := os.WriteFile(commandPolicyPath(), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := interaction.ValidateCommand("kill @e[type=!player]", models.RoleAdmin, false); err == nil || !strings.Contains(err.Error(), "requires confirmation") {
+			t.Errorf("Expected the saved mass target rule to cover selector arguments, got %v", err)
+		}
+		if err := interaction.ValidateCommand("allowlist off", models.RoleAdmin, false); err == nil || !strings.Contains(err.Error(), "deny-whitelist") {
+			t.Errorf("Expected the saved whitelist rule to cover allowlist, got %v", err)
+		}
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		if _, err := policies.ResetPolicy(); err != nil {
+			t.Fatal("Failed to reset policy:", err)
+		}
+		decision, err := policies.Evaluate("list", "viewer")
+		if err != nil || decision.Action != models.CommandRuleAllow || decision.RuleID != "allow-list" {
+			t.Errorf("Expected the built-in viewer policy after reset, got %+v, %v", decision, err)
+		}
+		decision, _ = policies.Evaluate("say hello", "viewer")
+		if decision.Action != models.CommandRuleDeny {
+			t.Errorf("Expected viewers to be denied other commands after reset, got %+v", decision)
+		}
+		decision, _ = policies.Evaluate("kick Steve", "moderator")
+		if decision.Action != models.CommandRuleAllow {
+			t.Errorf("Expected moderators to be allowed to kick, got %+v", decision)
+		}
+	})
+}
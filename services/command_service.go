@@ -165,8 +165,9 @@ func (cs *CommandService) GetQuickCommandByID(id string) (*models.QuickCommand,
 	return nil, fmt.Errorf("quick command with ID '%s' not found", id)
 }
 
-// ExecuteQuickCommand executes a quick command by ID
-func (cs *CommandService) ExecuteQuickCommand(id string) error {
+// ExecuteQuickCommand executes a quick command by ID, checking it against the
+// command policy for role. confirmed acknowledges commands that need confirmation.
+func (cs *CommandService) ExecuteQuickCommand(id, role string, confirmed bool) error {
 	cmd, err := cs.GetQuickCommandByID(id)
 	if err != nil {
 		return err
@@ -179,7 +180,7 @@ func (cs *CommandService) ExecuteQuickCommand(id string) error {
 	}
 
 	// Validate and send the command
-	if err := interactionSvc.ValidateCommand(cmd.Command); err != nil {
+	if err := interactionSvc.ValidateCommand(cmd.Command, role, confirmed); err != nil {
 		return fmt.Errorf("command validation failed: %v", err)
 	}
 
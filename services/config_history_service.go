@@ -0,0 +1,323 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"minecraft-easyserver/models"
+	"minecraft-easyserver/utils"
+)
+
+// ConfigHistoryService keeps versions of the server data files edited through the panel
+type ConfigHistoryService struct {
+	mutex      sync.Mutex
+	maxHistory int
+}
+
+var configHistoryService *ConfigHistoryService
+
+// NewConfigHistoryService returns the global config history service instance
+func NewConfigHistoryService() *ConfigHistoryService {
+	if configHistoryService == nil {
+		configHistoryService = &ConfigHistoryService{
+			maxHistory: 200, // Keep last 200 changes
+		}
+	}
+	return configHistoryService
+}
+
+// historyDir returns the directory holding the change index and file snapshots
+func historyDir() string {
+	return filepath.Join(dataDir, "history")
+}
+
+// GetHistory returns recorded changes, newest first.
+// file optionally filters by file name (e.g. "server.properties").
+func (h *ConfigHistoryService) GetHistory(file string, limit int) ([]models.ConfigChange, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	changes, err := h.readIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	result := []models.ConfigChange{}
+	for i := len(changes) - 1; i >= 0; i-- {
+		if file != "" && changes[i].File != file {
+			continue
+		}
+		result = append(result, changes[i])
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+// GetDiff returns a recorded change together with its line diff
+func (h *ConfigHistoryService) GetDiff(id string) (*models.ConfigChange, []models.ConfigDiffLine, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	change, err := h.findChange(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	before, after, err := h.readSnapshots(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return change, diffLines(string(before), string(after)), nil
+}
+
+// Rollback restores a file to the content it had before the given change.
+// The rollback itself is recorded as a new change.
+func (h *ConfigHistoryService) Rollback(id, user string) (*models.ConfigChange, error) {
+	// If no server version is active, return error
+	if bedrockPath == "" {
+		return nil, fmt.Errorf("no server version is currently active. Please download and activate a server version first")
+	}
+
+	h.mutex.Lock()
+	change, err := h.findChange(id)
+	if err != nil {
+		h.mutex.Unlock()
+		return nil, err
+	}
+	before, _, err := h.readSnapshots(id)
+	h.mutex.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(before) == 0 {
+		return nil, fmt.Errorf("cannot roll back change %s: %s did not exist before it", id, change.File)
+	}
+
+	path := filepath.Join(bedrockPath, change.File)
+	current, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if err := utils.WriteFileAtomic(path, before, 0644); err != nil {
+		return nil, fmt.Errorf("failed to restore %s: %v", change.File, err)
+	}
+
+	// Push the restored content to the running server where possible
+	if change.File == "server.properties" {
+		currentProperties, _ := parseServerProperties(current)
+		restoredProperties, _ := parseServerProperties(before)
+		applyPropertyChanges(currentProperties, restoredProperties)
+	} else {
+		reloadLiveFile(change.File)
+	}
+
+	return h.record(change.File, current, before, user, id)
+}
+
+// record stores before/after snapshots of a file and appends the change to the index
+func (h *ConfigHistoryService) record(file string, before, after []byte, user, rollbackOf string) (*models.ConfigChange, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if err := os.MkdirAll(historyDir(), 0755); err != nil {
+		return nil, err
+	}
+
+	changes, err := h.readIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	id := strconv.FormatInt(now.UnixNano(), 10)
+	if err := utils.WriteFileAtomic(filepath.Join(historyDir(), id+".before"), before, 0644); err != nil {
+		return nil, err
+	}
+	if err := utils.WriteFileAtomic(filepath.Join(historyDir(), id+".after"), after, 0644); err != nil {
+		return nil, err
+	}
+
+	added, removed := 0, 0
+	for _, line := range diffLines(string(before), string(after)) {
+		switch line.Op {
+		case "+":
+			added++
+		case "-":
+			removed++
+		}
+	}
+
+	change := models.ConfigChange{
+		ID:         id,
+		File:       file,
+		User:       user,
+		Timestamp:  now.Format("2006-01-02 15:04:05"),
+		Added:      added,
+		Removed:    removed,
+		RollbackOf: rollbackOf,
+	}
+	changes = append(changes, change)
+
+	// Keep only the last maxHistory changes and drop snapshots of older ones
+	if len(changes) > h.maxHistory {
+		for _, old := range changes[:len(changes)-h.maxHistory] {
+			os.Remove(filepath.Join(historyDir(), old.ID+".before"))
+			os.Remove(filepath.Join(historyDir(), old.ID+".after"))
+		}
+		changes = changes[len(changes)-h.maxHistory:]
+	}
+
+	if err := h.writeIndex(changes); err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+// findChange looks up a change by id; callers must hold the mutex
+func (h *ConfigHistoryService) findChange(id string) (*models.ConfigChange, error) {
+	changes, err := h.readIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range changes {
+		if changes[i].ID == id {
+			return &changes[i], nil
+		}
+	}
+	return nil, fmt.Errorf("config change not found: %s", id)
+}
+
+// readSnapshots reads the before/after content recorded for a change
+func (h *ConfigHistoryService) readSnapshots(id string) ([]byte, []byte, error) {
+	before, err := os.ReadFile(filepath.Join(historyDir(), id+".before"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("snapshot for config change %s not found", id)
+	}
+	after, err := os.ReadFile(filepath.Join(historyDir(), id+".after"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("snapshot for config change %s not found", id)
+	}
+	return before, after, nil
+}
+
+// readIndex reads the change index; callers must hold the mutex
+func (h *ConfigHistoryService) readIndex() ([]models.ConfigChange, error) {
+	var changes []models.ConfigChange
+
+	data, err := os.ReadFile(filepath.Join(historyDir(), "index.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return changes, nil
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &changes); err != nil {
+		return nil, fmt.Errorf("failed to parse config history: %v", err)
+	}
+	return changes, nil
+}
+
+// writeIndex writes the change index; callers must hold the mutex
+func (h *ConfigHistoryService) writeIndex(changes []models.ConfigChange) error {
+	data, err := json.MarshalIndent(changes, "", "  ")
+	if err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(filepath.Join(historyDir(), "index.json"), data, 0644)
+}
+
+// writeVersionedFile writes a server data file and records the change in the config history
+func writeVersionedFile(path string, data []byte, user string) error {
+	previous, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := utils.WriteFileAtomic(path, data, 0644); err != nil {
+		return err
+	}
+	recordFileChange(path, previous, data, user)
+	return nil
+}
+
+// recordFileChange adds a history entry for a file that has been written, if it changed
+func recordFileChange(path string, previous, data []byte, user string) {
+	if bytes.Equal(previous, data) {
+		return
+	}
+	if _, err := NewConfigHistoryService().record(filepath.Base(path), previous, data, user, ""); err != nil {
+		// The write itself succeeded, so only warn about the missing history entry
+		slog.Warn("Failed to record config history", "file", path, "error", err)
+	}
+}
+
+// diffLines computes a line diff between two texts using the longest common subsequence
+func diffLines(before, after string) []models.ConfigDiffLine {
+	a := splitLines(before)
+	b := splitLines(after)
+
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	diff := []models.ConfigDiffLine{}
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			diff = append(diff, models.ConfigDiffLine{Op: " ", Text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, models.ConfigDiffLine{Op: "-", Text: a[i]})
+			i++
+		default:
+			diff = append(diff, models.ConfigDiffLine{Op: "+", Text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		diff = append(diff, models.ConfigDiffLine{Op: "-", Text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		diff = append(diff, models.ConfigDiffLine{Op: "+", Text: b[j]})
+	}
+	return diff
+}
+
+// splitLines splits text into lines, ignoring a trailing newline
+func splitLines(text string) []string {
+	text = strings.TrimRight(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
@@ -2,9 +2,11 @@ package services
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -19,48 +21,56 @@ func NewConfigService() *ConfigService {
 	return &ConfigService{}
 }
 
-// GetConfig gets server configuration
-func (c *ConfigService) GetConfig() (models.ServerConfig, error) {
+// GetConfig gets all server.properties values, typed according to the schema.
+// Keys that are not in the schema are returned as strings; schema keys missing
+// from the file get their defaults, as the server itself would use.
+func (c *ConfigService) GetConfig() (models.ServerProperties, error) {
 	// If no server version is active, return default configuration
 	if bedrockPath == "" {
-		return getDefaultServerConfig(), nil
+		return defaultServerProperties(), nil
 	}
 	
 	configPath := filepath.Join(bedrockPath, "server.properties")
 	
 	// If server.properties doesn't exist, return default configuration
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		return getDefaultServerConfig(), nil
+		return defaultServerProperties(), nil
 	}
 	
-	return readServerProperties(configPath)
-}
+	rawProperties, err := readServerPropertyMap(configPath)
+	if err != nil {
+		return nil, err
+	}
 
-// getDefaultServerConfig returns default server configuration
-func getDefaultServerConfig() models.ServerConfig {
-	return models.ServerConfig{
-		ServerName:               "Bedrock Server",
-		Gamemode:                 "survival",
-		Difficulty:               "easy",
-		MaxPlayers:               10,
-		ServerPort:               19132,
-		AllowCheats:              false,
-		AllowList:                false,
-		OnlineMode:               true,
-		LevelName:                "Bedrock level",
-		DefaultPlayerPermission:  "member",
+	properties := defaultServerProperties()
+	for key, value := range rawProperties {
+		properties[key] = typedPropertyValue(key, value)
 	}
+	return properties, nil
+}
+
+// GetSchema gets the server.properties schema
+func (c *ConfigService) GetSchema() []models.ServerPropertySchema {
+	return GetServerPropertiesSchema()
 }
 
-// UpdateConfig updates server configuration
-func (c *ConfigService) UpdateConfig(config models.ServerConfig) error {
+// UpdateConfig validates the given values against the schema and writes them to
+// server.properties. Keys not in the request are left untouched, keys missing
+// from the file are added. Changes the running server can apply live are pushed
+// to it; the result lists the keys that still need a restart.
+func (c *ConfigService) UpdateConfig(values models.ServerProperties, user string) (models.ConfigUpdateResult, error) {
 	// If no server version is active, return error
 	if bedrockPath == "" {
-		return fmt.Errorf("no server version is currently active. Please download and activate a server version first")
+		return models.ConfigUpdateResult{}, fmt.Errorf("no server version is currently active. Please download and activate a server version first")
 	}
 	
+	formatted, err := validateServerProperties(values)
+	if err != nil {
+		return models.ConfigUpdateResult{}, err
+	}
+
 	configPath := filepath.Join(bedrockPath, "server.properties")
-	return writeServerProperties(configPath, config)
+	return setServerProperties(configPath, formatted, user)
 }
 
 // Read server.properties
@@ -119,63 +129,93 @@ func readServerProperties(path string) (models.ServerConfig, error) {
 	return config, scanner.Err()
 }
 
-// Write server.properties
-func writeServerProperties(path string, config models.ServerConfig) error {
-	// Create configuration map
-	configMap := map[string]string{
-		"server-name":                     config.ServerName,
-		"gamemode":                        config.Gamemode,
-		"difficulty":                      config.Difficulty,
-		"max-players":                     strconv.Itoa(config.MaxPlayers),
-		"server-port":                     strconv.Itoa(config.ServerPort),
-		"allow-cheats":                    strconv.FormatBool(config.AllowCheats),
-		"allow-list":                      strconv.FormatBool(config.AllowList),
-		"online-mode":                     strconv.FormatBool(config.OnlineMode),
-		"level-name":                      config.LevelName,
-		"default-player-permission-level": config.DefaultPlayerPermission,
-	}
-
-	// Read original file
-	file, err := os.Open(path)
+// readServerPropertyMap reads all key/value pairs from server.properties
+func readServerPropertyMap(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return err
+		return make(map[string]string), err
 	}
-	defer file.Close()
+	return parseServerProperties(data)
+}
 
-	var lines []string
-	scanner := bufio.NewScanner(file)
+// parseServerProperties parses key/value pairs from server.properties content
+func parseServerProperties(data []byte) (map[string]string, error) {
+	properties := make(map[string]string)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
 	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		properties[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
 	}
-	file.Close()
 
-	// Write new file
-	writer, err := os.Create(path)
+	return properties, scanner.Err()
+}
+
+// setServerProperties updates the given keys in server.properties on behalf of user.
+// Keys that are missing from the file are appended instead of being dropped.
+// The changed keys are applied to the running server where possible.
+func setServerProperties(path string, values map[string]string, user string) (models.ConfigUpdateResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return models.ConfigUpdateResult{}, err
+	}
+	before, err := parseServerProperties(data)
 	if err != nil {
-		return err
+		return models.ConfigUpdateResult{}, err
 	}
-	defer writer.Close()
 
-	for _, line := range lines {
+	var lines []string
+	if len(data) > 0 {
+		lines = strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	}
+
+	written := make(map[string]bool)
+	for i, line := range lines {
 		trimmedLine := strings.TrimSpace(line)
 		if trimmedLine == "" || strings.HasPrefix(trimmedLine, "#") {
-			writer.WriteString(line + "\n")
 			continue
 		}
 
-		parts := strings.SplitN(line, "=", 2)
+		parts := strings.SplitN(trimmedLine, "=", 2)
 		if len(parts) != 2 {
-			writer.WriteString(line + "\n")
 			continue
 		}
 
 		key := strings.TrimSpace(parts[0])
-		if newValue, exists := configMap[key]; exists {
-			writer.WriteString(fmt.Sprintf("%s=%s\n", key, newValue))
-		} else {
-			writer.WriteString(line + "\n")
+		if newValue, exists := values[key]; exists {
+			lines[i] = fmt.Sprintf("%s=%s", key, newValue)
+			written[key] = true
 		}
 	}
 
-	return nil
-}
\ No newline at end of file
+	// Append missing keys in a stable order
+	var missing []string
+	for key := range values {
+		if !written[key] {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+	for _, key := range missing {
+		lines = append(lines, fmt.Sprintf("%s=%s", key, values[key]))
+	}
+
+	newData := []byte(strings.Join(lines, "\n") + "\n")
+	if err := writeVersionedFile(path, newData, user); err != nil {
+		return models.ConfigUpdateResult{}, err
+	}
+
+	after, err := parseServerProperties(newData)
+	if err != nil {
+		return models.ConfigUpdateResult{}, err
+	}
+	return applyPropertyChanges(before, after), nil
+}
@@ -0,0 +1,253 @@
+package services
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"minecraft-easyserver/models"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// consoleResponseWait how long a command waits for its response
+	consoleResponseWait = time.Second
+	// consoleQueueSize messages buffered per client before it is treated as too slow
+	consoleQueueSize = 256
+	// consoleHistorySize log entries sent to a client when it connects
+	consoleHistorySize = 100
+)
+
+// consoleCommandResponses bedrock's response to commands whose output can be recognized,
+// by command name. Other commands get no response in their result; their output still
+// appears in the log stream.
+var consoleCommandResponses = map[string]*regexp.Regexp{
+	"list":       regexp.MustCompile(`^There are \d+/\d+ players online:`),
+	"kick":       regexp.MustCompile(`^Kicked .+ from the game`),
+	"tp":         regexp.MustCompile(`^Teleported .+ to `),
+	"teleport":   regexp.MustCompile(`^Teleported .+ to `),
+	"time":       regexp.MustCompile(`^(Set the time to|Added .+ to the time|Time is) `),
+	"difficulty": regexp.MustCompile(`^Set game difficulty to `),
+	"weather":    regexp.MustCompile(`^Changing to .+ weather`),
+	"gamemode":   regexp.MustCompile(`^Set .+ game mode to `),
+	"effect":     regexp.MustCompile(`^(Gave .+ to .+ for \d+ seconds|Took all effects from )`),
+	"clear":      regexp.MustCompile(`^(Cleared the inventory of .+, removing \d+ items|Could not clear the inventory of .+, no items to remove)`),
+	"allowlist":  regexp.MustCompile(`^(Player (added to|already in|removed from|not in) allowlist|AllowList file successfully reloaded)`),
+	"permission": regexp.MustCompile(`^Permissions file successfully reloaded`),
+}
+
+// Player events reported on the console
+const (
+	PlayerEventConnected    = "connected"
+	PlayerEventDisconnected = "disconnected"
+)
+
+// ConsoleService serves the bidirectional console WebSocket: log lines, status changes
+// and player events from the event service go out, commands come in and are answered
+// with their result.
+type ConsoleService struct {
+	upgrader    websocket.Upgrader
+	logs        *LogService
+	interaction *InteractionService
+}
+
+// consoleClient a connected console WebSocket
+type consoleClient struct {
+	*wsClient
+	auth StreamAuth
+}
+
+var consoleService *ConsoleService
+
+// NewConsoleService returns the global console service instance
+func NewConsoleService() *ConsoleService {
+	if consoleService == nil {
+		consoleService = &ConsoleService{
+			upgrader: websocket.Upgrader{
+				CheckOrigin: func(r *http.Request) bool {
+					return true // Allow all origins for development
+				},
+			},
+			logs:        NewLogService(),
+			interaction: GetInteractionService(),
+		}
+	}
+	return consoleService
+}
+
+// HandleConsole upgrades a request to a console WebSocket for the user auth identifies.
+// Commands are checked against the command policy for the user's current role; only
+// log lines passing matcher are sent. The connection is closed once the user's session
+// is revoked or they lose access to the console.
+func (cs *ConsoleService) HandleConsole(w http.ResponseWriter, r *http.Request, auth StreamAuth, matcher *LogMatcher) {
+	conn, err := cs.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		slog.Warn("Console WebSocket upgrade failed", "error", err)
+		return
+	}
+
+	client := &consoleClient{
+		wsClient: newWSClient("Console", conn, consoleQueueSize),
+		auth:     auth,
+	}
+
+	// Subscribe before reading the history so no line falls in between
+	events, unsubscribe := NewEventService().Subscribe(consoleQueueSize)
+	defer unsubscribe()
+
+	defer client.close()
+
+	go auth.Watch(models.PermissionView, client.done, client.close)
+
+	client.enqueue(statusMessage(NewServerService().GetStatus()))
+	for _, entry := range cs.logs.searchMemory(matcher, 0, consoleHistorySize).Logs {
+		client.enqueue(logMessage(entry))
+	}
+
+	go func() {
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					// Dropped by the event service for falling behind
+					client.close()
+					return
+				}
+				switch event.Topic {
+				case EventTopicLog:
+					if entry := event.Data.(models.ServerLogEntry); matcher.Match(entry) {
+						client.enqueue(logMessage(entry))
+					}
+				case EventTopicStatus:
+					client.enqueue(statusMessage(event.Data.(models.ServerStatus)))
+				case EventTopicPlayer:
+					client.enqueue(newConsoleMessage(models.ConsoleMessagePlayer, "", event.Data))
+				}
+			case <-client.done:
+				return
+			}
+		}
+	}()
+
+	client.readPump(func(data []byte) {
+		cs.handleMessage(client, data)
+	})
+}
+
+// handleMessage handles a message from a client
+func (cs *ConsoleService) handleMessage(client *consoleClient, data []byte) {
+	var message models.ConsoleMessage
+	if err := json.Unmarshal(data, &message); err != nil {
+		// Malformed JSON leaves the connection usable
+		client.enqueue(consoleError("", "invalid message: "+err.Error()))
+		return
+	}
+
+	switch message.Type {
+	case models.ConsoleMessagePing:
+		client.enqueue(newConsoleMessage(models.ConsoleMessagePong, message.ID, nil))
+	case models.ConsoleMessageCommand:
+		go cs.runCommand(client, message)
+	default:
+		client.enqueue(consoleError(message.ID, "unknown message type: "+message.Type))
+	}
+}
+
+// runCommand validates and sends a command, then answers with a command_result
+// carrying the command's message ID
+func (cs *ConsoleService) runCommand(client *consoleClient, message models.ConsoleMessage) {
+	command := strings.TrimSpace(message.Command)
+	result := models.ConsoleCommandResult{Command: command}
+	reply := newConsoleMessage(models.ConsoleMessageCommandResult, message.ID, &result)
+
+	if !cs.interaction.IsEnabled() {
+		reply.Error = "server interaction is not supported on this platform"
+		client.enqueue(reply)
+		return
+	}
+
+	// Viewers may watch the console but, as on POST /interaction/command, sending
+	// commands needs the moderate permission. The user is looked up again for every
+	// command, so a revoked session or changed role applies to an open console.
+	user, err := client.auth.Check(models.PermissionModerate)
+	if err != nil {
+		reply.Error = "Permission denied: " + err.Error()
+		client.enqueue(reply)
+		return
+	}
+
+	if err := cs.interaction.ValidateCommand(command, user.Role, message.Confirm); err != nil {
+		reply.Error = err.Error()
+		result.ConfirmRequired = strings.Contains(err.Error(), "requires confirmation")
+		client.enqueue(reply)
+		return
+	}
+
+	// Other output, such as chat or players joining, can come between a command and its
+	// response, so only a line recognized as this command's response is attached
+	name, _ := splitCommand(command)
+	pattern, known := consoleCommandResponses[name]
+	if !known {
+		if err := cs.interaction.SendCommand(command); err != nil {
+			reply.Error = err.Error()
+			client.enqueue(reply)
+			return
+		}
+		result.Success = true
+		client.enqueue(reply)
+		return
+	}
+
+	response, err := cs.interaction.SendCommandAndWait(command, func(line string) bool {
+		return pattern.MatchString(strings.TrimSpace(line))
+	}, consoleResponseWait)
+	if err != nil && !strings.Contains(err.Error(), "timed out") {
+		reply.Error = err.Error()
+		client.enqueue(reply)
+		return
+	}
+
+	result.Success = true
+	result.Response = response
+	client.enqueue(reply)
+}
+
+// enqueue queues a message for the client
+func (c *consoleClient) enqueue(message models.ConsoleMessage) {
+	c.enqueueJSON(message)
+}
+
+// newConsoleMessage creates an outgoing console message
+func newConsoleMessage(messageType, id string, data interface{}) models.ConsoleMessage {
+	return models.ConsoleMessage{
+		Type:      messageType,
+		ID:        id,
+		Timestamp: time.Now().Format("2006-01-02 15:04:05"),
+		Data:      data,
+	}
+}
+
+// logMessage wraps a log entry in a console message
+func logMessage(entry models.ServerLogEntry) models.ConsoleMessage {
+	return models.ConsoleMessage{
+		Type:      models.ConsoleMessageLog,
+		Timestamp: entry.Timestamp,
+		Data:      entry,
+	}
+}
+
+// statusMessage wraps a server status in a console message
+func statusMessage(status models.ServerStatus) models.ConsoleMessage {
+	return newConsoleMessage(models.ConsoleMessageStatus, "", status)
+}
+
+// consoleError creates an error message, optionally answering the message with id
+func consoleError(id, text string) models.ConsoleMessage {
+	message := newConsoleMessage(models.ConsoleMessageError, id, nil)
+	message.Error = text
+	return message
+}
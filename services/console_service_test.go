@@ -0,0 +1,213 @@
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"minecraft-easyserver/models"
+
+	"github.com/gorilla/websocket"
+)
+
+// newStreamAuth creates a user with role and a login session for them
+func newStreamAuth(t *testing.T, username, role string) StreamAuth {
+	user, err := NewUserService().CreateUser(models.CreateUserRequest{
+		Username: username,
+		Password: "Console-pass1",
+		Role:     role,
+	}, models.User{Role: models.RoleOwner})
+	if err != nil {
+		t.Fatal("Failed to create user:", err)
+	}
+	session, _, err := NewSessionService().CreateSession(user.ID, "127.0.0.1", "test")
+	if err != nil {
+		t.Fatal("Failed to create session:", err)
+	}
+	return StreamAuth{UserID: user.ID, SessionID: session.ID}
+}
+
+// dialConsole starts a console WebSocket server for auth and connects to it
+func dialConsole(t *testing.T, auth StreamAuth) *websocket.Conn {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		NewConsoleService().HandleConsole(w, r, auth, nil)
+	}))
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatal("Failed to connect to console:", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// readConsoleMessage reads messages until one matches, failing after a timeout
+func readConsoleMessage(t *testing.T, conn *websocket.Conn, match func(models.ConsoleMessage) bool) models.ConsoleMessage {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		var message models.ConsoleMessage
+		if err := conn.ReadJSON(&message); err != nil {
+			t.Fatal("Failed to read expected console message:", err)
+		}
+		if match(message) {
+			return message
+		}
+	}
+}
+
+func TestConsoleService(t *testing.T) {
+	originalDataDir := dataDir
+	SetDataDir(t.TempDir())
+	t.Cleanup(func() { SetDataDir(originalDataDir) })
+
+	NewLogService().AddLogEntry("INFO", "console history line")
+	conn := dialConsole(t, newStreamAuth(t, "console-admin", models.RoleAdmin))
+
+	t.Run("InitialStatusAndHistory", func(t *testing.T) {
+		status := readConsoleMessage(t, conn, func(m models.ConsoleMessage) bool { return true })
+		if status.Type != models.ConsoleMessageStatus {
+			t.Errorf("Expected status as first message, got %+v", status)
+		}
+		readConsoleMessage(t, conn, func(m models.ConsoleMessage) bool {
+			entry, ok := m.Data.(map[string]interface{})
+			return m.Type == models.ConsoleMessageLog && ok && entry["message"] == "console history line"
+		})
+	})
+
+	t.Run("PingPong", func(t *testing.T) {
+		if err := conn.WriteJSON(models.ConsoleMessage{Type: models.ConsoleMessagePing, ID: "p1"}); err != nil {
+			t.Fatal(err)
+		}
+		readConsoleMessage(t, conn, func(m models.ConsoleMessage) bool {
+			return m.Type == models.ConsoleMessagePong && m.ID == "p1"
+		})
+	})
+
+	t.Run("PlayerEvents", func(t *testing.T) {
+		NewLogService().AddServerOutput(LogSourceStdout, "[2025-08-07 18:52:40:456 INFO] Player disconnected: Some Player, xuid: 2535400000000001")
+		message := readConsoleMessage(t, conn, func(m models.ConsoleMessage) bool {
+			return m.Type == models.ConsoleMessagePlayer
+		})
+		event := message.Data.(map[string]interface{})
+		if event["event"] != PlayerEventDisconnected || event["name"] != "Some Player" || event["xuid"] != "2535400000000001" {
+			t.Errorf("Unexpected player event: %+v", event)
+		}
+	})
+
+	t.Run("StatusChange", func(t *testing.T) {
+		NewEventService().Publish(EventTopicStatus, models.ServerStatus{Status: "stopped", Message: "Server not running"})
+		message := readConsoleMessage(t, conn, func(m models.ConsoleMessage) bool {
+			return m.Type == models.ConsoleMessageStatus
+		})
+		if status := message.Data.(map[string]interface{}); status["status"] != "stopped" {
+			t.Errorf("Unexpected status: %+v", status)
+		}
+	})
+
+	t.Run("InvalidMessage", func(t *testing.T) {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte("not json")); err != nil {
+			t.Fatal(err)
+		}
+		readConsoleMessage(t, conn, func(m models.ConsoleMessage) bool {
+			return m.Type == models.ConsoleMessageError && strings.Contains(m.Error, "invalid message")
+		})
+
+		// The connection stays usable
+		if err := conn.WriteJSON(models.ConsoleMessage{Type: models.ConsoleMessagePing, ID: "p2"}); err != nil {
+			t.Fatal(err)
+		}
+		readConsoleMessage(t, conn, func(m models.ConsoleMessage) bool {
+			return m.Type == models.ConsoleMessagePong && m.ID == "p2"
+		})
+	})
+
+	interaction := GetInteractionService()
+	if !interaction.IsEnabled() {
+		t.Skip("Server interaction is not supported on this platform")
+	}
+
+	t.Run("CommandRejectedByPolicy", func(t *testing.T) {
+		if err := conn.WriteJSON(models.ConsoleMessage{Type: models.ConsoleMessageCommand, ID: "c1", Command: "kill @a"}); err != nil {
+			t.Fatal(err)
+		}
+		message := readConsoleMessage(t, conn, func(m models.ConsoleMessage) bool {
+			return m.Type == models.ConsoleMessageCommandResult && m.ID == "c1"
+		})
+		result := message.Data.(map[string]interface{})
+		if result["success"] == true || result["confirm_required"] != true || !strings.Contains(message.Error, "confirm-mass-targets") {
+			t.Errorf("Expected confirmation to be required, got %+v", message)
+		}
+	})
+
+	t.Run("CommandResult", func(t *testing.T) {
+		interaction.SetStdin(&fakeServerStdin{respond: func(command string) string {
+			switch command {
+			case "list":
+				// Unrelated output arriving first isn't taken as the response
+				return "[2025-08-07 18:40:12:000 INFO] Player connected: Steve, xuid: 2535400000000002\n" +
+					"[2025-08-07 18:40:12:000 INFO] There are 0/10 players online:"
+			case "say hello":
+				return "[2025-08-07 18:40:12:000 INFO] Running AutoCompaction..."
+			}
+			return ""
+		}})
+		t.Cleanup(func() { interaction.SetStdin(nil) })
+
+		if err := conn.WriteJSON(models.ConsoleMessage{Type: models.ConsoleMessageCommand, ID: "c2", Command: "list"}); err != nil {
+			t.Fatal(err)
+		}
+		message := readConsoleMessage(t, conn, func(m models.ConsoleMessage) bool {
+			return m.Type == models.ConsoleMessageCommandResult && m.ID == "c2"
+		})
+		result := message.Data.(map[string]interface{})
+		if result["success"] != true || result["response"] != "There are 0/10 players online:" {
+			t.Errorf("Unexpected command result: %+v", message)
+		}
+
+		if err := conn.WriteJSON(models.ConsoleMessage{Type: models.ConsoleMessageCommand, ID: "c3", Command: "say hello"}); err != nil {
+			t.Fatal(err)
+		}
+		message = readConsoleMessage(t, conn, func(m models.ConsoleMessage) bool {
+			return m.Type == models.ConsoleMessageCommandResult && m.ID == "c3"
+		})
+		result = message.Data.(map[string]interface{})
+		if result["success"] != true || result["response"] != nil {
+			t.Errorf("Expected no response for a command without a known one, got %+v", message)
+		}
+	})
+
+	t.Run("ViewerCannotSendCommands", func(t *testing.T) {
+		viewer := dialConsole(t, newStreamAuth(t, "console-viewer", models.RoleViewer))
+		if err := viewer.WriteJSON(models.ConsoleMessage{Type: models.ConsoleMessageCommand, ID: "v1", Command: "list"}); err != nil {
+			t.Fatal(err)
+		}
+		message := readConsoleMessage(t, viewer, func(m models.ConsoleMessage) bool {
+			return m.Type == models.ConsoleMessageCommandResult && m.ID == "v1"
+		})
+		if !strings.Contains(message.Error, "requires 'moderate' permission") {
+			t.Errorf("Expected viewers to be unable to send commands, got %+v", message)
+		}
+	})
+
+	t.Run("RevokedSession", func(t *testing.T) {
+		auth := newStreamAuth(t, "console-moderator", models.RoleModerator)
+		moderator := dialConsole(t, auth)
+		if err := NewSessionService().RevokeSession(auth.UserID, auth.SessionID); err != nil {
+			t.Fatal("Failed to revoke session:", err)
+		}
+
+		if err := moderator.WriteJSON(models.ConsoleMessage{Type: models.ConsoleMessageCommand, ID: "m1", Command: "list"}); err != nil {
+			t.Fatal(err)
+		}
+		message := readConsoleMessage(t, moderator, func(m models.ConsoleMessage) bool {
+			return m.Type == models.ConsoleMessageCommandResult && m.ID == "m1"
+		})
+		if !strings.Contains(message.Error, "revoked") {
+			t.Errorf("Expected an open console to stop accepting commands once its session is revoked, got %+v", message)
+		}
+	})
+}
@@ -0,0 +1,270 @@
+package services
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"minecraft-easyserver/models"
+)
+
+// contentLogLinePattern matches content log lines such as
+// "18:43:38[Texture][error]-HD Pack | textures/blocks/stone | missing texture"
+var contentLogLinePattern = regexp.MustCompile(`^(\d{2}:\d{2}:\d{2})?\s*\[([^\]]+)\]\[(\w+)\]-\s*(.*)$`)
+
+// packReference installed pack used to link content log entries to a pack
+type packReference struct {
+	UUID       string
+	Name       string
+	FolderName string
+}
+
+// GetPackSettings gets the pack enforcement settings from server.properties
+func (r *ResourcePackService) GetPackSettings() (models.ResourcePackSettings, error) {
+	settings := models.ResourcePackSettings{}
+
+	// If no server version is active, return defaults
+	if bedrockPath == "" {
+		return settings, nil
+	}
+
+	properties, err := readServerPropertyMap(filepath.Join(bedrockPath, "server.properties"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return settings, nil
+		}
+		return settings, err
+	}
+
+	settings.TexturepackRequired = properties["texturepack-required"] == "true"
+	settings.ContentLogFileEnabled = properties["content-log-file-enabled"] == "true"
+	return settings, nil
+}
+
+// UpdatePackSettings writes the pack enforcement settings to server.properties
+func (r *ResourcePackService) UpdatePackSettings(settings models.ResourcePackSettings, user string) error {
+	// If no server version is active, return error
+	if bedrockPath == "" {
+		return fmt.Errorf("no server version is currently active. Please download and activate a server version first")
+	}
+
+	_, err := setServerProperties(filepath.Join(bedrockPath, "server.properties"), map[string]string{
+		"texturepack-required":     strconv.FormatBool(settings.TexturepackRequired),
+		"content-log-file-enabled": strconv.FormatBool(settings.ContentLogFileEnabled),
+	}, user)
+	return err
+}
+
+// GetContentLog parses bedrock's content log files and returns the most recent entries.
+// level filters by content log severity (e.g. "error", "warning"); empty returns all.
+func (r *ResourcePackService) GetContentLog(limit int, level string) ([]models.ContentLogEntry, error) {
+	entries := []models.ContentLogEntry{}
+
+	// If no server version is active, return empty list
+	if bedrockPath == "" {
+		return entries, nil
+	}
+
+	files, err := findContentLogFiles(filepath.Join(bedrockPath, "logs"))
+	if err != nil {
+		return nil, err
+	}
+
+	packs := installedPackReferences()
+	for _, file := range files {
+		fileEntries, err := parseContentLogFile(file, packs)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range fileEntries {
+			if level != "" && !strings.EqualFold(entry.Level, level) {
+				continue
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	if limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+	return entries, nil
+}
+
+// findContentLogFiles returns content log files in the logs directory, oldest first
+func findContentLogFiles(logsPath string) ([]string, error) {
+	dirEntries, err := os.ReadDir(logsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	type logFile struct {
+		path    string
+		modTime int64
+	}
+	var logFiles []logFile
+	for _, entry := range dirEntries {
+		if entry.IsDir() || !strings.HasPrefix(strings.ToLower(entry.Name()), "contentlog") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		logFiles = append(logFiles, logFile{
+			path:    filepath.Join(logsPath, entry.Name()),
+			modTime: info.ModTime().UnixNano(),
+		})
+	}
+
+	sort.Slice(logFiles, func(i, j int) bool {
+		return logFiles[i].modTime < logFiles[j].modTime
+	})
+
+	paths := make([]string, 0, len(logFiles))
+	for _, file := range logFiles {
+		paths = append(paths, file.path)
+	}
+	return paths, nil
+}
+
+// parseContentLogFile parses a content log file, linking each entry to the pack it mentions
+func parseContentLogFile(path string, packs []packReference) ([]models.ContentLogEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []models.ContentLogEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		matches := contentLogLinePattern.FindStringSubmatch(line)
+		if matches == nil {
+			// Lines without a header continue the previous entry
+			if len(entries) > 0 {
+				entries[len(entries)-1].Message += "\n" + line
+			}
+			continue
+		}
+
+		entry := models.ContentLogEntry{
+			Timestamp: matches[1],
+			Category:  matches[2],
+			Level:     strings.ToLower(matches[3]),
+			Message:   matches[4],
+			File:      filepath.Base(path),
+		}
+		if pack := matchPackReference(entry.Message, packs); pack != nil {
+			entry.PackUUID = pack.UUID
+			entry.PackName = pack.Name
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, scanner.Err()
+}
+
+// matchPackReference finds the pack a content log message refers to.
+// A UUID match wins; otherwise the longest pack or folder name appearing as a whole
+// word is used. Names found only inside other words, such as "ore" in "core", don't
+// count, leaving the message without a pack.
+func matchPackReference(message string, packs []packReference) *packReference {
+	for i := range packs {
+		if packs[i].UUID != "" && strings.Contains(message, packs[i].UUID) {
+			return &packs[i]
+		}
+	}
+
+	lowerMessage := strings.ToLower(message)
+	var best *packReference
+	bestLength := 0
+	for i := range packs {
+		for _, name := range []string{packs[i].Name, packs[i].FolderName} {
+			if len(name) > bestLength && containsWord(lowerMessage, strings.ToLower(name)) {
+				best = &packs[i]
+				bestLength = len(name)
+			}
+		}
+	}
+	return best
+}
+
+// containsWord reports whether word appears in text without a letter, digit or
+// underscore directly before or after it
+func containsWord(text, word string) bool {
+	if word == "" {
+		return false
+	}
+	for offset := 0; offset < len(text); {
+		index := strings.Index(text[offset:], word)
+		if index < 0 {
+			return false
+		}
+		start := offset + index
+		end := start + len(word)
+
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if (start == 0 || !isWordRune(before)) && (end == len(text) || !isWordRune(after)) {
+			return true
+		}
+		offset = start + 1
+	}
+	return false
+}
+
+// isWordRune reports whether r can be part of a word
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// installedPackReferences collects installed resource and behavior packs
+func installedPackReferences() []packReference {
+	var packs []packReference
+	for _, dir := range []string{"resource_packs", "behavior_packs"} {
+		packsPath := filepath.Join(bedrockPath, dir)
+		dirEntries, err := os.ReadDir(packsPath)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range dirEntries {
+			if !entry.IsDir() || isSystemResourcePack(entry.Name()) {
+				continue
+			}
+
+			data, err := os.ReadFile(filepath.Join(packsPath, entry.Name(), "manifest.json"))
+			if err != nil {
+				continue
+			}
+			var manifest models.ResourcePackManifest
+			if json.Unmarshal(data, &manifest) != nil {
+				continue
+			}
+
+			packs = append(packs, packReference{
+				UUID:       manifest.Header.UUID,
+				Name:       manifest.Header.Name,
+				FolderName: entry.Name(),
+			})
+		}
+	}
+	return packs
+}
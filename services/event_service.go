@@ -0,0 +1,193 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"minecraft-easyserver/models"
+)
+
+// Event topics
+const (
+	EventTopicLog      = "log"
+	EventTopicStatus   = "status"
+	EventTopicPlayer   = "player"
+	EventTopicDownload = "download"
+)
+
+// EventTopics all event topics, in the order they are documented
+var EventTopics = []string{EventTopicLog, EventTopicStatus, EventTopicPlayer, EventTopicDownload}
+
+// EventService numbers and fans out server events. Log entries share the ID sequence
+// with other events and are replayed from the LogService buffer; other events are
+// kept in a buffer of their own so clients can resume after a reconnect.
+type EventService struct {
+	mutex       sync.Mutex
+	lastID      int64
+	events      []models.ServerEvent
+	maxEvents   int
+	subscribers map[chan models.ServerEvent]bool
+}
+
+var eventService *EventService
+
+// NewEventService returns the global event service instance
+func NewEventService() *EventService {
+	if eventService == nil {
+		eventService = &EventService{
+			maxEvents:   1000, // Keep last 1000 non-log events
+			subscribers: make(map[chan models.ServerEvent]bool),
+		}
+	}
+	return eventService
+}
+
+// Publish publishes an event on a topic
+func (es *EventService) Publish(topic string, data interface{}) {
+	es.mutex.Lock()
+	defer es.mutex.Unlock()
+
+	es.publish(topic, time.Now().Format("2006-01-02 15:04:05"), data, true)
+}
+
+// PublishLog numbers a log entry and publishes it, along with a player event if
+// the line reports a player joining or leaving
+func (es *EventService) PublishLog(entry *models.ServerLogEntry) {
+	es.mutex.Lock()
+	defer es.mutex.Unlock()
+
+	entry.ID = es.lastID + 1
+	es.publish(EventTopicLog, entry.Timestamp, *entry, false)
+
+	if event, ok := playerEventFromLog(*entry); ok {
+		es.publish(EventTopicPlayer, entry.Timestamp, event, true)
+	}
+}
+
+// LastID returns the ID of the most recent event
+func (es *EventService) LastID() int64 {
+	es.mutex.Lock()
+	defer es.mutex.Unlock()
+	return es.lastID
+}
+
+// Subscribe returns a channel receiving new events and a function to unsubscribe.
+// A subscriber that falls behind by more than buffer events is dropped and its
+// channel closed; it can catch up again with Since.
+func (es *EventService) Subscribe(buffer int) (<-chan models.ServerEvent, func()) {
+	ch := make(chan models.ServerEvent, buffer)
+
+	es.mutex.Lock()
+	es.subscribers[ch] = true
+	es.mutex.Unlock()
+
+	return ch, func() {
+		es.mutex.Lock()
+		defer es.mutex.Unlock()
+		if es.subscribers[ch] {
+			delete(es.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Since returns buffered events with an ID greater than lastID, oldest first, and
+// the ID of the newest event covered. topics optionally limits the result to the
+// given topics. Events published later carry IDs above the returned ID.
+func (es *EventService) Since(lastID int64, topics map[string]bool) ([]models.ServerEvent, int64) {
+	wanted := func(topic string) bool {
+		return len(topics) == 0 || topics[topic]
+	}
+
+	// Read the log buffer first; AddLogEntry locks the log service before this one
+	var logs []models.ServerLogEntry
+	var upTo int64
+	if wanted(EventTopicLog) {
+		logs, upTo = NewLogService().logsSince(lastID)
+	}
+
+	es.mutex.Lock()
+	if !wanted(EventTopicLog) {
+		upTo = es.lastID
+	}
+	var others []models.ServerEvent
+	for _, event := range es.events {
+		if event.ID > lastID && event.ID <= upTo && wanted(event.Topic) {
+			others = append(others, event)
+		}
+	}
+	es.mutex.Unlock()
+
+	// Merge both buffers by ID
+	result := make([]models.ServerEvent, 0, len(logs)+len(others))
+	i, j := 0, 0
+	for i < len(logs) || j < len(others) {
+		if j >= len(others) || (i < len(logs) && logs[i].ID < others[j].ID) {
+			result = append(result, models.ServerEvent{
+				ID:        logs[i].ID,
+				Topic:     EventTopicLog,
+				Timestamp: logs[i].Timestamp,
+				Data:      logs[i],
+			})
+			i++
+		} else {
+			result = append(result, others[j])
+			j++
+		}
+	}
+	return result, upTo
+}
+
+// ParseEventTopics parses a comma-separated topic list. An empty list selects all topics.
+func ParseEventTopics(value string) (map[string]bool, error) {
+	topics := make(map[string]bool)
+	for _, topic := range strings.Split(value, ",") {
+		topic = strings.TrimSpace(topic)
+		if topic == "" {
+			continue
+		}
+
+		valid := false
+		for _, known := range EventTopics {
+			if topic == known {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, fmt.Errorf("invalid topic: %s. Valid topics are: %s", topic, strings.Join(EventTopics, ", "))
+		}
+		topics[topic] = true
+	}
+	return topics, nil
+}
+
+// publish numbers an event and delivers it to subscribers; callers must hold the mutex.
+// Log events are not buffered here because the log service keeps them.
+func (es *EventService) publish(topic, timestamp string, data interface{}, buffered bool) {
+	es.lastID++
+	event := models.ServerEvent{
+		ID:        es.lastID,
+		Topic:     topic,
+		Timestamp: timestamp,
+		Data:      data,
+	}
+
+	if buffered {
+		es.events = append(es.events, event)
+		if len(es.events) > es.maxEvents {
+			es.events = es.events[len(es.events)-es.maxEvents:]
+		}
+	}
+
+	for subscriber := range es.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+			delete(es.subscribers, subscriber)
+			close(subscriber)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"minecraft-easyserver/models"
+)
+
+func TestEventService(t *testing.T) {
+	events := NewEventService()
+	logs := NewLogService()
+
+	start := events.LastID()
+	logs.AddLogEntry("INFO", "before status")
+	events.Publish(EventTopicStatus, models.ServerStatus{Status: "running"})
+	logs.AddServerOutput(LogSourceStdout, "[2025-08-07 18:40:12:123 INFO] Player connected: Steve, xuid: 2535400000000001")
+
+	t.Run("SinceMergesLogsAndEvents", func(t *testing.T) {
+		backlog, upTo := events.Since(start, nil)
+		var topics []string
+		for i, event := range backlog {
+			topics = append(topics, event.Topic)
+			if i > 0 && event.ID <= backlog[i-1].ID {
+				t.Errorf("Expected increasing IDs, got %d after %d", event.ID, backlog[i-1].ID)
+			}
+		}
+		if strings.Join(topics, ",") != "log,status,log,player" {
+			t.Errorf("Unexpected event order: %v", topics)
+		}
+		if upTo != events.LastID() {
+			t.Errorf("Expected backlog to cover up to %d, got %d", events.LastID(), upTo)
+		}
+	})
+
+	t.Run("ResumeAndTopicFilter", func(t *testing.T) {
+		all, _ := events.Since(start, nil)
+		resumed, _ := events.Since(all[1].ID, map[string]bool{EventTopicPlayer: true})
+		if len(resumed) != 1 || resumed[0].Topic != EventTopicPlayer {
+			t.Fatalf("Expected only the player event after resuming, got %+v", resumed)
+		}
+		player := resumed[0].Data.(models.PlayerEvent)
+		if player.Event != PlayerEventConnected || player.Name != "Steve" {
+			t.Errorf("Unexpected player event: %+v", player)
+		}
+	})
+
+	t.Run("InvalidTopic", func(t *testing.T) {
+		if _, err := ParseEventTopics("log,chat"); err == nil || !strings.Contains(err.Error(), "invalid topic") {
+			t.Errorf("Expected invalid topic error, got %v", err)
+		}
+		topics, err := ParseEventTopics(" log , status ")
+		if err != nil || len(topics) != 2 || !topics[EventTopicLog] || !topics[EventTopicStatus] {
+			t.Errorf("Unexpected topics: %v, %v", topics, err)
+		}
+	})
+
+	t.Run("SlowSubscriberIsDropped", func(t *testing.T) {
+		ch, unsubscribe := events.Subscribe(1)
+		defer unsubscribe()
+
+		events.Publish(EventTopicDownload, models.DownloadProgress{Version: "1.0", Progress: 1})
+		events.Publish(EventTopicDownload, models.DownloadProgress{Version: "1.0", Progress: 2})
+
+		<-ch
+		if _, ok := <-ch; ok {
+			t.Error("Expected channel to be closed after overflowing")
+		}
+	})
+}
@@ -81,6 +81,47 @@ func (is *InteractionService) SendCommand(command string) error {
 	return nil
 }
 
+// SendCommandAndWait sends a command to the server and waits until a server output
+// line accepted by match appears. It returns the matching line.
+func (is *InteractionService) SendCommandAndWait(command string, match func(line string) bool, timeout time.Duration) (string, error) {
+	// Subscribe before sending so a fast response isn't missed
+	entries, unsubscribe := NewLogService().Subscribe(64)
+	defer unsubscribe()
+
+	if err := is.SendCommand(command); err != nil {
+		return "", err
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case entry := <-entries:
+			if match(entry.Message) {
+				return entry.Message, nil
+			}
+		case <-timer.C:
+			return "", fmt.Errorf("timed out waiting for server response to '%s'", command)
+		}
+	}
+}
+
+// QuoteCommandArgument quotes a player name or other argument for use in a console command.
+// Arguments that would break out of the quotes are rejected.
+func QuoteCommandArgument(argument string) (string, error) {
+	if argument == "" {
+		return "", fmt.Errorf("command argument cannot be empty")
+	}
+	if strings.ContainsAny(argument, "\"\\\r\n") {
+		return "", fmt.Errorf("command argument contains forbidden character: %q", argument)
+	}
+	if strings.ContainsAny(argument, " @") {
+		return "\"" + argument + "\"", nil
+	}
+	return argument, nil
+}
+
 // GetCommandHistory returns recent command history
 func (is *InteractionService) GetCommandHistory(limit int) []models.ServerCommandResponse {
 	is.mutex.RLock()
@@ -122,47 +163,14 @@ func (is *InteractionService) Close() {
 	}
 }
 
-// ValidateCommand validates if a command is safe to execute
-func (is *InteractionService) ValidateCommand(command string) error {
-	if command == "" {
-		return fmt.Errorf("command cannot be empty")
-	}
-
-	// 检查命令长度
-	if len(command) > 256 {
-		return fmt.Errorf("command too long (max 256 characters)")
-	}
-
-	// 危险命令黑名单
-	dangerousCommands := []string{
-		"stop", "restart", "shutdown", "exit", "quit",
-		"reload", "op", "deop", "ban", "ban-ip",
-		"pardon", "pardon-ip", "whitelist",
-	}
-	
-	// 检查完整命令匹配
-	for _, dangerous := range dangerousCommands {
-		if command == dangerous {
-			return fmt.Errorf("command '%s' is not allowed through web interface", command)
-		}
-	}
-	
-	// 检查命令前缀匹配（防止参数绕过）
-	for _, dangerous := range dangerousCommands {
-		if len(command) > len(dangerous) && command[:len(dangerous)+1] == dangerous+" " {
-			return fmt.Errorf("command '%s' is not allowed through web interface", dangerous)
-		}
-	}
-
-	// 检查特殊字符，防止命令注入
-	forbiddenChars := []string{";", "&", "|", "`", "$", "(", ")", "<", ">"}
-	for _, char := range forbiddenChars {
-		if strings.Contains(command, char) {
-			return fmt.Errorf("command contains forbidden character: %s", char)
-		}
+// ValidateCommand checks a command against the command policy for a role.
+// confirmed acknowledges commands matched by a confirm rule.
+func (is *InteractionService) ValidateCommand(command, role string, confirmed bool) error {
+	decision, err := NewCommandPolicyService().Evaluate(command, role)
+	if err != nil {
+		return err
 	}
-
-	return nil
+	return CommandDecisionError(decision, confirmed)
 }
 
 // StartCommandCapture starts capturing command responses from server output
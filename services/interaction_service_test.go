@@ -0,0 +1,125 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeServerStdin answers commands written to the server like bedrock's console does;
+// a response may span several lines
+type fakeServerStdin struct {
+	respond func(command string) string
+}
+
+func (f *fakeServerStdin) Write(p []byte) (int, error) {
+	command := strings.TrimSpace(string(p))
+	if response := f.respond(command); response != "" {
+		go func() {
+			for _, line := range strings.Split(response, "\n") {
+				NewLogService().AddServerOutput(LogSourceStdout, line)
+			}
+		}()
+	}
+	return len(p), nil
+}
+
+func (f *fakeServerStdin) Close() error {
+	return nil
+}
+
+func TestSendCommandAndWait(t *testing.T) {
+	interaction := GetInteractionService()
+	if !interaction.IsEnabled() {
+		t.Skip("Server interaction is not supported on this platform")
+	}
+
+	interaction.SetStdin(&fakeServerStdin{respond: func(command string) string {
+		if command == `allowlist add "Some Player"` {
+			return "[2025-08-07 18:40:12:000 INFO] Player added to allowlist"
+		}
+		return ""
+	}})
+	t.Cleanup(func() { interaction.SetStdin(nil) })
+
+	t.Run("Confirmed", func(t *testing.T) {
+		name, err := QuoteCommandArgument("Some Player")
+		if err != nil {
+			t.Fatal("Failed to quote name:", err)
+		}
+
+		response, err := interaction.SendCommandAndWait("allowlist add "+name, responseIs("Player added to allowlist"), time.Second)
+		if err != nil {
+			t.Fatal("Expected command to be confirmed:", err)
+		}
+		if !strings.Contains(response, "Player added to allowlist") {
+			t.Errorf("Unexpected response: %s", response)
+		}
+	})
+
+	t.Run("TimesOut", func(t *testing.T) {
+		_, err := interaction.SendCommandAndWait("allowlist reload", responseIs("AllowList file successfully reloaded"), 50*time.Millisecond)
+		if err == nil || !strings.Contains(err.Error(), "timed out") {
+			t.Errorf("Expected timeout error, got %v", err)
+		}
+	})
+}
+
+func TestSyncLive(t *testing.T) {
+	interaction := GetInteractionService()
+	if !interaction.IsEnabled() {
+		t.Skip("Server interaction is not supported on this platform")
+	}
+
+	interaction.SetStdin(&fakeServerStdin{respond: func(command string) string {
+		// Chat mentioning the right words comes before the real response
+		chat := "[2025-08-07 18:40:12:000 INFO] <Alex> Steve was removed, already added back\n"
+		switch command {
+		case "allowlist remove Steve":
+			return chat + "[2025-08-07 18:40:12:000 INFO] Player not in allowlist"
+		case "allowlist add Steve":
+			return chat + "[2025-08-07 18:40:12:000 INFO] Player added to allowlist"
+		}
+		return ""
+	}})
+	t.Cleanup(func() { interaction.SetStdin(nil) })
+	fakeRunningServer(t)
+
+	result := syncLive("allowlist add Steve", []string{"Player added to allowlist"})
+	if !result.Confirmed || result.Response != "Player added to allowlist" {
+		t.Errorf("Expected the add to be confirmed by bedrock's response, got %+v", result)
+	}
+
+	result = syncLive("allowlist remove Steve", []string{"Player removed from allowlist"}, "Player not in allowlist")
+	if result.Confirmed || !strings.Contains(result.Error, "Player not in allowlist") {
+		t.Errorf("Expected a failed remove to be reported, got %+v", result)
+	}
+}
+
+func TestQuoteCommandArgument(t *testing.T) {
+	tests := []struct {
+		argument string
+		expected string
+		wantErr  bool
+	}{
+		{"Steve", "Steve", false},
+		{"Some Player", `"Some Player"`, false},
+		{"@a", `"@a"`, false},
+		{`Bad"Name`, "", true},
+		{"Bad\nName", "", true},
+		{"", "", true},
+	}
+
+	for _, test := range tests {
+		quoted, err := QuoteCommandArgument(test.argument)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("Expected error for %q", test.argument)
+			}
+			continue
+		}
+		if err != nil || quoted != test.expected {
+			t.Errorf("QuoteCommandArgument(%q) = %q, %v; want %q", test.argument, quoted, err, test.expected)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"minecraft-easyserver/models"
+)
+
+// livePropertyCommands server.properties keys a running server can apply
+// through a console command, mapped to the command for a new value
+var livePropertyCommands = map[string]func(value string) string{
+	"difficulty": func(value string) string { return "difficulty " + value },
+	"gamemode":   func(value string) string { return "defaultgamemode " + value },
+	"allow-list": func(value string) string {
+		if value == "true" {
+			return "allowlist on"
+		}
+		return "allowlist off"
+	},
+}
+
+// liveFileReloadCommands server data files a running server can reload without a
+// restart, with the reload command and bedrock's response to it
+var liveFileReloadCommands = map[string]struct{ command, response string }{
+	"allowlist.json":   {"allowlist reload", "AllowList file successfully reloaded"},
+	"permissions.json": {"permission reload", "Permissions file successfully reloaded"},
+}
+
+// liveCommandTimeout how long to wait for the server to acknowledge a live command
+const liveCommandTimeout = 3 * time.Second
+
+var (
+	restartPendingMutex sync.Mutex
+	restartPendingKeys  = make(map[string]bool)
+)
+
+// serverRunning reports whether the bedrock server process is running
+func serverRunning() bool {
+	return NewServerService().GetStatus().Status == "running"
+}
+
+// sendLiveCommand sends a console command to the running server
+func sendLiveCommand(command string) error {
+	return GetInteractionService().SendCommand(command)
+}
+
+// applyPropertyChanges compares two versions of server.properties and pushes the
+// changed keys to the running server. Keys that can't be applied live are marked
+// as pending until the next restart.
+func applyPropertyChanges(before, after map[string]string) models.ConfigUpdateResult {
+	result := models.ConfigUpdateResult{
+		ChangedKeys:         []string{},
+		AppliedLive:         []string{},
+		RestartRequiredKeys: []string{},
+	}
+
+	for key, value := range after {
+		if previous, exists := before[key]; !exists || previous != value {
+			result.ChangedKeys = append(result.ChangedKeys, key)
+		}
+	}
+	for key := range before {
+		if _, exists := after[key]; !exists {
+			result.ChangedKeys = append(result.ChangedKeys, key)
+		}
+	}
+	sort.Strings(result.ChangedKeys)
+
+	// A stopped server reads the new values on its next start
+	if len(result.ChangedKeys) == 0 || !serverRunning() {
+		return result
+	}
+
+	for _, key := range result.ChangedKeys {
+		command, live := livePropertyCommands[key]
+		value, exists := after[key]
+		if live && exists && sendLiveCommand(command(value)) == nil {
+			result.AppliedLive = append(result.AppliedLive, key)
+			continue
+		}
+		result.RestartRequiredKeys = append(result.RestartRequiredKeys, key)
+	}
+
+	markRestartPending(result.RestartRequiredKeys)
+	return result
+}
+
+// reloadLiveFile asks the running server to reload a data file
+func reloadLiveFile(file string) models.LiveSyncResult {
+	reload, exists := liveFileReloadCommands[file]
+	if !exists {
+		return models.LiveSyncResult{ServerRunning: serverRunning()}
+	}
+	return syncLive(reload.command, []string{reload.response})
+}
+
+// syncLive sends a command to the running server and waits for one of bedrock's
+// responses to it: a success response confirms the change, a failure response is
+// reported as an error. A stopped server picks up the files on its next start.
+func syncLive(command string, success []string, failure ...string) models.LiveSyncResult {
+	result := models.LiveSyncResult{ServerRunning: serverRunning()}
+	if !result.ServerRunning {
+		return result
+	}
+
+	result.Command = command
+	responses := append(append([]string{}, success...), failure...)
+	response, err := GetInteractionService().SendCommandAndWait(command, responseIs(responses...), liveCommandTimeout)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Response = response
+	if responseIs(failure...)(response) {
+		result.Error = "server reported: " + response
+		return result
+	}
+	result.Confirmed = true
+	return result
+}
+
+// responseIs matches console lines that are exactly one of the given responses,
+// ignoring case, so unrelated output such as chat can't be mistaken for them
+func responseIs(responses ...string) func(line string) bool {
+	return func(line string) bool {
+		line = strings.TrimSpace(line)
+		for _, response := range responses {
+			if strings.EqualFold(line, response) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// commandResponse matches bedrock's response to command as recognized by
+// consoleCommandResponses. If mention is set, such as the target player, the response
+// must also name it, so the response to a command for someone else doesn't match.
+func commandResponse(command, mention string) func(line string) bool {
+	name, _ := splitCommand(command)
+	pattern := consoleCommandResponses[name]
+	mention = strings.ToLower(mention)
+	return func(line string) bool {
+		line = strings.TrimSpace(line)
+		if pattern == nil || !pattern.MatchString(line) {
+			return false
+		}
+		return strings.Contains(strings.ToLower(line), mention)
+	}
+}
+
+// markRestartPending records keys that only take effect after a restart
+func markRestartPending(keys []string) {
+	restartPendingMutex.Lock()
+	defer restartPendingMutex.Unlock()
+
+	for _, key := range keys {
+		restartPendingKeys[key] = true
+	}
+}
+
+// clearRestartPending forgets pending keys once the server has been (re)started
+func clearRestartPending() {
+	restartPendingMutex.Lock()
+	defer restartPendingMutex.Unlock()
+
+	restartPendingKeys = make(map[string]bool)
+}
+
+// getRestartPendingKeys returns the keys waiting for a restart, sorted
+func getRestartPendingKeys() []string {
+	restartPendingMutex.Lock()
+	defer restartPendingMutex.Unlock()
+
+	keys := make([]string, 0, len(restartPendingKeys))
+	for key := range restartPendingKeys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
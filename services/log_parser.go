@@ -0,0 +1,144 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"minecraft-easyserver/models"
+)
+
+// Log entry sources
+const (
+	LogSourceStdout = "stdout"
+	LogSourceStderr = "stderr"
+	LogSourcePanel  = "panel"
+)
+
+// Log entry categories
+const (
+	LogCategoryStartup  = "startup"
+	LogCategoryShutdown = "shutdown"
+	LogCategoryPlayer   = "player"
+	LogCategoryPack     = "pack"
+)
+
+// Log entry events
+const (
+	LogEventServerStarting     = "server_starting"
+	LogEventServerVersion      = "server_version"
+	LogEventLevelOpening       = "level_opening"
+	LogEventListening          = "listening"
+	LogEventServerStarted      = "server_started"
+	LogEventStopRequested      = "stop_requested"
+	LogEventServerStopping     = "server_stopping"
+	LogEventServerStopped      = "server_stopped"
+	LogEventPlayerConnected    = "player_connected"
+	LogEventPlayerSpawned      = "player_spawned"
+	LogEventPlayerDisconnected = "player_disconnected"
+	LogEventPackError          = "pack_error"
+)
+
+// bedrockLinePattern matches bedrock's line prefix, e.g. "[2025-08-07 18:43:38:123 INFO] Server started."
+var bedrockLinePattern = regexp.MustCompile(`^\[(\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2})(?::\d+)? ([A-Za-z]+)\]\s?(.*)$`)
+
+var (
+	// playerConnectedPattern matches "Player connected: Steve, xuid: 2535400000000001"
+	playerConnectedPattern = regexp.MustCompile(`^Player connected:\s*(.+?),\s*xuid:\s*(\d*)`)
+
+	// playerSpawnedPattern matches "Player Spawned: Steve xuid: 2535400000000001, pfid: ..."
+	playerSpawnedPattern = regexp.MustCompile(`^Player Spawned:\s*(.+?),?\s+xuid:\s*(\d*)`)
+
+	// playerDisconnectedPattern matches "Player disconnected: Steve, xuid: 2535400000000001, pfid: ..."
+	playerDisconnectedPattern = regexp.MustCompile(`^Player disconnected:\s*(.+?),\s*xuid:\s*(\d*)`)
+
+	// packMessagePattern matches content log lines about resource or behavior packs
+	packMessagePattern = regexp.MustCompile(`(?i)\bpack`)
+)
+
+// logPhases startup and shutdown lines recognised by their message prefix
+var logPhases = []struct {
+	prefix   string
+	category string
+	event    string
+}{
+	{"Starting Server", LogCategoryStartup, LogEventServerStarting},
+	{"Version:", LogCategoryStartup, LogEventServerVersion},
+	{"Opening level", LogCategoryStartup, LogEventLevelOpening},
+	{"IPv4 supported", LogCategoryStartup, LogEventListening},
+	{"IPv6 supported", LogCategoryStartup, LogEventListening},
+	{"Server started", LogCategoryStartup, LogEventServerStarted},
+	{"Server stop requested", LogCategoryShutdown, LogEventStopRequested},
+	{"Stopping server", LogCategoryShutdown, LogEventServerStopping},
+	{"Quit correctly", LogCategoryShutdown, LogEventServerStopped},
+}
+
+// ParseLogLine parses a line of bedrock output. Lines with bedrock's own prefix take
+// their timestamp and level from it; other lines are INFO on stdout and ERROR on stderr.
+func ParseLogLine(line, source string) models.ServerLogEntry {
+	entry := models.ServerLogEntry{
+		Timestamp: time.Now().Format("2006-01-02 15:04:05"),
+		Level:     "INFO",
+		Message:   line,
+		Source:    source,
+	}
+	if source == LogSourceStderr {
+		entry.Level = "ERROR"
+	}
+
+	if matches := bedrockLinePattern.FindStringSubmatch(line); matches != nil {
+		entry.Timestamp = matches[1]
+		entry.Level = strings.ToUpper(matches[2])
+		entry.Message = matches[3]
+	}
+
+	classifyLogEntry(&entry)
+	return entry
+}
+
+// classifyLogEntry sets the category, event and player fields of an entry from its message
+func classifyLogEntry(entry *models.ServerLogEntry) {
+	message := strings.TrimSpace(entry.Message)
+
+	players := []struct {
+		pattern *regexp.Regexp
+		event   string
+	}{
+		{playerConnectedPattern, LogEventPlayerConnected},
+		{playerSpawnedPattern, LogEventPlayerSpawned},
+		{playerDisconnectedPattern, LogEventPlayerDisconnected},
+	}
+	for _, player := range players {
+		if matches := player.pattern.FindStringSubmatch(message); matches != nil {
+			entry.Category = LogCategoryPlayer
+			entry.Event = player.event
+			entry.Player = strings.TrimSpace(matches[1])
+			entry.Xuid = matches[2]
+			return
+		}
+	}
+
+	for _, phase := range logPhases {
+		if strings.HasPrefix(message, phase.prefix) {
+			entry.Category = phase.category
+			entry.Event = phase.event
+			return
+		}
+	}
+
+	if (entry.Level == "WARN" || entry.Level == "ERROR") && packMessagePattern.MatchString(message) {
+		entry.Category = LogCategoryPack
+		entry.Event = LogEventPackError
+	}
+}
+
+// playerEventFromLog returns the join or leave event reported by a log entry
+func playerEventFromLog(entry models.ServerLogEntry) (models.PlayerEvent, bool) {
+	switch entry.Event {
+	case LogEventPlayerConnected:
+		return models.PlayerEvent{Event: PlayerEventConnected, Name: entry.Player, Xuid: entry.Xuid}, true
+	case LogEventPlayerDisconnected:
+		return models.PlayerEvent{Event: PlayerEventDisconnected, Name: entry.Player, Xuid: entry.Xuid}, true
+	}
+	return models.PlayerEvent{}, false
+}
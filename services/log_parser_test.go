@@ -0,0 +1,96 @@
+package services
+
+import (
+	"testing"
+
+	"minecraft-easyserver/models"
+)
+
+func TestParseLogLine(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		source string
+		want   models.ServerLogEntry
+	}{
+		{
+			name:   "BedrockPrefix",
+			line:   "[2025-08-07 18:43:38:123 INFO] Server started.",
+			source: LogSourceStdout,
+			want: models.ServerLogEntry{
+				Timestamp: "2025-08-07 18:43:38", Level: "INFO", Message: "Server started.",
+				Category: LogCategoryStartup, Event: LogEventServerStarted,
+			},
+		},
+		{
+			name:   "WarningOnStdout",
+			line:   "[2025-08-07 18:43:39:001 WARN] Something looks off",
+			source: LogSourceStdout,
+			want:   models.ServerLogEntry{Timestamp: "2025-08-07 18:43:39", Level: "WARN", Message: "Something looks off"},
+		},
+		{
+			name:   "InfoOnStderr",
+			line:   "[2025-08-07 18:43:38:123 INFO] Version: 1.21.2.02",
+			source: LogSourceStderr,
+			want: models.ServerLogEntry{
+				Timestamp: "2025-08-07 18:43:38", Level: "INFO", Message: "Version: 1.21.2.02",
+				Category: LogCategoryStartup, Event: LogEventServerVersion,
+			},
+		},
+		{
+			name:   "PlayerConnected",
+			line:   "[2025-08-07 18:40:12:123 INFO] Player connected: Some Player, xuid: 2535400000000001",
+			source: LogSourceStdout,
+			want: models.ServerLogEntry{
+				Timestamp: "2025-08-07 18:40:12", Level: "INFO", Message: "Player connected: Some Player, xuid: 2535400000000001",
+				Category: LogCategoryPlayer, Event: LogEventPlayerConnected, Player: "Some Player", Xuid: "2535400000000001",
+			},
+		},
+		{
+			name:   "PlayerSpawned",
+			line:   "[2025-08-07 18:40:15:000 INFO] Player Spawned: Steve xuid: 2535400000000001, pfid: abc",
+			source: LogSourceStdout,
+			want: models.ServerLogEntry{
+				Timestamp: "2025-08-07 18:40:15", Level: "INFO", Message: "Player Spawned: Steve xuid: 2535400000000001, pfid: abc",
+				Category: LogCategoryPlayer, Event: LogEventPlayerSpawned, Player: "Steve", Xuid: "2535400000000001",
+			},
+		},
+		{
+			name:   "PackError",
+			line:   "[2025-08-07 18:43:38:500 ERROR] [Pack] Error opening pack manifest",
+			source: LogSourceStdout,
+			want: models.ServerLogEntry{
+				Timestamp: "2025-08-07 18:43:38", Level: "ERROR", Message: "[Pack] Error opening pack manifest",
+				Category: LogCategoryPack, Event: LogEventPackError,
+			},
+		},
+		{
+			name:   "Shutdown",
+			line:   "[2025-08-07 19:00:00:000 INFO] Quit correctly",
+			source: LogSourceStdout,
+			want: models.ServerLogEntry{
+				Timestamp: "2025-08-07 19:00:00", Level: "INFO", Message: "Quit correctly",
+				Category: LogCategoryShutdown, Event: LogEventServerStopped,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseLogLine(tt.line, tt.source)
+			tt.want.Source = tt.source
+			if got != tt.want {
+				t.Errorf("ParseLogLine(%q)\n got: %+v\nwant: %+v", tt.line, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("UnprefixedLinesUseSource", func(t *testing.T) {
+		if entry := ParseLogLine("NO LOG FILE! - setting up server logging...", LogSourceStdout); entry.Level != "INFO" || entry.Timestamp == "" {
+			t.Errorf("Expected stdout line to be INFO, got %+v", entry)
+		}
+		if entry := ParseLogLine("Segmentation fault", LogSourceStderr); entry.Level != "ERROR" {
+			t.Errorf("Expected stderr line to be ERROR, got %+v", entry)
+		}
+	})
+}
@@ -0,0 +1,264 @@
+package services
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"minecraft-easyserver/models"
+)
+
+const (
+	// defaultLogSearchLimit entries returned per page when no limit is given
+	defaultLogSearchLimit = 100
+	// maxLogSearchLimit largest page size
+	maxLogSearchLimit = 1000
+)
+
+// logTimeFormats accepted formats for the since and until filters
+var logTimeFormats = []string{"2006-01-02 15:04:05", time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"}
+
+// sessionLinePattern matches lines written by the session log service
+var sessionLinePattern = regexp.MustCompile(`^\[(\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2})\] \[([A-Za-z]+)\] (.*)$`)
+
+// LogMatcher is a compiled log filter. A nil matcher matches every entry.
+type LogMatcher struct {
+	levels  map[string]bool
+	since   time.Time
+	until   time.Time
+	query   string
+	pattern *regexp.Regexp
+	player  string
+}
+
+// CompileLogFilter validates a log filter and prepares it for matching
+func CompileLogFilter(filter models.LogFilter) (*LogMatcher, error) {
+	matcher := &LogMatcher{
+		query:  strings.ToLower(filter.Query),
+		player: strings.ToLower(strings.TrimSpace(filter.Player)),
+	}
+
+	if len(filter.Levels) > 0 {
+		matcher.levels = make(map[string]bool)
+		for _, level := range filter.Levels {
+			if level = strings.ToUpper(strings.TrimSpace(level)); level != "" {
+				matcher.levels[level] = true
+			}
+		}
+	}
+
+	var err error
+	if filter.Since != "" {
+		if matcher.since, err = parseLogTime(filter.Since); err != nil {
+			return nil, fmt.Errorf("invalid since: %v", err)
+		}
+	}
+	if filter.Until != "" {
+		if matcher.until, err = parseLogTime(filter.Until); err != nil {
+			return nil, fmt.Errorf("invalid until: %v", err)
+		}
+	}
+
+	if filter.Regex && filter.Query != "" {
+		if matcher.pattern, err = regexp.Compile(filter.Query); err != nil {
+			return nil, fmt.Errorf("invalid regular expression: %v", err)
+		}
+	}
+
+	return matcher, nil
+}
+
+// Match reports whether an entry passes the filter
+func (m *LogMatcher) Match(entry models.ServerLogEntry) bool {
+	if m == nil {
+		return true
+	}
+
+	if m.levels != nil && !m.levels[strings.ToUpper(entry.Level)] {
+		return false
+	}
+
+	if !m.since.IsZero() || !m.until.IsZero() {
+		timestamp, err := time.ParseInLocation("2006-01-02 15:04:05", entry.Timestamp, time.Local)
+		if err != nil {
+			return false
+		}
+		if !m.since.IsZero() && timestamp.Before(m.since) {
+			return false
+		}
+		if !m.until.IsZero() && timestamp.After(m.until) {
+			return false
+		}
+	}
+
+	if m.player != "" && strings.ToLower(entry.Player) != m.player &&
+		!strings.Contains(strings.ToLower(entry.Message), m.player) {
+		return false
+	}
+
+	if m.pattern != nil {
+		return m.pattern.MatchString(entry.Message)
+	}
+	if m.query != "" {
+		return strings.Contains(strings.ToLower(entry.Message), m.query)
+	}
+	return true
+}
+
+// Search returns one page of log entries matching filter, oldest first. Without a
+// session it searches the in-memory buffer, otherwise that session's log files.
+// cursor is the next_cursor of the previous page and continues with older entries.
+func (ls *LogService) Search(filter models.LogFilter, cursor string, limit int) (models.LogSearchResult, error) {
+	matcher, err := CompileLogFilter(filter)
+	if err != nil {
+		return models.LogSearchResult{}, err
+	}
+
+	if limit <= 0 {
+		limit = defaultLogSearchLimit
+	}
+	if limit > maxLogSearchLimit {
+		limit = maxLogSearchLimit
+	}
+
+	var before int64
+	if cursor != "" {
+		before, err = strconv.ParseInt(cursor, 10, 64)
+		if err != nil || before < 0 {
+			return models.LogSearchResult{}, fmt.Errorf("invalid cursor: %s", cursor)
+		}
+	}
+
+	if filter.Session != "" {
+		return NewSessionLogService().search(filter.Session, matcher, before, limit)
+	}
+	return ls.searchMemory(matcher, before, limit), nil
+}
+
+// searchMemory searches the in-memory buffer; the cursor is the ID of the oldest
+// entry returned so far
+func (ls *LogService) searchMemory(matcher *LogMatcher, before int64, limit int) models.LogSearchResult {
+	ls.mutex.RLock()
+	defer ls.mutex.RUnlock()
+	return ls.matchingLogs(matcher, before, limit)
+}
+
+// matchingLogs is searchMemory for callers already holding the log mutex
+func (ls *LogService) matchingLogs(matcher *LogMatcher, before int64, limit int) models.LogSearchResult {
+	result := models.LogSearchResult{Logs: []models.ServerLogEntry{}}
+	for i := len(ls.logEntries) - 1; i >= 0; i-- {
+		entry := ls.logEntries[i]
+		if before > 0 && entry.ID >= before {
+			continue
+		}
+		if !matcher.Match(entry) {
+			continue
+		}
+		if len(result.Logs) == limit {
+			// There is at least one more page
+			result.NextCursor = strconv.FormatInt(result.Logs[len(result.Logs)-1].ID, 10)
+			break
+		}
+		result.Logs = append(result.Logs, entry)
+	}
+
+	reverseLogEntries(result.Logs)
+	result.Count = len(result.Logs)
+	return result
+}
+
+// search searches a session's log files; the cursor is the line number of the
+// oldest entry returned so far
+func (s *SessionLogService) search(id string, matcher *LogMatcher, before int64, limit int) (models.LogSearchResult, error) {
+	files, err := s.openSessionFiles(id)
+	if err != nil {
+		return models.LogSearchResult{}, err
+	}
+	defer closeFiles(files)
+
+	// Keep the last limit matches before the cursor, and their line numbers
+	var page []models.ServerLogEntry
+	var lines []int64
+	matched := 0
+	var line int64
+
+	for _, file := range files {
+		reader, err := logFileReader(file)
+		if err != nil {
+			return models.LogSearchResult{}, err
+		}
+
+		scanner := bufio.NewScanner(reader)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			line++
+			if before > 0 && line >= before {
+				break
+			}
+
+			entry := parseSessionLine(scanner.Text())
+			if !matcher.Match(entry) {
+				continue
+			}
+
+			matched++
+			page = append(page, entry)
+			lines = append(lines, line)
+			if len(page) > limit {
+				page = page[1:]
+				lines = lines[1:]
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return models.LogSearchResult{}, fmt.Errorf("failed to read session %s: %v", id, err)
+		}
+		if before > 0 && line >= before {
+			break
+		}
+	}
+
+	result := models.LogSearchResult{Logs: page, Count: len(page)}
+	if result.Logs == nil {
+		result.Logs = []models.ServerLogEntry{}
+	}
+	if matched > len(page) {
+		result.NextCursor = strconv.FormatInt(lines[0], 10)
+	}
+	return result, nil
+}
+
+// parseSessionLine parses a line written by the session log service
+func parseSessionLine(line string) models.ServerLogEntry {
+	matches := sessionLinePattern.FindStringSubmatch(line)
+	if matches == nil {
+		return models.ServerLogEntry{Message: line}
+	}
+
+	entry := models.ServerLogEntry{
+		Timestamp: matches[1],
+		Level:     matches[2],
+		Message:   matches[3],
+	}
+	classifyLogEntry(&entry)
+	return entry
+}
+
+// parseLogTime parses a since or until filter value in local time
+func parseLogTime(value string) (time.Time, error) {
+	for _, format := range logTimeFormats {
+		if t, err := time.ParseInLocation(format, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognised time %q, use YYYY-MM-DD HH:MM:SS or RFC 3339", value)
+}
+
+// reverseLogEntries reverses entries in place
+func reverseLogEntries(entries []models.ServerLogEntry) {
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"minecraft-easyserver/models"
+)
+
+func TestLogSearch(t *testing.T) {
+	logs := NewLogService()
+	logs.ClearLogs()
+	t.Cleanup(logs.ClearLogs)
+
+	for i := 0; i < 10; i++ {
+		logs.AddServerOutput(LogSourceStdout, fmt.Sprintf("[2025-08-07 18:40:%02d:000 INFO] Line %d", i, i))
+	}
+	logs.AddServerOutput(LogSourceStdout, "[2025-08-07 18:41:00:000 WARN] Disk almost full")
+	logs.AddServerOutput(LogSourceStdout, "[2025-08-07 18:41:01:000 INFO] Player connected: Steve, xuid: 2535400000000001")
+	logs.AddServerOutput(LogSourceStdout, "[2025-08-07 18:41:02:000 INFO] Steve was slain by Zombie")
+
+	t.Run("PagesWithCursor", func(t *testing.T) {
+		filter := models.LogFilter{Query: "line "}
+		var messages []string
+		cursor := ""
+		for page := 0; page < 5; page++ {
+			result, err := logs.Search(filter, cursor, 4)
+			if err != nil {
+				t.Fatal("Search failed:", err)
+			}
+			// Pages are read newest first, each page oldest first
+			var pageMessages []string
+			for _, entry := range result.Logs {
+				pageMessages = append(pageMessages, entry.Message)
+			}
+			messages = append(pageMessages, messages...)
+			if result.NextCursor == "" {
+				break
+			}
+			cursor = result.NextCursor
+		}
+
+		if len(messages) != 10 {
+			t.Fatalf("Expected 10 lines across pages, got %v", messages)
+		}
+		for i, message := range messages {
+			if message != fmt.Sprintf("Line %d", i) {
+				t.Errorf("Expected line %d, got %s", i, message)
+			}
+		}
+	})
+
+	t.Run("FiltersByLevel", func(t *testing.T) {
+		result, err := logs.Search(models.LogFilter{Levels: []string{"warn", "error"}}, "", 0)
+		if err != nil || result.Count != 1 || result.Logs[0].Message != "Disk almost full" {
+			t.Errorf("Expected only the warning, got %+v, %v", result, err)
+		}
+	})
+
+	t.Run("FiltersByTimeRange", func(t *testing.T) {
+		filter := models.LogFilter{Since: "2025-08-07 18:40:03", Until: "2025-08-07T18:40:05"}
+		result, err := logs.Search(filter, "", 0)
+		if err != nil || result.Count != 3 {
+			t.Errorf("Expected 3 entries in range, got %+v, %v", result, err)
+		}
+	})
+
+	t.Run("FiltersByRegexAndPlayer", func(t *testing.T) {
+		result, err := logs.Search(models.LogFilter{Query: `^Line [3-5]$`, Regex: true}, "", 0)
+		if err != nil || result.Count != 3 {
+			t.Errorf("Expected 3 regex matches, got %+v, %v", result, err)
+		}
+
+		result, err = logs.Search(models.LogFilter{Player: "steve"}, "", 0)
+		if err != nil || result.Count != 2 {
+			t.Errorf("Expected both of Steve's lines, got %+v, %v", result, err)
+		}
+	})
+
+	t.Run("RejectsInvalidFilters", func(t *testing.T) {
+		invalid := []struct {
+			filter models.LogFilter
+			cursor string
+		}{
+			{models.LogFilter{Query: "(", Regex: true}, ""},
+			{models.LogFilter{Since: "yesterday"}, ""},
+			{models.LogFilter{}, "abc"},
+			{models.LogFilter{Session: "../etc"}, ""},
+		}
+		for _, test := range invalid {
+			if _, err := logs.Search(test.filter, test.cursor, 0); err == nil || !strings.Contains(err.Error(), "invalid") {
+				t.Errorf("Expected invalid error for %+v %q, got %v", test.filter, test.cursor, err)
+			}
+		}
+	})
+}
+
+func TestSessionLogSearch(t *testing.T) {
+	sessions := NewSessionLogService()
+	sessions.Configure(SessionLogOptions{
+		Enabled:  true,
+		Dir:      t.TempDir(),
+		MaxSize:  300,
+		MaxAge:   time.Hour,
+		Compress: true,
+	})
+	t.Cleanup(func() {
+		sessions.EndSession()
+		sessions.Configure(SessionLogOptions{})
+	})
+
+	if err := sessions.StartSession(); err != nil {
+		t.Fatal("Failed to start session:", err)
+	}
+	for i := 0; i < 20; i++ {
+		level := "INFO"
+		if i%5 == 0 {
+			level = "ERROR"
+		}
+		sessions.Write(models.ServerLogEntry{Timestamp: "2025-08-07 18:40:12", Level: level, Message: fmt.Sprintf("Session line %d", i)})
+	}
+
+	list, err := sessions.ListSessions()
+	if err != nil || len(list) != 1 {
+		t.Fatalf("Expected one session, got %+v, %v", list, err)
+	}
+	filter := models.LogFilter{Session: list[0].ID}
+
+	t.Run("PagesAcrossRotatedFiles", func(t *testing.T) {
+		var messages []string
+		cursor := ""
+		for page := 0; page < 10; page++ {
+			result, err := NewLogService().Search(filter, cursor, 6)
+			if err != nil {
+				t.Fatal("Search failed:", err)
+			}
+			var pageMessages []string
+			for _, entry := range result.Logs {
+				pageMessages = append(pageMessages, entry.Message)
+			}
+			messages = append(pageMessages, messages...)
+			if result.NextCursor == "" {
+				break
+			}
+			cursor = result.NextCursor
+		}
+
+		if len(messages) != 20 {
+			t.Fatalf("Expected 20 lines across pages, got %v", messages)
+		}
+		for i, message := range messages {
+			if message != fmt.Sprintf("Session line %d", i) {
+				t.Errorf("Expected line %d, got %s", i, message)
+			}
+		}
+	})
+
+	t.Run("FiltersSessionLines", func(t *testing.T) {
+		filter := filter
+		filter.Levels = []string{"ERROR"}
+		result, err := NewLogService().Search(filter, "", 0)
+		if err != nil || result.Count != 4 || result.Logs[0].Message != "Session line 0" {
+			t.Errorf("Expected 4 errors, got %+v, %v", result, err)
+		}
+	})
+
+	t.Run("MissingSession", func(t *testing.T) {
+		_, err := NewLogService().Search(models.LogFilter{Session: "20000101-000000"}, "", 0)
+		if err == nil || !strings.Contains(err.Error(), "not found") {
+			t.Errorf("Expected not found error, got %v", err)
+		}
+	})
+}
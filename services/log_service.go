@@ -5,8 +5,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
+	"sort"
 	"sync"
 	"time"
 
@@ -14,15 +15,26 @@ import (
 	"github.com/gorilla/websocket"
 )
 
+// logHistorySize log entries sent to a client when it connects
+const logHistorySize = 100
+
 type LogService struct {
 	logEntries []models.ServerLogEntry
 	mutex      sync.RWMutex
-	clients    map[*websocket.Conn]bool
+	clients    map[*logClient]bool
 	clientsMux sync.RWMutex
 	upgrader   websocket.Upgrader
 	maxLogs    int
+	queueSize  int
 	stopChan   chan bool
 	capturing  bool
+
+	// listeners receive every new log entry, e.g. to wait for a command response
+	listeners    map[chan models.ServerLogEntry]bool
+	listenersMux sync.Mutex
+
+	// hooks are called for every new log entry and never miss one, unlike listeners
+	hooks []func(models.ServerLogEntry)
 }
 
 var logService *LogService
@@ -31,31 +43,50 @@ func NewLogService() *LogService {
 	if logService == nil {
 		logService = &LogService{
 			logEntries: make([]models.ServerLogEntry, 0),
-			clients:    make(map[*websocket.Conn]bool),
+			clients:    make(map[*logClient]bool),
 			upgrader: websocket.Upgrader{
 				CheckOrigin: func(r *http.Request) bool {
 					return true // Allow all origins for development
 				},
 			},
 			maxLogs: 1000, // Keep last 1000 log entries
+			queueSize: 256, // Messages buffered per WebSocket client before it is disconnected
 			stopChan: make(chan bool),
 			capturing: false,
+			listeners: make(map[chan models.ServerLogEntry]bool),
 		}
 	}
 	return logService
 }
 
-// AddLogEntry adds a new log entry
-func (ls *LogService) AddLogEntry(level, message string) {
-	ls.mutex.Lock()
-	defer ls.mutex.Unlock()
+// logClient a connected log WebSocket and the entries it wants
+type logClient struct {
+	*wsClient
+	matcher *LogMatcher
+}
 
-	entry := models.ServerLogEntry{
+// AddLogEntry adds a log entry written by the panel itself
+func (ls *LogService) AddLogEntry(level, message string) {
+	ls.addEntry(models.ServerLogEntry{
 		Timestamp: time.Now().Format("2006-01-02 15:04:05"),
 		Level:     level,
 		Message:   message,
-	}
+		Source:    LogSourcePanel,
+	})
+}
 
+// AddServerOutput parses and adds a line of bedrock output read from source
+func (ls *LogService) AddServerOutput(source, line string) {
+	ls.addEntry(ParseLogLine(line, source))
+}
+
+// addEntry numbers, stores and broadcasts a log entry
+func (ls *LogService) addEntry(entry models.ServerLogEntry) {
+	ls.mutex.Lock()
+	defer ls.mutex.Unlock()
+
+	NewEventService().PublishLog(&entry)
+	NewSessionLogService().Write(entry)
 	ls.logEntries = append(ls.logEntries, entry)
 
 	// Keep only the last maxLogs entries
@@ -65,6 +96,59 @@ func (ls *LogService) AddLogEntry(level, message string) {
 
 	// Broadcast to all connected clients
 	ls.broadcastLogEntry(entry)
+	ls.notifyListeners(entry)
+	ls.runHooks(entry)
+}
+
+// AddHook registers a function called with every new log entry. Hooks run while
+// the entry is being logged, so they must return quickly and must not log.
+func (ls *LogService) AddHook(hook func(models.ServerLogEntry)) {
+	ls.listenersMux.Lock()
+	defer ls.listenersMux.Unlock()
+	ls.hooks = append(ls.hooks, hook)
+}
+
+// runHooks calls every registered hook with a log entry
+func (ls *LogService) runHooks(entry models.ServerLogEntry) {
+	ls.listenersMux.Lock()
+	hooks := ls.hooks
+	ls.listenersMux.Unlock()
+
+	for _, hook := range hooks {
+		hook(entry)
+	}
+}
+
+// Subscribe returns a channel receiving new log entries and a function to unsubscribe.
+// Entries are dropped for a listener whose buffer is full.
+func (ls *LogService) Subscribe(buffer int) (<-chan models.ServerLogEntry, func()) {
+	ch := make(chan models.ServerLogEntry, buffer)
+
+	ls.listenersMux.Lock()
+	ls.listeners[ch] = true
+	ls.listenersMux.Unlock()
+
+	return ch, func() {
+		ls.listenersMux.Lock()
+		defer ls.listenersMux.Unlock()
+		if ls.listeners[ch] {
+			delete(ls.listeners, ch)
+			close(ch)
+		}
+	}
+}
+
+// notifyListeners passes a log entry to all subscribed listeners without blocking
+func (ls *LogService) notifyListeners(entry models.ServerLogEntry) {
+	ls.listenersMux.Lock()
+	defer ls.listenersMux.Unlock()
+
+	for listener := range ls.listeners {
+		select {
+		case listener <- entry:
+		default:
+		}
+	}
 }
 
 // GetLogs returns recent log entries
@@ -72,12 +156,30 @@ func (ls *LogService) GetLogs(limit int) []models.ServerLogEntry {
 	ls.mutex.RLock()
 	defer ls.mutex.RUnlock()
 
-	if limit <= 0 || limit > len(ls.logEntries) {
-		return ls.logEntries
+	start := 0
+	if limit > 0 && limit < len(ls.logEntries) {
+		start = len(ls.logEntries) - limit
 	}
 
-	start := len(ls.logEntries) - limit
-	return ls.logEntries[start:]
+	// Copy so callers don't share the buffer that addEntry appends to
+	logs := make([]models.ServerLogEntry, len(ls.logEntries)-start)
+	copy(logs, ls.logEntries[start:])
+	return logs
+}
+
+// logsSince returns the buffered entries with an ID greater than lastID, along with
+// the latest event ID at that point. Every log entry up to that ID is included.
+func (ls *LogService) logsSince(lastID int64) ([]models.ServerLogEntry, int64) {
+	ls.mutex.RLock()
+	defer ls.mutex.RUnlock()
+
+	// Entries are numbered in order, so everything after the first match is newer
+	start := sort.Search(len(ls.logEntries), func(i int) bool {
+		return ls.logEntries[i].ID > lastID
+	})
+	entries := make([]models.ServerLogEntry, len(ls.logEntries)-start)
+	copy(entries, ls.logEntries[start:])
+	return entries, NewEventService().LastID()
 }
 
 // ClearLogs clears all log entries
@@ -87,66 +189,69 @@ func (ls *LogService) ClearLogs() {
 	ls.logEntries = make([]models.ServerLogEntry, 0)
 }
 
-// HandleWebSocket handles WebSocket connections for real-time logs
-func (ls *LogService) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
+// HandleWebSocket handles WebSocket connections for real-time logs for the user auth
+// identifies, until their session is revoked or they lose access.
+// Only entries passing matcher are sent; nil sends everything.
+func (ls *LogService) HandleWebSocket(w http.ResponseWriter, r *http.Request, auth StreamAuth, matcher *LogMatcher) {
 	conn, err := ls.upgrader.Upgrade(w, r, nil)
 	if err != nil {
-		log.Printf("WebSocket upgrade failed: %v", err)
+		slog.Warn("Log WebSocket upgrade failed", "error", err)
 		return
 	}
-	defer conn.Close()
 
-	log.Printf("WebSocket connection established from %s", r.RemoteAddr)
+	slog.Debug("Log WebSocket connected", "remote_addr", r.RemoteAddr)
+
+	client := &logClient{
+		// Room for the history on top of the live queue, so it can't overflow it
+		wsClient: newWSClient("Log", conn, logHistorySize+ls.queueSize),
+		matcher:  matcher,
+	}
+	defer client.close()
 
-	// Add client
+	go auth.Watch(models.PermissionView, client.done, client.close)
+
+	// Register and queue the recent logs under the log lock, so every entry is
+	// sent exactly once: either as history or as a broadcast
+	ls.mutex.RLock()
 	ls.clientsMux.Lock()
-	ls.clients[conn] = true
+	ls.clients[client] = true
 	ls.clientsMux.Unlock()
-
-	// Send recent logs to new client
-	ls.sendRecentLogs(conn)
+	for _, entry := range ls.matchingLogs(matcher, 0, logHistorySize).Logs {
+		if data, err := json.Marshal(entry); err == nil {
+			client.enqueue(data)
+		}
+	}
+	ls.mutex.RUnlock()
 
 	// Remove client when connection closes
 	defer func() {
 		ls.clientsMux.Lock()
-		delete(ls.clients, conn)
+		delete(ls.clients, client)
 		ls.clientsMux.Unlock()
 	}()
 
-	// Keep connection alive
-	for {
-		_, _, err := conn.ReadMessage()
-		if err != nil {
-			break
-		}
-	}
+	// Clients don't send anything meaningful
+	client.readPump(nil)
 }
 
-// sendRecentLogs sends recent log entries to a specific client
-func (ls *LogService) sendRecentLogs(conn *websocket.Conn) {
-	logs := ls.GetLogs(100) // Send last 100 logs
-	for _, entry := range logs {
-		data, _ := json.Marshal(entry)
-		conn.WriteMessage(websocket.TextMessage, data)
-	}
-}
-
-// broadcastLogEntry broadcasts a log entry to all connected clients
+// broadcastLogEntry queues a log entry for all connected clients; callers must hold
+// the log mutex so entries are queued in order
 func (ls *LogService) broadcastLogEntry(entry models.ServerLogEntry) {
 	ls.clientsMux.RLock()
 	defer ls.clientsMux.RUnlock()
 
+	if len(ls.clients) == 0 {
+		return
+	}
+
 	data, err := json.Marshal(entry)
 	if err != nil {
 		return
 	}
 
 	for client := range ls.clients {
-		err := client.WriteMessage(websocket.TextMessage, data)
-		if err != nil {
-			// Remove disconnected client
-			delete(ls.clients, client)
-			client.Close()
+		if client.matcher.Match(entry) {
+			client.enqueue(data)
 		}
 	}
 }
@@ -181,15 +286,15 @@ func (ls *LogService) StartLogCapture(stdout, stderr io.ReadCloser) {
 	ls.capturing = true
 	
 	if stdout != nil {
-		go ls.captureOutput(stdout, "INFO")
+		go ls.captureOutput(stdout, LogSourceStdout)
 	}
 	if stderr != nil {
-		go ls.captureOutput(stderr, "ERROR")
+		go ls.captureOutput(stderr, LogSourceStderr)
 	}
 }
 
 // captureOutput captures output from a reader and adds to logs
-func (ls *LogService) captureOutput(reader io.ReadCloser, level string) {
+func (ls *LogService) captureOutput(reader io.ReadCloser, source string) {
 	defer reader.Close()
 	scanner := bufio.NewScanner(reader)
 
@@ -203,7 +308,7 @@ func (ls *LogService) captureOutput(reader io.ReadCloser, level string) {
 		
 		line := scanner.Text()
 		if line != "" {
-			ls.AddLogEntry(level, line)
+			ls.AddServerOutput(source, line)
 		}
 	}
 
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"minecraft-easyserver/models"
+
+	"github.com/gorilla/websocket"
+)
+
+// dialLogs starts a log WebSocket server and connects to it
+func dialLogs(t *testing.T, server *httptest.Server) *websocket.Conn {
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatal("Failed to connect to logs:", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// readLogsUntil reads log entries until one has the given message, failing after a timeout
+func readLogsUntil(conn *websocket.Conn, message string) ([]models.ServerLogEntry, error) {
+	var entries []models.ServerLogEntry
+	conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	for {
+		var entry models.ServerLogEntry
+		if err := conn.ReadJSON(&entry); err != nil {
+			return entries, err
+		}
+		entries = append(entries, entry)
+		if entry.Message == message {
+			return entries, nil
+		}
+	}
+}
+
+// waitForLogClients waits until the given number of log clients are connected
+func waitForLogClients(t *testing.T, ls *LogService, count int) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		ls.clientsMux.RLock()
+		connected := len(ls.clients)
+		ls.clientsMux.RUnlock()
+
+		if connected == count {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d log clients, got %d", count, connected)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestLogServiceBroadcast(t *testing.T) {
+	logs := NewLogService()
+	logs.ClearLogs()
+	t.Cleanup(logs.ClearLogs)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logs.HandleWebSocket(w, r, StreamAuth{}, nil)
+	}))
+	t.Cleanup(server.Close)
+
+	t.Run("ManyClients", func(t *testing.T) {
+		logs.AddLogEntry("INFO", "ready")
+
+		const clientCount = 20
+		const writers = 4
+		const perWriter = 50
+
+		conns := make([]*websocket.Conn, clientCount)
+		for i := range conns {
+			conns[i] = dialLogs(t, server)
+			// The history includes the marker once the client is registered
+			if _, err := readLogsUntil(conns[i], "ready"); err != nil {
+				t.Fatal("Failed to read history:", err)
+			}
+		}
+
+		// Clients connect and disconnect while entries are logged
+		var wg sync.WaitGroup
+		for w := 0; w < writers; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < perWriter; i++ {
+					logs.AddLogEntry("INFO", fmt.Sprintf("writer %d line %d", w, i))
+				}
+			}(w)
+		}
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+				if err == nil {
+					conn.Close()
+				}
+			}()
+		}
+		wg.Wait()
+		logs.AddLogEntry("INFO", "done")
+
+		for i, conn := range conns {
+			entries, err := readLogsUntil(conn, "done")
+			if err != nil {
+				t.Fatalf("Client %d failed to read logs: %v", i, err)
+			}
+			// Each writer's lines arrive in order
+			next := make([]int, writers)
+			for _, entry := range entries[:len(entries)-1] {
+				var w, line int
+				if _, err := fmt.Sscanf(entry.Message, "writer %d line %d", &w, &line); err != nil {
+					t.Fatalf("Client %d got unexpected entry %q", i, entry.Message)
+				}
+				if line != next[w] {
+					t.Fatalf("Client %d got writer %d line %d, expected %d", i, w, line, next[w])
+				}
+				next[w]++
+			}
+			for w, count := range next {
+				if count != perWriter {
+					t.Errorf("Client %d got %d lines from writer %d, expected %d", i, count, w, perWriter)
+				}
+			}
+		}
+	})
+
+	t.Run("SlowClientIsDisconnected", func(t *testing.T) {
+		originalQueue := logs.queueSize
+		logs.queueSize = 64
+		t.Cleanup(func() { logs.queueSize = originalQueue })
+
+		// Wait for the clients of the previous test to go away
+		waitForLogClients(t, logs, 0)
+
+		// One client never reads, another keeps up
+		stuck := dialLogs(t, server)
+		reader := dialLogs(t, server)
+		waitForLogClients(t, logs, 2)
+
+		received := make(chan error, 1)
+		go func() {
+			reader.SetReadDeadline(time.Now().Add(30 * time.Second))
+			for {
+				_, data, err := reader.ReadMessage()
+				if err != nil {
+					received <- err
+					return
+				}
+				if strings.Contains(string(data), "flood done") {
+					received <- nil
+					return
+				}
+			}
+		}()
+
+		// Enough output to fill the stuck client's socket buffers and queue
+		line := strings.Repeat("x", 16*1024)
+		var slowest time.Duration
+		for i := 0; i < 2000; i++ {
+			start := time.Now()
+			logs.AddLogEntry("INFO", line)
+			if elapsed := time.Since(start); elapsed > slowest {
+				slowest = elapsed
+			}
+			time.Sleep(time.Millisecond)
+		}
+		logs.AddLogEntry("INFO", "flood done")
+		if slowest > time.Second {
+			t.Errorf("Logging was held up by the stuck client for %v", slowest)
+		}
+
+		if err := <-received; err != nil {
+			t.Error("Expected the reading client to get every entry, got", err)
+		}
+
+		// The stuck client is disconnected and removed
+		waitForLogClients(t, logs, 1)
+		stuck.SetReadDeadline(time.Now().Add(10 * time.Second))
+		for {
+			if _, _, err := stuck.ReadMessage(); err != nil {
+				if strings.Contains(err.Error(), "timeout") {
+					t.Error("Expected the stuck client's connection to be closed, got", err)
+				}
+				break
+			}
+		}
+	})
+
+	t.Run("GetLogsReturnsCopy", func(t *testing.T) {
+		got := logs.GetLogs(1)
+		got[0].Message = "changed"
+		if logs.GetLogs(1)[0].Message == "changed" {
+			t.Error("Expected GetLogs to return a copy of the buffer")
+		}
+	})
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"fmt"
+	"log/slog"
 	"minecraft-easyserver/models"
 	"os"
 	"path/filepath"
@@ -26,7 +27,7 @@ func NewPerformanceMonitoringService() *PerformanceMonitoringService {
 func (p *PerformanceMonitoringService) GetCPUUsage() (float64, error) {
 	percent, err := cpu.Percent(time.Second, false)
 	if err != nil {
-		fmt.Printf("Failed to obtain CPU usage rate: %v\n", err)
+		slog.Warn("Failed to obtain CPU usage rate", "error", err)
 		return 0, err
 	}
 	return percent[0], nil
@@ -36,7 +37,7 @@ func (p *PerformanceMonitoringService) GetCPUUsage() (float64, error) {
 func (p *PerformanceMonitoringService) GetMemoryUsage() (float64, error) {
 	v, err := mem.VirtualMemory()
 	if err != nil {
-		fmt.Printf("Failed to retrieve memory information: %v\n", err)
+		slog.Warn("Failed to retrieve memory information", "error", err)
 		return 0, err
 	}
 	return v.UsedPercent, nil
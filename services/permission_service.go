@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+
+	"minecraft-easyserver/models"
 )
 
 // PermissionService permission service
@@ -26,17 +28,17 @@ func (p *PermissionService) GetPermissions() ([]map[string]interface{}, error) {
 	return readPermissions(permissionsPath)
 }
 
-// UpdatePermission updates permission
-func (p *PermissionService) UpdatePermission(xuid, level string) error {
+// UpdatePermission updates permission and reloads permissions on the running server
+func (p *PermissionService) UpdatePermission(xuid, level, user string) (models.LiveSyncResult, error) {
 	// If no server version is active, return error
 	if bedrockPath == "" {
-		return fmt.Errorf("no server version is currently active. Please download and activate a server version first")
+		return models.LiveSyncResult{}, fmt.Errorf("no server version is currently active. Please download and activate a server version first")
 	}
 	
 	permissionsPath := filepath.Join(bedrockPath, "permissions.json")
 	permissions, err := readPermissions(permissionsPath)
 	if err != nil {
-		return err
+		return models.LiveSyncResult{}, err
 	}
 
 	// Validate permission level
@@ -49,7 +51,7 @@ func (p *PermissionService) UpdatePermission(xuid, level string) error {
 		}
 	}
 	if !isValid {
-		return fmt.Errorf("invalid permission level: %s. Valid levels are: visitor, member, operator", level)
+		return models.LiveSyncResult{}, fmt.Errorf("invalid permission level: %s. Valid levels are: visitor, member, operator", level)
 	}
 
 	// Find and update existing permission
@@ -71,20 +73,23 @@ func (p *PermissionService) UpdatePermission(xuid, level string) error {
 		permissions = append(permissions, newPermission)
 	}
 
-	return writePermissions(permissionsPath, permissions)
+	if err := writePermissions(permissionsPath, permissions, user); err != nil {
+		return models.LiveSyncResult{}, err
+	}
+	return reloadLiveFile("permissions.json"), nil
 }
 
-// RemovePermission removes permission
-func (p *PermissionService) RemovePermission(xuid string) error {
+// RemovePermission removes permission and reloads permissions on the running server
+func (p *PermissionService) RemovePermission(xuid, user string) (models.LiveSyncResult, error) {
 	// If no server version is active, return error
 	if bedrockPath == "" {
-		return fmt.Errorf("no server version is currently active. Please download and activate a server version first")
+		return models.LiveSyncResult{}, fmt.Errorf("no server version is currently active. Please download and activate a server version first")
 	}
 	
 	permissionsPath := filepath.Join(bedrockPath, "permissions.json")
 	permissions, err := readPermissions(permissionsPath)
 	if err != nil {
-		return err
+		return models.LiveSyncResult{}, err
 	}
 
 	// Find and remove permission
@@ -98,10 +103,13 @@ func (p *PermissionService) RemovePermission(xuid string) error {
 	}
 
 	if !found {
-		return fmt.Errorf("permission not found for player: %s", xuid)
+		return models.LiveSyncResult{}, fmt.Errorf("permission not found for player: %s", xuid)
 	}
 
-	return writePermissions(permissionsPath, permissions)
+	if err := writePermissions(permissionsPath, permissions, user); err != nil {
+		return models.LiveSyncResult{}, err
+	}
+	return reloadLiveFile("permissions.json"), nil
 }
 
 // Read permissions.json
@@ -126,11 +134,11 @@ func readPermissions(path string) ([]map[string]interface{}, error) {
 }
 
 // Write permissions.json
-func writePermissions(path string, permissions []map[string]interface{}) error {
+func writePermissions(path string, permissions []map[string]interface{}, user string) error {
 	data, err := json.MarshalIndent(permissions, "", "  ")
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(path, data, 0644)
+	return writeVersionedFile(path, data, user)
 }
\ No newline at end of file
@@ -0,0 +1,330 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"minecraft-easyserver/models"
+	"minecraft-easyserver/utils"
+)
+
+// Player moderation actions
+const (
+	PlayerActionKick     = "kick"
+	PlayerActionTell     = "tell"
+	PlayerActionTeleport = "teleport"
+	PlayerActionGamemode = "gamemode"
+	PlayerActionEffect   = "effect"
+	PlayerActionClear    = "clear"
+)
+
+var (
+	// effectNamePattern bedrock effect ids such as "speed" or "fire_resistance"
+	effectNamePattern = regexp.MustCompile(`^[a-z_]{1,32}$`)
+
+	// itemNamePattern bedrock item ids, optionally namespaced, e.g. "minecraft:tnt"
+	itemNamePattern = regexp.MustCompile(`^[a-z0-9_]+(:[a-z0-9_]+)?$`)
+
+	// validGamemodes game modes accepted by the gamemode command
+	validGamemodes = []string{"survival", "creative", "adventure", "spectator"}
+)
+
+// maxModerationText maximum length of kick reasons and private messages
+const maxModerationText = 200
+
+// PlayerService sends typed moderation commands for a single player
+type PlayerService struct {
+	mutex       sync.Mutex
+	maxAudit    int
+	interaction *InteractionService
+}
+
+var playerService *PlayerService
+
+// NewPlayerService returns the global player service instance
+func NewPlayerService() *PlayerService {
+	if playerService == nil {
+		playerService = &PlayerService{
+			maxAudit:    1000, // Keep last 1000 audit entries
+			interaction: GetInteractionService(),
+		}
+	}
+	return playerService
+}
+
+// moderationAuditPath returns the file the moderation audit trail is stored in
+func moderationAuditPath() string {
+	return filepath.Join(dataDir, "moderation_audit.json")
+}
+
+// Kick kicks a player with an optional reason
+func (p *PlayerService) Kick(player, reason, user string) (models.LiveSyncResult, error) {
+	target, err := quotePlayer(player)
+	if err != nil {
+		return models.LiveSyncResult{}, err
+	}
+	reason, err = moderationText(reason, false)
+	if err != nil {
+		return models.LiveSyncResult{}, err
+	}
+
+	command := "kick " + target
+	if reason != "" {
+		command += " " + reason
+	}
+	return p.run(PlayerActionKick, player, command, true, user)
+}
+
+// Tell sends a private message to a player
+func (p *PlayerService) Tell(player, message, user string) (models.LiveSyncResult, error) {
+	target, err := quotePlayer(player)
+	if err != nil {
+		return models.LiveSyncResult{}, err
+	}
+	message, err = moderationText(message, true)
+	if err != nil {
+		return models.LiveSyncResult{}, err
+	}
+
+	return p.run(PlayerActionTell, player, "tell "+target+" "+message, false, user)
+}
+
+// TeleportToPosition teleports a player to coordinates
+func (p *PlayerService) TeleportToPosition(player string, x, y, z float64, user string) (models.LiveSyncResult, error) {
+	target, err := quotePlayer(player)
+	if err != nil {
+		return models.LiveSyncResult{}, err
+	}
+
+	command := fmt.Sprintf("tp %s %s %s %s", target, formatCoordinate(x), formatCoordinate(y), formatCoordinate(z))
+	return p.run(PlayerActionTeleport, player, command, true, user)
+}
+
+// TeleportToPlayer teleports a player to another player
+func (p *PlayerService) TeleportToPlayer(player, destination, user string) (models.LiveSyncResult, error) {
+	target, err := quotePlayer(player)
+	if err != nil {
+		return models.LiveSyncResult{}, err
+	}
+	quotedDestination, err := quotePlayer(destination)
+	if err != nil {
+		return models.LiveSyncResult{}, err
+	}
+
+	return p.run(PlayerActionTeleport, player, "tp "+target+" "+quotedDestination, true, user)
+}
+
+// SetGamemode sets a player's game mode
+func (p *PlayerService) SetGamemode(player, gamemode, user string) (models.LiveSyncResult, error) {
+	target, err := quotePlayer(player)
+	if err != nil {
+		return models.LiveSyncResult{}, err
+	}
+
+	valid := false
+	for _, mode := range validGamemodes {
+		if gamemode == mode {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return models.LiveSyncResult{}, fmt.Errorf("invalid gamemode: %s. Valid gamemodes are: %s", gamemode, strings.Join(validGamemodes, ", "))
+	}
+
+	return p.run(PlayerActionGamemode, player, "gamemode "+gamemode+" "+target, true, user)
+}
+
+// GiveEffect gives a player a status effect. The effect "clear" removes all effects.
+func (p *PlayerService) GiveEffect(player, effect string, seconds, amplifier int, hideParticles bool, user string) (models.LiveSyncResult, error) {
+	target, err := quotePlayer(player)
+	if err != nil {
+		return models.LiveSyncResult{}, err
+	}
+	if !effectNamePattern.MatchString(effect) {
+		return models.LiveSyncResult{}, fmt.Errorf("invalid effect: %s", effect)
+	}
+
+	command := "effect " + target + " " + effect
+	if effect != "clear" {
+		if seconds < 1 || seconds > 1000000 {
+			return models.LiveSyncResult{}, fmt.Errorf("invalid effect duration: %d. Duration must be between 1 and 1000000 seconds", seconds)
+		}
+		if amplifier < 0 || amplifier > 255 {
+			return models.LiveSyncResult{}, fmt.Errorf("invalid effect amplifier: %d. Amplifier must be between 0 and 255", amplifier)
+		}
+		command += fmt.Sprintf(" %d %d %t", seconds, amplifier, hideParticles)
+	}
+
+	return p.run(PlayerActionEffect, player, command, true, user)
+}
+
+// ClearInventory clears a player's inventory, or only the given item
+func (p *PlayerService) ClearInventory(player, item, user string) (models.LiveSyncResult, error) {
+	target, err := quotePlayer(player)
+	if err != nil {
+		return models.LiveSyncResult{}, err
+	}
+
+	command := "clear " + target
+	if item != "" {
+		if !itemNamePattern.MatchString(item) {
+			return models.LiveSyncResult{}, fmt.Errorf("invalid item: %s", item)
+		}
+		command += " " + item
+	}
+
+	return p.run(PlayerActionClear, player, command, true, user)
+}
+
+// GetAudit returns the moderation audit trail, newest first.
+// player optionally filters by player name.
+func (p *PlayerService) GetAudit(player string, limit int) ([]models.ModerationAuditEntry, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	entries, err := readModerationAudit()
+	if err != nil {
+		return nil, err
+	}
+
+	result := []models.ModerationAuditEntry{}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if player != "" && !strings.EqualFold(entries[i].Player, player) {
+			continue
+		}
+		result = append(result, entries[i])
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+// run sends a moderation command and records it in the audit trail. If confirm is
+// set, the command only counts as confirmed once bedrock's response to it names the
+// player; it is false for commands the server doesn't answer.
+func (p *PlayerService) run(action, player, command string, confirm bool, user string) (models.LiveSyncResult, error) {
+	if !serverRunning() {
+		return models.LiveSyncResult{}, fmt.Errorf("server is not running")
+	}
+
+	result := models.LiveSyncResult{ServerRunning: true, Command: command}
+	if !confirm {
+		if err := p.interaction.SendCommand(command); err != nil {
+			return models.LiveSyncResult{}, err
+		}
+	} else {
+		response, err := p.interaction.SendCommandAndWait(command, commandResponse(command, player), liveCommandTimeout)
+		if err != nil && !strings.Contains(err.Error(), "timed out") {
+			return models.LiveSyncResult{}, err
+		}
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Confirmed = true
+			result.Response = response
+		}
+	}
+
+	p.audit(models.ModerationAuditEntry{
+		Timestamp: time.Now().Format("2006-01-02 15:04:05"),
+		Action:    action,
+		User:      user,
+		Player:    player,
+		Command:   command,
+		Confirmed: result.Confirmed,
+		Error:     result.Error,
+	})
+	return result, nil
+}
+
+// audit appends an entry to the moderation audit trail
+func (p *PlayerService) audit(entry models.ModerationAuditEntry) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	entries, err := readModerationAudit()
+	if err != nil {
+		slog.Warn("Failed to read moderation audit trail", "error", err)
+		return
+	}
+
+	entries = append(entries, entry)
+
+	// Keep only the last maxAudit entries
+	if len(entries) > p.maxAudit {
+		entries = entries[len(entries)-p.maxAudit:]
+	}
+
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		slog.Warn("Failed to write moderation audit trail", "error", err)
+		return
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err == nil {
+		err = utils.WriteFileAtomic(moderationAuditPath(), data, 0644)
+	}
+	if err != nil {
+		slog.Warn("Failed to write moderation audit trail", "error", err)
+	}
+}
+
+// quotePlayer validates and quotes a player name. Selectors such as @a are quoted
+// so they only match a player with that literal name.
+func quotePlayer(player string) (string, error) {
+	quoted, err := QuoteCommandArgument(strings.TrimSpace(player))
+	if err != nil {
+		return "", fmt.Errorf("invalid player name: %v", err)
+	}
+	return quoted, nil
+}
+
+// moderationText validates free text that ends a command, such as a kick reason
+func moderationText(text string, required bool) (string, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		if required {
+			return "", fmt.Errorf("invalid message: message cannot be empty")
+		}
+		return "", nil
+	}
+	if len(text) > maxModerationText {
+		return "", fmt.Errorf("invalid message: message too long (max %d characters)", maxModerationText)
+	}
+	if strings.ContainsAny(text, "\r\n") {
+		return "", fmt.Errorf("invalid message: message cannot contain line breaks")
+	}
+	return text, nil
+}
+
+// formatCoordinate formats a coordinate without trailing zeros
+func formatCoordinate(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// readModerationAudit reads the moderation audit trail; callers must hold the mutex
+func readModerationAudit() ([]models.ModerationAuditEntry, error) {
+	var entries []models.ModerationAuditEntry
+
+	data, err := os.ReadFile(moderationAuditPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return entries, nil
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse moderation audit trail: %v", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeRunningServer makes GetStatus report a running server backed by the test process
+func fakeRunningServer(t *testing.T) {
+	serverMutex.Lock()
+	serverProcess = &exec.Cmd{Process: &os.Process{Pid: os.Getpid()}}
+	serverMutex.Unlock()
+
+	t.Cleanup(func() {
+		serverMutex.Lock()
+		serverProcess = nil
+		serverMutex.Unlock()
+	})
+}
+
+func TestPlayerService(t *testing.T) {
+	originalDataDir := dataDir
+	SetDataDir(t.TempDir())
+	t.Cleanup(func() { SetDataDir(originalDataDir) })
+
+	players := NewPlayerService()
+
+	t.Run("ServerNotRunning", func(t *testing.T) {
+		if _, err := players.Kick("Steve", "", "admin"); err == nil || !strings.Contains(err.Error(), "not running") {
+			t.Errorf("Expected not running error, got %v", err)
+		}
+	})
+
+	interaction := GetInteractionService()
+	if !interaction.IsEnabled() {
+		t.Skip("Server interaction is not supported on this platform")
+	}
+
+	var mutex sync.Mutex
+	var commands []string
+	interaction.SetStdin(&fakeServerStdin{respond: func(command string) string {
+		mutex.Lock()
+		commands = append(commands, command)
+		mutex.Unlock()
+		switch {
+		case strings.HasPrefix(command, "kick "):
+			return "Kicked Some Player from the game"
+		case strings.HasPrefix(command, "tp "):
+			return "Teleported Some Player to 10, 64.5, -3"
+		case strings.HasPrefix(command, "effect "):
+			// Chat and responses for other players don't confirm a command
+			return "<Steve> no effect on me\nGave Speed * 1 to Steve for 30 seconds"
+		}
+		return ""
+	}})
+	t.Cleanup(func() { interaction.SetStdin(nil) })
+	fakeRunningServer(t)
+
+	lastCommand := func() string {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if len(commands) == 0 {
+			return ""
+		}
+		return commands[len(commands)-1]
+	}
+
+	t.Run("QuotesPlayerNames", func(t *testing.T) {
+		result, err := players.Kick("Some Player", "griefing", "admin")
+		if err != nil {
+			t.Fatal("Failed to kick:", err)
+		}
+		if !result.Confirmed {
+			t.Errorf("Expected kick to be confirmed, got %+v", result)
+		}
+		if lastCommand() != `kick "Some Player" griefing` {
+			t.Errorf("Unexpected command: %s", lastCommand())
+		}
+
+		if _, err := players.Tell("@a", "hello", "admin"); err != nil {
+			t.Fatal("Failed to tell:", err)
+		}
+		if lastCommand() != `tell "@a" hello` {
+			t.Errorf("Expected selector to be quoted, got %s", lastCommand())
+		}
+	})
+
+	t.Run("Teleport", func(t *testing.T) {
+		if _, err := players.TeleportToPosition("Some Player", 10, 64.5, -3, "admin"); err != nil {
+			t.Fatal("Failed to teleport:", err)
+		}
+		if lastCommand() != `tp "Some Player" 10 64.5 -3` {
+			t.Errorf("Unexpected command: %s", lastCommand())
+		}
+	})
+
+	t.Run("UnrelatedOutput", func(t *testing.T) {
+		result, err := players.GiveEffect("Some Player", "speed", 30, 1, false, "admin")
+		if err != nil {
+			t.Fatal("Failed to give effect:", err)
+		}
+		if result.Confirmed || !strings.Contains(result.Error, "timed out") {
+			t.Errorf("Expected effect not to be confirmed by unrelated output, got %+v", result)
+		}
+	})
+
+	t.Run("RejectsUnsafeInput", func(t *testing.T) {
+		mutex.Lock()
+		sent := len(commands)
+		mutex.Unlock()
+
+		cases := []func() error{
+			func() error { _, err := players.Kick(`Steve" op @s`, "", "admin"); return err },
+			func() error { _, err := players.Tell("Steve", "hi\nop Steve", "admin"); return err },
+			func() error { _, err := players.SetGamemode("Steve", "god", "admin"); return err },
+			func() error { _, err := players.GiveEffect("Steve", "speed; op", 30, 1, false, "admin"); return err },
+			func() error { _, err := players.ClearInventory("Steve", "tnt 1 0", "admin"); return err },
+		}
+		for i, run := range cases {
+			if err := run(); err == nil || !strings.Contains(err.Error(), "invalid") {
+				t.Errorf("Case %d: expected invalid input error, got %v", i, err)
+			}
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		if len(commands) != sent {
+			t.Errorf("Expected no commands to be sent for invalid input, got %v", commands[sent:])
+		}
+	})
+
+	t.Run("Audit", func(t *testing.T) {
+		audit, err := players.GetAudit("some player", 0)
+		if err != nil {
+			t.Fatal("Failed to read audit trail:", err)
+		}
+		if len(audit) != 3 || audit[0].Action != PlayerActionEffect || audit[0].Confirmed || audit[1].Action != PlayerActionTeleport || audit[2].Action != PlayerActionKick {
+			t.Errorf("Unexpected audit trail: %+v", audit)
+		}
+	})
+}
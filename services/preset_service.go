@@ -0,0 +1,230 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"minecraft-easyserver/models"
+	"minecraft-easyserver/utils"
+)
+
+// presetNamePattern allowed preset names: letters, digits, spaces, dashes and underscores
+var presetNamePattern = regexp.MustCompile(`^[\p{L}\p{N} _-]{1,64}$`)
+
+// PresetService manages named server.properties presets
+type PresetService struct {
+	mutex         sync.Mutex
+	configService *ConfigService
+}
+
+var presetService *PresetService
+
+// NewPresetService returns the global preset service instance
+func NewPresetService() *PresetService {
+	if presetService == nil {
+		presetService = &PresetService{
+			configService: NewConfigService(),
+		}
+	}
+	return presetService
+}
+
+// presetsPath returns the file presets are stored in
+func presetsPath() string {
+	return filepath.Join(dataDir, "presets.json")
+}
+
+// GetPresets gets all presets sorted by name
+func (p *PresetService) GetPresets() ([]models.ConfigPreset, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	presets, err := readPresets()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(presets, func(i, j int) bool {
+		return presets[i].Name < presets[j].Name
+	})
+	return presets, nil
+}
+
+// GetPreset gets a preset by name
+func (p *PresetService) GetPreset(name string) (*models.ConfigPreset, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	presets, err := readPresets()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range presets {
+		if presets[i].Name == name {
+			return &presets[i], nil
+		}
+	}
+	return nil, fmt.Errorf("preset not found: %s", name)
+}
+
+// CreatePreset saves a new preset. Values come from properties when given,
+// otherwise the listed keys are copied from the current server configuration.
+func (p *PresetService) CreatePreset(name, description string, keys []string, properties models.ServerProperties) (*models.ConfigPreset, error) {
+	if !presetNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid preset name: %s. Use up to 64 letters, digits, spaces, dashes or underscores", name)
+	}
+
+	if len(properties) == 0 {
+		current, err := p.configService.GetConfig()
+		if err != nil {
+			return nil, err
+		}
+
+		properties = make(models.ServerProperties, len(keys))
+		for _, key := range keys {
+			value, exists := current[key]
+			if !exists {
+				return nil, fmt.Errorf("invalid preset: %s is not set in the current configuration", key)
+			}
+			properties[key] = value
+		}
+	}
+
+	if len(properties) == 0 {
+		return nil, fmt.Errorf("invalid preset: at least one property is required")
+	}
+
+	// Store values in their typed form so applying the preset can't fail validation later
+	formatted, err := validateServerProperties(properties)
+	if err != nil {
+		return nil, err
+	}
+	typed := make(models.ServerProperties, len(formatted))
+	for key, value := range formatted {
+		typed[key] = typedPropertyValue(key, value)
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	presets, err := readPresets()
+	if err != nil {
+		return nil, err
+	}
+	for _, preset := range presets {
+		if preset.Name == name {
+			return nil, fmt.Errorf("preset already exists: %s", name)
+		}
+	}
+
+	preset := models.ConfigPreset{
+		Name:        name,
+		Description: description,
+		Properties:  typed,
+		CreatedAt:   time.Now().Format("2006-01-02 15:04:05"),
+	}
+	presets = append(presets, preset)
+
+	if err := writePresets(presets); err != nil {
+		return nil, err
+	}
+	return &preset, nil
+}
+
+// DeletePreset deletes a preset by name
+func (p *PresetService) DeletePreset(name string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	presets, err := readPresets()
+	if err != nil {
+		return err
+	}
+
+	for i, preset := range presets {
+		if preset.Name == name {
+			presets = append(presets[:i], presets[i+1:]...)
+			return writePresets(presets)
+		}
+	}
+	return fmt.Errorf("preset not found: %s", name)
+}
+
+// DiffPreset lists the preset values that differ from the live server.properties
+func (p *PresetService) DiffPreset(name string) ([]models.ConfigPresetDiff, error) {
+	preset, err := p.GetPreset(name)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := p.configService.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	diff := []models.ConfigPresetDiff{}
+	for key, value := range preset.Properties {
+		currentValue, exists := current[key]
+		if exists && fmt.Sprint(currentValue) == fmt.Sprint(value) {
+			continue
+		}
+		diff = append(diff, models.ConfigPresetDiff{
+			Key:     key,
+			Current: currentValue,
+			Preset:  value,
+		})
+	}
+
+	sort.Slice(diff, func(i, j int) bool {
+		return diff[i].Key < diff[j].Key
+	})
+	return diff, nil
+}
+
+// ApplyPreset writes the preset values through the config service
+func (p *PresetService) ApplyPreset(name, user string) (models.ConfigUpdateResult, error) {
+	preset, err := p.GetPreset(name)
+	if err != nil {
+		return models.ConfigUpdateResult{}, err
+	}
+
+	return p.configService.UpdateConfig(preset.Properties, user)
+}
+
+// readPresets reads presets from disk; callers must hold the mutex
+func readPresets() ([]models.ConfigPreset, error) {
+	var presets []models.ConfigPreset
+
+	data, err := os.ReadFile(presetsPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return presets, nil
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &presets); err != nil {
+		return nil, fmt.Errorf("failed to parse presets: %v", err)
+	}
+	return presets, nil
+}
+
+// writePresets writes presets to disk; callers must hold the mutex
+func writePresets(presets []models.ConfigPreset) error {
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(presets, "", "  ")
+	if err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(presetsPath(), data, 0644)
+}
@@ -50,8 +50,9 @@ func (r *ResourcePackService) GetResourcePacks() ([]models.ResourcePackInfo, err
 		return packs, nil
 	}
 
-	// Read active resource packs from world configuration
+	// Read active resource packs and their selected subpacks from world configuration
 	activePackUUIDs := make(map[string]bool)
+	activeSubpacks := make(map[string]string)
 	worldPath := filepath.Join(bedrockPath, "worlds", config.LevelName)
 	worldResourcePacksPath := filepath.Join(worldPath, "world_resource_packs.json")
 	if data, err := os.ReadFile(worldResourcePacksPath); err == nil {
@@ -59,6 +60,7 @@ func (r *ResourcePackService) GetResourcePacks() ([]models.ResourcePackInfo, err
 		if json.Unmarshal(data, &worldPacks) == nil {
 			for _, pack := range worldPacks {
 				activePackUUIDs[pack.PackID] = true
+				activeSubpacks[pack.PackID] = pack.Subpack
 			}
 		}
 	}
@@ -86,12 +88,19 @@ func (r *ResourcePackService) GetResourcePacks() ([]models.ResourcePackInfo, err
 				var manifest models.ResourcePackManifest
 				if json.Unmarshal(data, &manifest) == nil {
 					packInfo := models.ResourcePackInfo{
-						Name:        manifest.Header.Name,
-						UUID:        manifest.Header.UUID,
-						Version:     manifest.Header.Version,
-						Description: manifest.Header.Description,
-						FolderName:  entry.Name(),
-						Active:      activePackUUIDs[manifest.Header.UUID],
+						Name:          manifest.Header.Name,
+						UUID:          manifest.Header.UUID,
+						Version:       manifest.Header.Version,
+						Description:   manifest.Header.Description,
+						FolderName:    entry.Name(),
+						Active:        activePackUUIDs[manifest.Header.UUID],
+						Subpacks:      manifest.Subpacks,
+						ActiveSubpack: activeSubpacks[manifest.Header.UUID],
+					}
+					// Expose the version that a rollback would restore
+					if backup, err := readPackManifest(filepath.Join(packBackupDir(), manifest.Header.UUID)); err == nil {
+						previousVersion := backup.Header.Version
+						packInfo.PreviousVersion = &previousVersion
 					}
 					packs = append(packs, packInfo)
 				}
@@ -102,22 +111,45 @@ func (r *ResourcePackService) GetResourcePacks() ([]models.ResourcePackInfo, err
 	return packs, nil
 }
 
-// UploadResourcePack uploads and extracts resource pack
-func (r *ResourcePackService) UploadResourcePack(zipPath, fileName string) (*models.ResourcePackInfo, error) {
+// Upload conflict modes used when a pack with the same header UUID is already installed
+const (
+	PackConflictReject  = "reject"
+	PackConflictReplace = "replace"
+	PackConflictKeep    = "keep"
+)
+
+// UploadResourcePack uploads and extracts resource pack.
+// conflictMode decides what happens when a pack with the same header UUID is
+// already installed: reject the upload, replace the installed pack (keeping the
+// old version for rollback) or keep both side by side.
+func (r *ResourcePackService) UploadResourcePack(zipPath, fileName, conflictMode string) (*models.ResourcePackInfo, error) {
 	// If no server version is active, return error
 	if bedrockPath == "" {
 		return nil, fmt.Errorf("no server version is currently active. Please download and activate a server version first")
 	}
-	
+
+	if conflictMode == "" {
+		conflictMode = PackConflictReject
+	}
+	if conflictMode != PackConflictReject && conflictMode != PackConflictReplace && conflictMode != PackConflictKeep {
+		return nil, fmt.Errorf("invalid conflict mode: %s. Valid modes are: reject, replace, keep", conflictMode)
+	}
+
 	// Create resource_packs directory if it doesn't exist
 	resourcePacksPath := filepath.Join(bedrockPath, "resource_packs")
 	if err := os.MkdirAll(resourcePacksPath, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create resource_packs directory: %v", err)
 	}
 
-	// Extract zip file
-	extractPath := filepath.Join(resourcePacksPath, strings.TrimSuffix(fileName, filepath.Ext(fileName)))
-	if err := utils.ExtractZip(zipPath, extractPath); err != nil {
+	// Extract into a staging directory outside resource_packs so the server
+	// never sees a half-extracted pack
+	stagingPath, err := os.MkdirTemp(bedrockPath, ".pack-upload-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %v", err)
+	}
+	defer os.RemoveAll(stagingPath)
+
+	if err := utils.ExtractZip(zipPath, stagingPath); err != nil {
 		return nil, fmt.Errorf("failed to extract resource pack: %v", err)
 	}
 
@@ -125,19 +157,9 @@ func (r *ResourcePackService) UploadResourcePack(zipPath, fileName string) (*mod
 	os.Remove(zipPath)
 
 	// Read manifest.json to get pack information
-	manifestPath := filepath.Join(extractPath, "manifest.json")
-	data, err := os.ReadFile(manifestPath)
+	manifest, err := readPackManifest(stagingPath)
 	if err != nil {
-		// Clean up extracted files if manifest is missing
-		os.RemoveAll(extractPath)
-		return nil, fmt.Errorf("manifest.json not found in resource pack")
-	}
-
-	var manifest models.ResourcePackManifest
-	if err := json.Unmarshal(data, &manifest); err != nil {
-		// Clean up extracted files if manifest is invalid
-		os.RemoveAll(extractPath)
-		return nil, fmt.Errorf("invalid manifest.json: %v", err)
+		return nil, err
 	}
 
 	packInfo := &models.ResourcePackInfo{
@@ -145,15 +167,246 @@ func (r *ResourcePackService) UploadResourcePack(zipPath, fileName string) (*mod
 		UUID:        manifest.Header.UUID,
 		Version:     manifest.Header.Version,
 		Description: manifest.Header.Description,
-		FolderName:  strings.TrimSuffix(fileName, filepath.Ext(fileName)),
 		Active:      false,
+		Subpacks:    manifest.Subpacks,
+	}
+
+	existing, err := r.findInstalledPack(manifest.Header.UUID)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing != nil {
+		switch conflictMode {
+		case PackConflictReject:
+			return nil, fmt.Errorf("resource pack already installed: %s (%s) version %s",
+				existing.Name, existing.UUID, formatPackVersion(existing.Version))
+		case PackConflictReplace:
+			return r.replaceResourcePack(existing, stagingPath, packInfo)
+		}
+	}
+
+	// New pack, or keep both: move into a folder that doesn't clash with an installed one
+	folderName := uniquePackFolder(resourcePacksPath, strings.TrimSuffix(fileName, filepath.Ext(fileName)))
+	if err := os.Rename(stagingPath, filepath.Join(resourcePacksPath, folderName)); err != nil {
+		return nil, fmt.Errorf("failed to install resource pack: %v", err)
 	}
 
+	packInfo.FolderName = folderName
 	return packInfo, nil
 }
 
-// ActivateResourcePack activates resource pack
-func (r *ResourcePackService) ActivateResourcePack(packUUID string) error {
+// replaceResourcePack swaps an installed pack for a newly extracted version.
+// The previous version is moved to the backup directory so it can be rolled back.
+func (r *ResourcePackService) replaceResourcePack(existing *models.ResourcePackInfo, stagingPath string, packInfo *models.ResourcePackInfo) (*models.ResourcePackInfo, error) {
+	packPath := filepath.Join(bedrockPath, "resource_packs", existing.FolderName)
+	backupPath := filepath.Join(packBackupDir(), existing.UUID)
+
+	if err := os.MkdirAll(packBackupDir(), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create resource pack backup directory: %v", err)
+	}
+
+	// Only the most recent previous version is kept
+	if err := os.RemoveAll(backupPath); err != nil {
+		return nil, fmt.Errorf("failed to remove old resource pack backup: %v", err)
+	}
+
+	if err := os.Rename(packPath, backupPath); err != nil {
+		return nil, fmt.Errorf("failed to back up installed resource pack: %v", err)
+	}
+
+	if err := os.Rename(stagingPath, packPath); err != nil {
+		// Put the previous version back in place
+		os.Rename(backupPath, packPath)
+		return nil, fmt.Errorf("failed to install resource pack: %v", err)
+	}
+
+	if _, err := updateWorldPackVersion(existing.UUID, packInfo.Version); err != nil {
+		return nil, fmt.Errorf("resource pack replaced but failed to update world pack versions: %v", err)
+	}
+
+	previousVersion := existing.Version
+	packInfo.FolderName = existing.FolderName
+	packInfo.Active = existing.Active
+	packInfo.ActiveSubpack = existing.ActiveSubpack
+	packInfo.PreviousVersion = &previousVersion
+	return packInfo, nil
+}
+
+// RollbackResourcePack restores the version a pack had before it was last replaced.
+// The current version takes the place of the backup, so a rollback can be undone
+// by rolling back again.
+func (r *ResourcePackService) RollbackResourcePack(packUUID string) (*models.ResourcePackInfo, error) {
+	// If no server version is active, return error
+	if bedrockPath == "" {
+		return nil, fmt.Errorf("no server version is currently active. Please download and activate a server version first")
+	}
+
+	current, err := r.findInstalledPack(packUUID)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, fmt.Errorf("resource pack not found: %s", packUUID)
+	}
+
+	backupPath := filepath.Join(packBackupDir(), packUUID)
+	backupManifest, err := readPackManifest(backupPath)
+	if err != nil {
+		return nil, fmt.Errorf("no previous version available for resource pack: %s", packUUID)
+	}
+
+	packPath := filepath.Join(bedrockPath, "resource_packs", current.FolderName)
+	swapPath := filepath.Join(bedrockPath, ".pack-rollback-"+packUUID)
+	os.RemoveAll(swapPath)
+
+	if err := os.Rename(packPath, swapPath); err != nil {
+		return nil, fmt.Errorf("failed to move current resource pack aside: %v", err)
+	}
+	if err := os.Rename(backupPath, packPath); err != nil {
+		os.Rename(swapPath, packPath)
+		return nil, fmt.Errorf("failed to restore previous resource pack: %v", err)
+	}
+	if err := os.Rename(swapPath, backupPath); err != nil {
+		return nil, fmt.Errorf("previous version restored but failed to keep current version as backup: %v", err)
+	}
+
+	if _, err := updateWorldPackVersion(packUUID, backupManifest.Header.Version); err != nil {
+		return nil, fmt.Errorf("resource pack rolled back but failed to update world pack versions: %v", err)
+	}
+
+	previousVersion := current.Version
+	return &models.ResourcePackInfo{
+		Name:            backupManifest.Header.Name,
+		UUID:            backupManifest.Header.UUID,
+		Version:         backupManifest.Header.Version,
+		Description:     backupManifest.Header.Description,
+		FolderName:      current.FolderName,
+		Active:          current.Active,
+		PreviousVersion: &previousVersion,
+		Subpacks:        backupManifest.Subpacks,
+		ActiveSubpack:   current.ActiveSubpack,
+	}, nil
+}
+
+// hasSubpack checks if a subpack folder name is declared in the manifest subpacks
+func hasSubpack(subpacks []models.ResourcePackSubpack, folderName string) bool {
+	for _, subpack := range subpacks {
+		if subpack.FolderName == folderName {
+			return true
+		}
+	}
+	return false
+}
+
+// findInstalledPack returns the first installed pack with the given header UUID, or nil
+func (r *ResourcePackService) findInstalledPack(packUUID string) (*models.ResourcePackInfo, error) {
+	packs, err := r.GetResourcePacks()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pack := range packs {
+		if pack.UUID == packUUID {
+			return &pack, nil
+		}
+	}
+	return nil, nil
+}
+
+// packBackupDir returns the directory holding the previous version of replaced packs.
+// It lives outside resource_packs so the server doesn't load the old copies.
+func packBackupDir() string {
+	return filepath.Join(bedrockPath, "resource_packs_backup")
+}
+
+// readPackManifest reads manifest.json from a pack directory
+func readPackManifest(packPath string) (*models.ResourcePackManifest, error) {
+	data, err := os.ReadFile(filepath.Join(packPath, "manifest.json"))
+	if err != nil {
+		return nil, fmt.Errorf("manifest.json not found in resource pack")
+	}
+
+	var manifest models.ResourcePackManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest.json: %v", err)
+	}
+	return &manifest, nil
+}
+
+// uniquePackFolder returns folderName, or folderName with a numeric suffix if it is taken
+func uniquePackFolder(resourcePacksPath, folderName string) string {
+	candidate := folderName
+	for i := 2; ; i++ {
+		if _, err := os.Stat(filepath.Join(resourcePacksPath, candidate)); os.IsNotExist(err) {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s_%d", folderName, i)
+	}
+}
+
+// updateWorldPackVersion pins the given version for a pack in every world that uses it.
+// It returns the number of worlds that were updated.
+func updateWorldPackVersion(packUUID string, version [3]int) (int, error) {
+	worldsPath := filepath.Join(bedrockPath, "worlds")
+	entries, err := os.ReadDir(worldsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	updated := 0
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		worldResourcePacksPath := filepath.Join(worldsPath, entry.Name(), "world_resource_packs.json")
+		data, err := os.ReadFile(worldResourcePacksPath)
+		if err != nil {
+			continue
+		}
+
+		var worldPacks []models.WorldResourcePack
+		if err := json.Unmarshal(data, &worldPacks); err != nil {
+			continue
+		}
+
+		changed := false
+		for i := range worldPacks {
+			if worldPacks[i].PackID == packUUID && worldPacks[i].Version != version {
+				worldPacks[i].Version = version
+				changed = true
+			}
+		}
+		if !changed {
+			continue
+		}
+
+		data, err = json.MarshalIndent(worldPacks, "", "  ")
+		if err != nil {
+			return updated, err
+		}
+		if err := utils.WriteFileAtomic(worldResourcePacksPath, data, 0644); err != nil {
+			return updated, fmt.Errorf("failed to update %s: %v", entry.Name(), err)
+		}
+		updated++
+	}
+
+	return updated, nil
+}
+
+// formatPackVersion formats a pack version array as "major.minor.patch"
+func formatPackVersion(version [3]int) string {
+	return fmt.Sprintf("%d.%d.%d", version[0], version[1], version[2])
+}
+
+// ActivateResourcePack activates resource pack.
+// subpack optionally selects one of the subpacks declared in the manifest by folder name;
+// calling it again for an active pack with a different subpack switches the selection.
+func (r *ResourcePackService) ActivateResourcePack(packUUID, subpack string) error {
 	// If no server version is active, return error
 	if bedrockPath == "" {
 		return fmt.Errorf("no server version is currently active. Please download and activate a server version first")
@@ -182,7 +435,11 @@ func (r *ResourcePackService) ActivateResourcePack(packUUID string) error {
 		return fmt.Errorf("cannot activate system resource pack: %s", targetPack.Name)
 	}
 
-	if targetPack.Active {
+	if subpack != "" && !hasSubpack(targetPack.Subpacks, subpack) {
+		return fmt.Errorf("subpack not found in resource pack %s: %s", targetPack.Name, subpack)
+	}
+
+	if targetPack.Active && (subpack == "" || subpack == targetPack.ActiveSubpack) {
 		return fmt.Errorf("resource pack already activated: %s", targetPack.Name)
 	}
 
@@ -202,12 +459,22 @@ func (r *ResourcePackService) ActivateResourcePack(packUUID string) error {
 		json.Unmarshal(data, &worldPacks)
 	}
 
-	// Add new resource pack
-	newPack := models.WorldResourcePack{
-		PackID:  packUUID,
-		Version: targetPack.Version,
+	if targetPack.Active {
+		// Switch the subpack of the already active pack
+		for i := range worldPacks {
+			if worldPacks[i].PackID == packUUID {
+				worldPacks[i].Subpack = subpack
+			}
+		}
+	} else {
+		// Add new resource pack
+		newPack := models.WorldResourcePack{
+			PackID:  packUUID,
+			Subpack: subpack,
+			Version: targetPack.Version,
+		}
+		worldPacks = append(worldPacks, newPack)
 	}
-	worldPacks = append(worldPacks, newPack)
 
 	// Save updated resource packs
 	data, err := json.MarshalIndent(worldPacks, "", "  ")
@@ -218,7 +485,7 @@ func (r *ResourcePackService) ActivateResourcePack(packUUID string) error {
 	// Ensure world directory exists
 	os.MkdirAll(worldPath, 0755)
 
-	return os.WriteFile(worldResourcePacksPath, data, 0644)
+	return utils.WriteFileAtomic(worldResourcePacksPath, data, 0644)
 }
 
 // DeactivateResourcePack deactivates resource pack
@@ -283,7 +550,7 @@ func (r *ResourcePackService) DeactivateResourcePack(packUUID string) error {
 		return err
 	}
 
-	return os.WriteFile(worldResourcePacksPath, data, 0644)
+	return utils.WriteFileAtomic(worldResourcePacksPath, data, 0644)
 }
 
 // DeleteResourcePack deletes resource pack
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"archive/zip"
 	"encoding/json"
 	"os"
 	"path/filepath"
@@ -19,6 +20,7 @@ func TestResourcePackService(t *testing.T) {
 
 	// Set bedrock path
 	SetBedrockPath(tempDir)
+	SetDataDir(filepath.Join(tempDir, "data"))
 
 	// Create test server.properties first
 	serverPropsPath := filepath.Join(tempDir, "server.properties")
@@ -105,7 +107,7 @@ func TestResourcePackService(t *testing.T) {
 
 	// Test ActivateResourcePack
 	t.Run("ActivateResourcePack", func(t *testing.T) {
-		err := service.ActivateResourcePack("12345678-1234-1234-1234-123456789012")
+		err := service.ActivateResourcePack("12345678-1234-1234-1234-123456789012", "")
 		if err != nil {
 			t.Fatal("Failed to activate resource pack:", err)
 		}
@@ -151,7 +153,7 @@ func TestResourcePackService(t *testing.T) {
 
 	// Test ActivateResourcePack with already activated pack
 	t.Run("ActivateResourcePack_AlreadyActivated", func(t *testing.T) {
-		err := service.ActivateResourcePack("12345678-1234-1234-1234-123456789012")
+		err := service.ActivateResourcePack("12345678-1234-1234-1234-123456789012", "")
 		if err == nil {
 			t.Error("Expected error when activating already activated pack")
 		}
@@ -215,7 +217,7 @@ func TestResourcePackService(t *testing.T) {
 
 	// Test with non-existent pack
 	t.Run("ActivateResourcePack_NotFound", func(t *testing.T) {
-		err := service.ActivateResourcePack("non-existent-uuid")
+		err := service.ActivateResourcePack("non-existent-uuid", "")
 		if err == nil {
 			t.Error("Expected error when activating non-existent pack")
 		}
@@ -273,7 +275,7 @@ func TestResourcePackService(t *testing.T) {
 
 		// Test that official packs cannot be activated
 		for _, packName := range officialPacks {
-			err := service.ActivateResourcePack("official-" + packName + "-uuid")
+			err := service.ActivateResourcePack("official-"+packName+"-uuid", "")
 			if err == nil {
 				t.Errorf("Expected error when trying to activate official pack %s", packName)
 			}
@@ -288,3 +290,346 @@ func TestResourcePackService(t *testing.T) {
 		}
 	})
 }
+
+// writePackZip creates a zipped resource pack with the given header UUID and version
+func writePackZip(t *testing.T, dir, name, packUUID string, version [3]int) string {
+	manifest := models.ResourcePackManifest{
+		FormatVersion: 2,
+		Header: models.ResourcePackHeader{
+			Name:    "Upgrade Pack",
+			UUID:    packUUID,
+			Version: version,
+		},
+	}
+	manifestData, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatal("Failed to marshal manifest:", err)
+	}
+
+	zipPath := filepath.Join(dir, name)
+	file, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatal("Failed to create zip file:", err)
+	}
+	defer file.Close()
+
+	writer := zip.NewWriter(file)
+	entry, err := writer.Create("manifest.json")
+	if err != nil {
+		t.Fatal("Failed to add manifest to zip:", err)
+	}
+	entry.Write(manifestData)
+	if err := writer.Close(); err != nil {
+		t.Fatal("Failed to close zip writer:", err)
+	}
+	return zipPath
+}
+
+func TestResourcePackUpgrade(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "bedrock_test")
+	if err != nil {
+		t.Fatal("Failed to create temporary directory:", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	SetBedrockPath(tempDir)
+	SetDataDir(filepath.Join(tempDir, "data"))
+
+	if err := os.WriteFile(filepath.Join(tempDir, "server.properties"), []byte("level-name=test_world\n"), 0644); err != nil {
+		t.Fatal("Failed to write server.properties:", err)
+	}
+	worldPath := filepath.Join(tempDir, "worlds", "test_world")
+	if err := os.MkdirAll(worldPath, 0755); err != nil {
+		t.Fatal("Failed to create world directory:", err)
+	}
+
+	uploadDir := t.TempDir()
+	service := NewResourcePackService()
+	packUUID := "11111111-2222-3333-4444-555555555555"
+
+	readPinnedVersion := func() [3]int {
+		data, err := os.ReadFile(filepath.Join(worldPath, "world_resource_packs.json"))
+		if err != nil {
+			t.Fatal("Failed to read world_resource_packs.json:", err)
+		}
+		var worldPacks []models.WorldResourcePack
+		if err := json.Unmarshal(data, &worldPacks); err != nil {
+			t.Fatal("Failed to unmarshal world_resource_packs.json:", err)
+		}
+		if len(worldPacks) != 1 {
+			t.Fatalf("Expected 1 world pack, got %d", len(worldPacks))
+		}
+		return worldPacks[0].Version
+	}
+
+	zipPath := writePackZip(t, uploadDir, "upgrade_pack.mcpack", packUUID, [3]int{1, 0, 0})
+	if _, err := service.UploadResourcePack(zipPath, "upgrade_pack.mcpack", ""); err != nil {
+		t.Fatal("Failed to upload resource pack:", err)
+	}
+	if err := service.ActivateResourcePack(packUUID, ""); err != nil {
+		t.Fatal("Failed to activate resource pack:", err)
+	}
+
+	t.Run("Upload_RejectsDuplicateUUID", func(t *testing.T) {
+		zipPath := writePackZip(t, uploadDir, "upgrade_pack.mcpack", packUUID, [3]int{1, 1, 0})
+		if _, err := service.UploadResourcePack(zipPath, "upgrade_pack.mcpack", PackConflictReject); err == nil {
+			t.Error("Expected error when uploading a pack that is already installed")
+		}
+	})
+
+	t.Run("Upload_Replace", func(t *testing.T) {
+		zipPath := writePackZip(t, uploadDir, "upgrade_pack.mcpack", packUUID, [3]int{1, 1, 0})
+		info, err := service.UploadResourcePack(zipPath, "upgrade_pack.mcpack", PackConflictReplace)
+		if err != nil {
+			t.Fatal("Failed to replace resource pack:", err)
+		}
+		if info.PreviousVersion == nil || *info.PreviousVersion != [3]int{1, 0, 0} {
+			t.Errorf("Expected previous version 1.0.0, got %v", info.PreviousVersion)
+		}
+		if info.FolderName != "upgrade_pack" {
+			t.Errorf("Expected pack to stay in folder 'upgrade_pack', got '%s'", info.FolderName)
+		}
+		if version := readPinnedVersion(); version != [3]int{1, 1, 0} {
+			t.Errorf("Expected world to pin version 1.1.0, got %v", version)
+		}
+
+		packs, err := service.GetResourcePacks()
+		if err != nil {
+			t.Fatal("Failed to get resource packs:", err)
+		}
+		if len(packs) != 1 {
+			t.Errorf("Expected 1 resource pack after replace, got %d", len(packs))
+		}
+	})
+
+	t.Run("Rollback", func(t *testing.T) {
+		info, err := service.RollbackResourcePack(packUUID)
+		if err != nil {
+			t.Fatal("Failed to roll back resource pack:", err)
+		}
+		if info.Version != [3]int{1, 0, 0} {
+			t.Errorf("Expected version 1.0.0 after rollback, got %v", info.Version)
+		}
+		if version := readPinnedVersion(); version != [3]int{1, 0, 0} {
+			t.Errorf("Expected world to pin version 1.0.0, got %v", version)
+		}
+	})
+
+	t.Run("Upload_KeepBoth", func(t *testing.T) {
+		zipPath := writePackZip(t, uploadDir, "upgrade_pack.mcpack", packUUID, [3]int{2, 0, 0})
+		info, err := service.UploadResourcePack(zipPath, "upgrade_pack.mcpack", PackConflictKeep)
+		if err != nil {
+			t.Fatal("Failed to upload resource pack:", err)
+		}
+		if info.FolderName == "upgrade_pack" {
+			t.Error("Expected kept pack to be extracted into a new folder")
+		}
+
+		packs, err := service.GetResourcePacks()
+		if err != nil {
+			t.Fatal("Failed to get resource packs:", err)
+		}
+		if len(packs) != 2 {
+			t.Errorf("Expected 2 resource packs after keep, got %d", len(packs))
+		}
+	})
+
+	t.Run("Rollback_NoBackup", func(t *testing.T) {
+		if _, err := service.RollbackResourcePack("non-existent-uuid"); err == nil {
+			t.Error("Expected error when rolling back a pack that is not installed")
+		}
+	})
+}
+
+func TestResourcePackSubpacks(t *testing.T) {
+	tempDir := t.TempDir()
+	SetBedrockPath(tempDir)
+	SetDataDir(filepath.Join(tempDir, "data"))
+
+	if err := os.WriteFile(filepath.Join(tempDir, "server.properties"), []byte("level-name=test_world\n"), 0644); err != nil {
+		t.Fatal("Failed to write server.properties:", err)
+	}
+	worldPath := filepath.Join(tempDir, "worlds", "test_world")
+	if err := os.MkdirAll(worldPath, 0755); err != nil {
+		t.Fatal("Failed to create world directory:", err)
+	}
+
+	packUUID := "aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee"
+	packPath := filepath.Join(tempDir, "resource_packs", "hd_pack")
+	if err := os.MkdirAll(packPath, 0755); err != nil {
+		t.Fatal("Failed to create pack directory:", err)
+	}
+	manifest := models.ResourcePackManifest{
+		FormatVersion: 2,
+		Header: models.ResourcePackHeader{
+			Name:    "HD Pack",
+			UUID:    packUUID,
+			Version: [3]int{1, 0, 0},
+		},
+		Subpacks: []models.ResourcePackSubpack{
+			{FolderName: "low_res", Name: "Low", MemoryTier: 0},
+			{FolderName: "high_res", Name: "High", MemoryTier: 2},
+		},
+	}
+	manifestData, _ := json.Marshal(manifest)
+	if err := os.WriteFile(filepath.Join(packPath, "manifest.json"), manifestData, 0644); err != nil {
+		t.Fatal("Failed to write manifest file:", err)
+	}
+
+	service := NewResourcePackService()
+
+	readWorldPacks := func() []models.WorldResourcePack {
+		data, err := os.ReadFile(filepath.Join(worldPath, "world_resource_packs.json"))
+		if err != nil {
+			t.Fatal("Failed to read world_resource_packs.json:", err)
+		}
+		var worldPacks []models.WorldResourcePack
+		if err := json.Unmarshal(data, &worldPacks); err != nil {
+			t.Fatal("Failed to unmarshal world_resource_packs.json:", err)
+		}
+		return worldPacks
+	}
+
+	t.Run("GetResourcePacks_ListsSubpacks", func(t *testing.T) {
+		packs, err := service.GetResourcePacks()
+		if err != nil {
+			t.Fatal("Failed to get resource packs:", err)
+		}
+		if len(packs) != 1 || len(packs[0].Subpacks) != 2 {
+			t.Fatalf("Expected 1 pack with 2 subpacks, got %+v", packs)
+		}
+		if packs[0].Subpacks[1].MemoryTier != 2 {
+			t.Errorf("Expected memory tier 2, got %d", packs[0].Subpacks[1].MemoryTier)
+		}
+	})
+
+	t.Run("Activate_UnknownSubpack", func(t *testing.T) {
+		if err := service.ActivateResourcePack(packUUID, "ultra_res"); err == nil {
+			t.Error("Expected error when activating an unknown subpack")
+		}
+	})
+
+	t.Run("Activate_WithSubpack", func(t *testing.T) {
+		if err := service.ActivateResourcePack(packUUID, "low_res"); err != nil {
+			t.Fatal("Failed to activate resource pack:", err)
+		}
+		worldPacks := readWorldPacks()
+		if len(worldPacks) != 1 || worldPacks[0].Subpack != "low_res" {
+			t.Errorf("Expected subpack 'low_res' in world pack entry, got %+v", worldPacks)
+		}
+	})
+
+	t.Run("Activate_SwitchSubpack", func(t *testing.T) {
+		if err := service.ActivateResourcePack(packUUID, "high_res"); err != nil {
+			t.Fatal("Failed to switch subpack:", err)
+		}
+		worldPacks := readWorldPacks()
+		if len(worldPacks) != 1 || worldPacks[0].Subpack != "high_res" {
+			t.Errorf("Expected subpack 'high_res' in world pack entry, got %+v", worldPacks)
+		}
+
+		packs, err := service.GetResourcePacks()
+		if err != nil {
+			t.Fatal("Failed to get resource packs:", err)
+		}
+		if packs[0].ActiveSubpack != "high_res" {
+			t.Errorf("Expected active subpack 'high_res', got '%s'", packs[0].ActiveSubpack)
+		}
+	})
+}
+
+func TestResourcePackContentLog(t *testing.T) {
+	tempDir := t.TempDir()
+	SetBedrockPath(tempDir)
+	SetDataDir(filepath.Join(tempDir, "data"))
+
+	if err := os.WriteFile(filepath.Join(tempDir, "server.properties"), []byte("level-name=test_world\ntexturepack-required=false\n"), 0644); err != nil {
+		t.Fatal("Failed to write server.properties:", err)
+	}
+
+	packUUID := "99999999-8888-7777-6666-555555555555"
+	packPath := filepath.Join(tempDir, "resource_packs", "broken_pack")
+	if err := os.MkdirAll(packPath, 0755); err != nil {
+		t.Fatal("Failed to create pack directory:", err)
+	}
+	manifestData, _ := json.Marshal(models.ResourcePackManifest{
+		Header: models.ResourcePackHeader{Name: "Broken Pack", UUID: packUUID, Version: [3]int{1, 0, 0}},
+	})
+	if err := os.WriteFile(filepath.Join(packPath, "manifest.json"), manifestData, 0644); err != nil {
+		t.Fatal("Failed to write manifest file:", err)
+	}
+
+	logsPath := filepath.Join(tempDir, "logs")
+	if err := os.MkdirAll(logsPath, 0755); err != nil {
+		t.Fatal("Failed to create logs directory:", err)
+	}
+	contentLog := "18:43:38[Texture][error]-Broken Pack | textures/blocks/stone | missing texture\n" +
+		"18:43:39[Json][warning]-Unknown pack | blocks.json | unexpected field\n" +
+		"  continued detail\n"
+	if err := os.WriteFile(filepath.Join(logsPath, "ContentLog__Thu_Aug_07_2025__18_43_38.txt"), []byte(contentLog), 0644); err != nil {
+		t.Fatal("Failed to write content log:", err)
+	}
+
+	service := NewResourcePackService()
+
+	t.Run("UpdatePackSettings_AddsMissingKeys", func(t *testing.T) {
+		err := service.UpdatePackSettings(models.ResourcePackSettings{TexturepackRequired: true, ContentLogFileEnabled: true}, "test")
+		if err != nil {
+			t.Fatal("Failed to update pack settings:", err)
+		}
+		settings, err := service.GetPackSettings()
+		if err != nil {
+			t.Fatal("Failed to get pack settings:", err)
+		}
+		if !settings.TexturepackRequired || !settings.ContentLogFileEnabled {
+			t.Errorf("Expected both settings enabled, got %+v", settings)
+		}
+	})
+
+	t.Run("GetContentLog_LinksPack", func(t *testing.T) {
+		entries, err := service.GetContentLog(0, "")
+		if err != nil {
+			t.Fatal("Failed to read content log:", err)
+		}
+		if len(entries) != 2 {
+			t.Fatalf("Expected 2 content log entries, got %d", len(entries))
+		}
+		if entries[0].PackUUID != packUUID || entries[0].Category != "Texture" || entries[0].Level != "error" {
+			t.Errorf("Unexpected first entry: %+v", entries[0])
+		}
+		if entries[1].PackUUID != "" {
+			t.Errorf("Expected unmatched entry to have no pack, got '%s'", entries[1].PackUUID)
+		}
+		if entries[1].Message != "Unknown pack | blocks.json | unexpected field\n  continued detail" {
+			t.Errorf("Expected continuation line to be appended, got '%s'", entries[1].Message)
+		}
+	})
+
+	t.Run("MatchPackReference_WholeWords", func(t *testing.T) {
+		packs := []packReference{{Name: "Ore", FolderName: "ore_pack"}, {Name: "UI", FolderName: "ui"}}
+
+		for _, message := range []string{
+			"Core | textures/blocks/ore_block | missing texture",
+			"Build failed | more details",
+		} {
+			if pack := matchPackReference(message, packs); pack != nil {
+				t.Errorf("Expected '%s' to match no pack, got '%s'", message, pack.Name)
+			}
+		}
+		for _, message := range []string{"Ore | blocks.json | unexpected field", "[ui] missing screen"} {
+			if pack := matchPackReference(message, packs); pack == nil {
+				t.Errorf("Expected '%s' to match a pack", message)
+			}
+		}
+	})
+
+	t.Run("GetContentLog_FilterLevel", func(t *testing.T) {
+		entries, err := service.GetContentLog(0, "error")
+		if err != nil {
+			t.Fatal("Failed to read content log:", err)
+		}
+		if len(entries) != 1 {
+			t.Errorf("Expected 1 error entry, got %d", len(entries))
+		}
+	})
+}
@@ -0,0 +1,261 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"minecraft-easyserver/models"
+)
+
+// propertyKeyPattern restricts the keys accepted for properties outside the schema
+var propertyKeyPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// propertyRange returns a pointer for schema min/max values
+func propertyRange(value float64) *float64 {
+	return &value
+}
+
+// serverPropertiesSchema describes every key shipped in bedrock's server.properties,
+// in the order the server writes them
+var serverPropertiesSchema = []models.ServerPropertySchema{
+	{Key: "server-name", Type: models.PropertyTypeString, Default: "Dedicated Server",
+		Description: "Used as the server name shown in the server list."},
+	{Key: "gamemode", Type: models.PropertyTypeEnum, Allowed: []string{"survival", "creative", "adventure"}, Default: "survival",
+		Description: "Sets the game mode for new players."},
+	{Key: "force-gamemode", Type: models.PropertyTypeBool, Default: "false",
+		Description: "Forces players to join in the default game mode instead of the mode saved for them."},
+	{Key: "difficulty", Type: models.PropertyTypeEnum, Allowed: []string{"peaceful", "easy", "normal", "hard"}, Default: "easy",
+		Description: "Sets the difficulty of the world."},
+	{Key: "allow-cheats", Type: models.PropertyTypeBool, Default: "false",
+		Description: "If true then cheats like commands can be used."},
+	{Key: "max-players", Type: models.PropertyTypeInt, Min: propertyRange(1), Max: propertyRange(1000), Default: "10",
+		Description: "The maximum number of players that can play on the server."},
+	{Key: "online-mode", Type: models.PropertyTypeBool, Default: "true",
+		Description: "If true then all connected players must be authenticated to Xbox Live."},
+	{Key: "allow-list", Type: models.PropertyTypeBool, Default: "false",
+		Description: "If true then all connected players must be listed in allowlist.json."},
+	{Key: "server-port", Type: models.PropertyTypeInt, Min: propertyRange(1), Max: propertyRange(65535), Default: "19132",
+		Description: "Which IPv4 port the server should listen to."},
+	{Key: "server-portv6", Type: models.PropertyTypeInt, Min: propertyRange(1), Max: propertyRange(65535), Default: "19133",
+		Description: "Which IPv6 port the server should listen to."},
+	{Key: "enable-lan-visibility", Type: models.PropertyTypeBool, Default: "true",
+		Description: "Listen and respond to clients that are looking for servers on the LAN."},
+	{Key: "view-distance", Type: models.PropertyTypeInt, Min: propertyRange(5), Max: propertyRange(96), Default: "32",
+		Description: "The maximum allowed view distance in number of chunks."},
+	{Key: "tick-distance", Type: models.PropertyTypeInt, Min: propertyRange(4), Max: propertyRange(12), Default: "4",
+		Description: "The world will be ticked this many chunks away from any player."},
+	{Key: "player-idle-timeout", Type: models.PropertyTypeInt, Min: propertyRange(0), Default: "30",
+		Description: "After a player has idled for this many minutes they will be kicked. 0 disables the timeout."},
+	{Key: "max-threads", Type: models.PropertyTypeInt, Min: propertyRange(0), Default: "8",
+		Description: "Maximum number of threads the server will try to use. 0 uses as many as possible."},
+	{Key: "level-name", Type: models.PropertyTypeString, Default: "Bedrock level",
+		Description: "Name of the world folder under worlds/."},
+	{Key: "level-seed", Type: models.PropertyTypeString, Default: "",
+		Description: "Seed used when generating a new world. Empty picks a random seed."},
+	{Key: "default-player-permission-level", Type: models.PropertyTypeEnum, Allowed: []string{"visitor", "member", "operator"}, Default: "member",
+		Description: "Permission level for new players joining for the first time."},
+	{Key: "texturepack-required", Type: models.PropertyTypeBool, Default: "false",
+		Description: "Force clients to use texture packs in the current world."},
+	{Key: "content-log-file-enabled", Type: models.PropertyTypeBool, Default: "false",
+		Description: "Enables logging content errors to a file."},
+	{Key: "content-log-level", Type: models.PropertyTypeEnum, Allowed: []string{"verbose", "info", "warning", "error"}, Default: "info",
+		Description: "Minimum severity of content errors that are logged."},
+	{Key: "content-log-console-output-enabled", Type: models.PropertyTypeBool, Default: "false",
+		Description: "Enables writing content errors to the console."},
+	{Key: "compression-threshold", Type: models.PropertyTypeInt, Min: propertyRange(0), Max: propertyRange(65535), Default: "1",
+		Description: "Determines the smallest size of raw network payload to compress."},
+	{Key: "compression-algorithm", Type: models.PropertyTypeEnum, Allowed: []string{"zlib", "snappy"}, Default: "zlib",
+		Description: "Determines the compression algorithm to use for networking."},
+	{Key: "server-authoritative-movement", Type: models.PropertyTypeEnum, Allowed: []string{"client-auth", "server-auth", "server-auth-with-rewind"}, Default: "server-auth",
+		Description: "Changes how the server validates player movement."},
+	{Key: "player-position-acceptance-threshold", Type: models.PropertyTypeFloat, Min: propertyRange(0), Default: "0.5",
+		Description: "Tolerance of discrepancies between client and server player position."},
+	{Key: "player-movement-action-direction-threshold", Type: models.PropertyTypeFloat, Min: propertyRange(0), Max: propertyRange(1), Default: "0.85",
+		Description: "The amount that the player's attack direction and look direction can differ."},
+	{Key: "player-movement-score-threshold", Type: models.PropertyTypeInt, Min: propertyRange(0), Default: "20",
+		Description: "The number of incongruent time intervals needed before abnormal behavior is reported."},
+	{Key: "player-movement-distance-threshold", Type: models.PropertyTypeFloat, Min: propertyRange(0), Default: "0.3",
+		Description: "The difference between server and client positions that needs to be exceeded before abnormal behavior is detected."},
+	{Key: "player-movement-duration-threshold-in-ms", Type: models.PropertyTypeInt, Min: propertyRange(0), Default: "500",
+		Description: "The duration of time the server and client positions can be out of sync before the abnormal movement score is incremented."},
+	{Key: "correct-player-movement", Type: models.PropertyTypeBool, Default: "false",
+		Description: "If true, the client position will get corrected to the server position if the movement score exceeds the threshold."},
+	{Key: "server-authoritative-block-breaking", Type: models.PropertyTypeBool, Default: "false",
+		Description: "If true, the server will compute block mining operations in sync with the client."},
+	{Key: "server-authoritative-block-breaking-pick-range-scalar", Type: models.PropertyTypeFloat, Min: propertyRange(0), Default: "1.5",
+		Description: "Multiplier applied to the block breaking reach distance check."},
+	{Key: "chat-restriction", Type: models.PropertyTypeEnum, Allowed: []string{"None", "Dropped", "Disabled"}, Default: "None",
+		Description: "Restricts chat: Dropped silently drops messages, Disabled blocks chat for non-operators."},
+	{Key: "disable-player-interaction", Type: models.PropertyTypeBool, Default: "false",
+		Description: "If true, the server will inform clients to ignore other players when interacting with the world."},
+	{Key: "client-side-chunk-generation-enabled", Type: models.PropertyTypeBool, Default: "true",
+		Description: "If true, the server will inform clients that they may generate visual level chunks outside of player interaction distances."},
+	{Key: "block-network-ids-are-hashes", Type: models.PropertyTypeBool, Default: "true",
+		Description: "If true, the server will send hashed block network IDs instead of IDs that start from 0."},
+	{Key: "disable-persona", Type: models.PropertyTypeBool, Default: "false",
+		Description: "Internal use only."},
+	{Key: "disable-custom-skins", Type: models.PropertyTypeBool, Default: "false",
+		Description: "If true, disable players customized skins that were customized outside of the Minecraft store assets."},
+	{Key: "server-build-radius-ratio", Type: models.PropertyTypeString, Default: "Disabled",
+		Description: "\"Disabled\" or a value from 0.0 to 1.0 for the fraction of view distance the server generates for clients."},
+	{Key: "allow-outbound-script-debugging", Type: models.PropertyTypeBool, Default: "false",
+		Description: "Allows script debugger 'connect' command and script-debugger-auto-attach=connect mode."},
+	{Key: "allow-inbound-script-debugging", Type: models.PropertyTypeBool, Default: "false",
+		Description: "Allows script debugger 'listen' command and script-debugger-auto-attach=listen mode."},
+	{Key: "script-debugger-auto-attach", Type: models.PropertyTypeEnum, Allowed: []string{"disabled", "connect", "listen"}, Default: "disabled",
+		Description: "Attempts to attach the script debugger at level load."},
+	{Key: "emit-server-telemetry", Type: models.PropertyTypeBool, Default: "false",
+		Description: "Emits server telemetry."},
+}
+
+// GetServerPropertiesSchema returns the server.properties schema
+func GetServerPropertiesSchema() []models.ServerPropertySchema {
+	return serverPropertiesSchema
+}
+
+// findPropertySchema returns the schema entry for a key, or nil for unknown keys
+func findPropertySchema(key string) *models.ServerPropertySchema {
+	for i := range serverPropertiesSchema {
+		if serverPropertiesSchema[i].Key == key {
+			return &serverPropertiesSchema[i]
+		}
+	}
+	return nil
+}
+
+// typedPropertyValue converts a raw server.properties value to its schema type.
+// Unknown keys and values that don't parse are returned as strings.
+func typedPropertyValue(key, raw string) interface{} {
+	schema := findPropertySchema(key)
+	if schema == nil {
+		return raw
+	}
+
+	switch schema.Type {
+	case models.PropertyTypeBool:
+		if val, err := strconv.ParseBool(raw); err == nil {
+			return val
+		}
+	case models.PropertyTypeInt:
+		if val, err := strconv.Atoi(raw); err == nil {
+			return val
+		}
+	case models.PropertyTypeFloat:
+		if val, err := strconv.ParseFloat(raw, 64); err == nil {
+			return val
+		}
+	}
+	return raw
+}
+
+// defaultServerProperties returns the schema defaults as typed values
+func defaultServerProperties() models.ServerProperties {
+	properties := make(models.ServerProperties, len(serverPropertiesSchema))
+	for _, schema := range serverPropertiesSchema {
+		properties[schema.Key] = typedPropertyValue(schema.Key, schema.Default)
+	}
+	return properties
+}
+
+// validateServerProperties validates values against the schema and returns them
+// formatted for server.properties. Keys outside the schema are kept as plain values.
+func validateServerProperties(values models.ServerProperties) (map[string]string, error) {
+	formatted := make(map[string]string, len(values))
+	var problems []string
+
+	for key, value := range values {
+		raw, err := validatePropertyValue(key, value)
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		formatted[key] = raw
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return nil, fmt.Errorf("invalid configuration: %s", strings.Join(problems, "; "))
+	}
+	return formatted, nil
+}
+
+// validatePropertyValue validates a single value and formats it for server.properties
+func validatePropertyValue(key string, value interface{}) (string, error) {
+	var raw string
+	switch v := value.(type) {
+	case string:
+		raw = strings.TrimSpace(v)
+	case bool:
+		raw = strconv.FormatBool(v)
+	case float64:
+		raw = strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		raw = strconv.Itoa(v)
+	default:
+		return "", fmt.Errorf("%s must be a string, number or boolean", key)
+	}
+
+	// A line break would let a value inject extra keys into the file
+	if strings.ContainsAny(raw, "\r\n") {
+		return "", fmt.Errorf("%s must not contain line breaks", key)
+	}
+
+	schema := findPropertySchema(key)
+	if schema == nil {
+		if !propertyKeyPattern.MatchString(key) {
+			return "", fmt.Errorf("%s is not a valid property name", key)
+		}
+		return raw, nil
+	}
+
+	switch schema.Type {
+	case models.PropertyTypeBool:
+		val, err := strconv.ParseBool(raw)
+		if err != nil {
+			return "", fmt.Errorf("%s must be true or false", key)
+		}
+		return strconv.FormatBool(val), nil
+	case models.PropertyTypeInt:
+		val, err := strconv.ParseFloat(raw, 64)
+		if err != nil || val != math.Trunc(val) {
+			return "", fmt.Errorf("%s must be an integer", key)
+		}
+		if err := checkPropertyRange(schema, val); err != nil {
+			return "", err
+		}
+		return strconv.Itoa(int(val)), nil
+	case models.PropertyTypeFloat:
+		val, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return "", fmt.Errorf("%s must be a number", key)
+		}
+		if err := checkPropertyRange(schema, val); err != nil {
+			return "", err
+		}
+		return strconv.FormatFloat(val, 'f', -1, 64), nil
+	case models.PropertyTypeEnum:
+		for _, allowed := range schema.Allowed {
+			if raw == allowed {
+				return raw, nil
+			}
+		}
+		return "", fmt.Errorf("%s must be one of: %s", key, strings.Join(schema.Allowed, ", "))
+	}
+
+	return raw, nil
+}
+
+// checkPropertyRange checks a numeric value against the schema's min/max
+func checkPropertyRange(schema *models.ServerPropertySchema, val float64) error {
+	if schema.Min != nil && val < *schema.Min {
+		return fmt.Errorf("%s must be at least %v", schema.Key, *schema.Min)
+	}
+	if schema.Max != nil && val > *schema.Max {
+		return fmt.Errorf("%s must be at most %v", schema.Key, *schema.Max)
+	}
+	return nil
+}
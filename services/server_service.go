@@ -9,6 +9,7 @@ import (
 	"sync"
 	"time"
 
+	"minecraft-easyserver/config"
 	"minecraft-easyserver/models"
 )
 
@@ -16,6 +17,7 @@ var (
 	serverProcess *exec.Cmd
 	serverMutex   sync.Mutex
 	bedrockPath   string
+	dataDir       = config.DefaultDataDir
 	logSvc        *LogService
 	interactionSvc *InteractionService
 )
@@ -54,6 +56,11 @@ func GetBedrockPath() string {
 	return bedrockPath
 }
 
+// SetDataDir sets the directory for panel-managed data files (mainly for testing)
+func SetDataDir(path string) {
+	dataDir = path
+}
+
 // ServerService server service
 type ServerService struct{}
 
@@ -88,10 +95,13 @@ func (s *ServerService) GetStatus() models.ServerStatus {
 	// If process has ended, FindProcess will still return a Process object
 	// We can try sending signal 0 to check if process is really running
 	if process != nil {
+		pendingKeys := getRestartPendingKeys()
 		return models.ServerStatus{
-			Status:  "running",
-			Message: "Server is running",
-			PID:     serverProcess.Process.Pid,
+			Status:             "running",
+			Message:            "Server is running",
+			PID:                serverProcess.Process.Pid,
+			RestartPending:     len(pendingKeys) > 0,
+			RestartPendingKeys: pendingKeys,
 		}
 	}
 
@@ -160,10 +170,27 @@ func (s *ServerService) Start() error {
 		return fmt.Errorf("failed to start server: %v", err)
 	}
 
+	// The new process reads the current server.properties
+	clearRestartPending()
+
+	// Keep this session's output on disk
+	if err := NewSessionLogService().StartSession(); err != nil {
+		logSvc.AddLogEntry("WARN", fmt.Sprintf("Failed to start session log: %v", err))
+	}
+
 	// Start log capture
 	logSvc.StartLogCapture(stdout, stderr)
 	logSvc.AddLogEntry("INFO", "Server started successfully")
 
+	// Kick banned players as they join
+	NewBanService().StartWatching()
+
+	NewEventService().Publish(EventTopicStatus, models.ServerStatus{
+		Status:  "running",
+		Message: "Server is running",
+		PID:     serverProcess.Process.Pid,
+	})
+
 	// Start command response capture (if interaction is enabled)
 	if interactionSvc.IsEnabled() {
 		// Note: In a real implementation, you might want to duplicate stdout
@@ -217,6 +244,12 @@ func (s *ServerService) Stop() error {
 	if logSvc != nil {
 		logSvc.AddLogEntry("INFO", "Server stopped")
 	}
+	NewSessionLogService().EndSession()
+
+	NewEventService().Publish(EventTopicStatus, models.ServerStatus{
+		Status:  "stopped",
+		Message: "Server not running",
+	})
 
 	return nil
 }
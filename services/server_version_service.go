@@ -7,6 +7,7 @@ import (
 	"io"
 	"minecraft-easyserver/config"
 	"minecraft-easyserver/models"
+	"minecraft-easyserver/utils"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -363,7 +364,7 @@ func (s *ServerVersionService) saveConfig(path string, config map[interface{}]in
 		return err
 	}
 
-	return os.WriteFile(path, data, 0644)
+	return utils.WriteFileAtomic(path, data, 0644)
 }
 
 // updateProgress updates download progress
@@ -375,6 +376,10 @@ func (s *ServerVersionService) updateProgress(version string, progress float64,
 		s.downloadProgress[version] = &models.DownloadProgress{}
 	}
 
+	// Publish status changes and whole-percent steps rather than every chunk read
+	previous := *s.downloadProgress[version]
+	publish := previous.Status != status || int(previous.Progress) != int(progress)
+
 	s.downloadProgress[version].Version = version
 	s.downloadProgress[version].Progress = progress
 	s.downloadProgress[version].Status = status
@@ -382,6 +387,10 @@ func (s *ServerVersionService) updateProgress(version string, progress float64,
 	s.downloadProgress[version].TotalBytes = totalBytes
 	s.downloadProgress[version].DownloadedBytes = downloadedBytes
 
+	if publish {
+		NewEventService().Publish(EventTopicDownload, *s.downloadProgress[version])
+	}
+
 	// Clean up completed downloads after 30 seconds
 	if status == "completed" || status == "error" {
 		go func() {
@@ -524,7 +533,7 @@ func (s *ServerVersionService) UpdateVersionConfigFromGitHub() error {
 
 	// Write to local file
 	configPath := filepath.Join(configDir, "server_versions.json")
-	err = os.WriteFile(configPath, body, 0644)
+	err = utils.WriteFileAtomic(configPath, body, 0644)
 	if err != nil {
 		return fmt.Errorf("failed to write config file: %v", err)
 	}
@@ -0,0 +1,329 @@
+package services
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"minecraft-easyserver/models"
+	"minecraft-easyserver/utils"
+)
+
+// sessionIDFormat layout of session IDs, taken from the time the server started
+const sessionIDFormat = "20060102-150405"
+
+// sessionIDPattern valid session IDs
+var sessionIDPattern = regexp.MustCompile(`^\d{8}-\d{6}$`)
+
+// SessionLogOptions controls where server output is written and how it is rotated
+type SessionLogOptions struct {
+	Enabled  bool
+	Dir      string
+	Prefix   string
+	MaxSize  int64         // Rotate the session file once it reaches this many bytes
+	MaxAge   time.Duration // Delete files of past sessions older than this; 0 keeps them
+	Compress bool          // Gzip rotated files
+}
+
+// SessionLogService writes server output of each server session to its own log file
+type SessionLogService struct {
+	mutex   sync.Mutex
+	options SessionLogOptions
+	file    *utils.RotatingFile
+	session string
+}
+
+var sessionLogService *SessionLogService
+
+// NewSessionLogService returns the global session log service instance
+func NewSessionLogService() *SessionLogService {
+	if sessionLogService == nil {
+		sessionLogService = &SessionLogService{}
+	}
+	return sessionLogService
+}
+
+// Configure sets the session log options; it applies from the next session
+func (s *SessionLogService) Configure(options SessionLogOptions) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if options.Prefix == "" {
+		options.Prefix = "server"
+	}
+	s.options = options
+}
+
+// StartSession opens a new log file for a server session
+func (s *SessionLogService) StartSession() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.closeSession()
+	if !s.options.Enabled {
+		return nil
+	}
+
+	if err := os.MkdirAll(s.options.Dir, 0755); err != nil {
+		return fmt.Errorf("failed to create log directory: %v", err)
+	}
+
+	session := time.Now().Format(sessionIDFormat)
+	// Sessions started within the same second share an ID; keep appending to it
+	part := 0
+	for _, file := range s.sessionFiles(session) {
+		if file.part > part {
+			part = file.part
+		}
+	}
+
+	return s.openSession(session, part)
+}
+
+// EndSession closes the current session's log file
+func (s *SessionLogService) EndSession() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.closeSession()
+}
+
+// Write appends a log entry to the current session's log file, if there is one
+func (s *SessionLogService) Write(entry models.ServerLogEntry) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.file == nil {
+		return
+	}
+
+	line := fmt.Sprintf("[%s] [%s] %s\n", entry.Timestamp, entry.Level, entry.Message)
+	if _, err := s.file.Write([]byte(line)); err != nil {
+		slog.Warn("Failed to write session log", "error", err)
+	}
+}
+
+// ListSessions lists the sessions that have log files, newest first
+func (s *SessionLogService) ListSessions() ([]models.LogSession, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	files, err := s.logFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make(map[string]*models.LogSession)
+	for _, file := range files {
+		session, exists := sessions[file.session]
+		if !exists {
+			started, _ := time.ParseInLocation(sessionIDFormat, file.session, time.Local)
+			session = &models.LogSession{
+				ID:        file.session,
+				StartedAt: started.Format("2006-01-02 15:04:05"),
+				Active:    file.session == s.session && s.file != nil,
+			}
+			sessions[file.session] = session
+		}
+
+		session.Files++
+		session.Size += file.info.Size()
+		modified := file.info.ModTime().Format("2006-01-02 15:04:05")
+		if modified > session.LastWrittenAt {
+			session.LastWrittenAt = modified
+		}
+	}
+
+	result := make([]models.LogSession, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, *session)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID > result[j].ID
+	})
+	return result, nil
+}
+
+// WriteSession writes the complete, uncompressed log of a session to w
+func (s *SessionLogService) WriteSession(id string, w io.Writer) error {
+	files, err := s.openSessionFiles(id)
+	if err != nil {
+		return err
+	}
+	defer closeFiles(files)
+
+	for _, file := range files {
+		reader, err := logFileReader(file)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(w, reader); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// openSessionFiles opens a session's files in the order they were written. The files
+// are opened while holding the lock; open files stay readable if they are rotated
+// meanwhile, and slow readers don't hold up logging.
+func (s *SessionLogService) openSessionFiles(id string) ([]*os.File, error) {
+	if !sessionIDPattern.MatchString(id) {
+		return nil, fmt.Errorf("invalid session ID: %s", id)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var opened []*os.File
+	for _, file := range s.sessionFiles(id) {
+		f, err := os.Open(filepath.Join(s.options.Dir, file.info.Name()))
+		if err != nil {
+			closeFiles(opened)
+			return nil, err
+		}
+		opened = append(opened, f)
+	}
+
+	if len(opened) == 0 {
+		return nil, fmt.Errorf("session %s not found", id)
+	}
+	return opened, nil
+}
+
+// sessionLogFile a file belonging to a session; part 0 is the active file,
+// rotated files are numbered from 1 in the order they were written
+type sessionLogFile struct {
+	session string
+	part    int
+	info    os.FileInfo
+}
+
+// logFilePattern matches session log file names for the configured prefix
+func (s *SessionLogService) logFilePattern() *regexp.Regexp {
+	return regexp.MustCompile(`^` + regexp.QuoteMeta(s.options.Prefix) + `-(\d{8}-\d{6})(?:\.(\d+))?\.log(\.gz)?$`)
+}
+
+// logFiles returns all session log files; callers must hold the mutex
+func (s *SessionLogService) logFiles() ([]sessionLogFile, error) {
+	if s.options.Dir == "" {
+		return nil, nil
+	}
+
+	entries, err := os.ReadDir(s.options.Dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	pattern := s.logFilePattern()
+	var files []sessionLogFile
+	for _, entry := range entries {
+		matches := pattern.FindStringSubmatch(entry.Name())
+		if matches == nil || entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		part, _ := strconv.Atoi(matches[2])
+		files = append(files, sessionLogFile{session: matches[1], part: part, info: info})
+	}
+	return files, nil
+}
+
+// sessionFiles returns a session's files in the order they were written; callers must hold the mutex
+func (s *SessionLogService) sessionFiles(session string) []sessionLogFile {
+	files, err := s.logFiles()
+	if err != nil {
+		return nil
+	}
+
+	var result []sessionLogFile
+	for _, file := range files {
+		if file.session == session {
+			result = append(result, file)
+		}
+	}
+	// Rotated parts first, oldest first, then the active file
+	sort.Slice(result, func(i, j int) bool {
+		if (result[i].part == 0) != (result[j].part == 0) {
+			return result[j].part == 0
+		}
+		return result[i].part < result[j].part
+	})
+	return result
+}
+
+// activePath returns the path of a session's active file
+func (s *SessionLogService) activePath(session string) string {
+	return filepath.Join(s.options.Dir, fmt.Sprintf("%s-%s.log", s.options.Prefix, session))
+}
+
+// openSession opens the active file of a session, continuing after its rotated part
+// number part; callers must hold the mutex
+func (s *SessionLogService) openSession(session string, part int) error {
+	pattern := s.logFilePattern()
+	file, err := utils.OpenRotatingFile(s.activePath(session), utils.RotatingFileOptions{
+		MaxSize:  s.options.MaxSize,
+		MaxAge:   s.options.MaxAge,
+		Compress: s.options.Compress,
+		// A full file becomes the session's next numbered part
+		RotatedPath: func() string {
+			part++
+			return filepath.Join(s.options.Dir, fmt.Sprintf("%s-%s.%d.log", s.options.Prefix, session, part))
+		},
+		// Files of past sessions expire; the current session's are kept
+		Expires: func(name string) bool {
+			matches := pattern.FindStringSubmatch(name)
+			return matches != nil && matches[1] != session
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to open session log: %v", err)
+	}
+
+	s.file = file
+	s.session = session
+	return nil
+}
+
+// closeSession closes the active file; callers must hold the mutex
+func (s *SessionLogService) closeSession() {
+	if s.file != nil {
+		s.file.Close()
+		s.file = nil
+	}
+	s.session = ""
+}
+
+// logFileReader returns a reader for an open log file, decompressing gzipped files
+func logFileReader(file *os.File) (io.Reader, error) {
+	if !strings.HasSuffix(file.Name(), ".gz") {
+		return file, nil
+	}
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", filepath.Base(file.Name()), err)
+	}
+	return gz, nil
+}
+
+// closeFiles closes every file in files
+func closeFiles(files []*os.File) {
+	for _, file := range files {
+		file.Close()
+	}
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"minecraft-easyserver/models"
+)
+
+func TestSessionLogService(t *testing.T) {
+	dir := t.TempDir()
+	sessions := NewSessionLogService()
+	sessions.Configure(SessionLogOptions{
+		Enabled:  true,
+		Dir:      dir,
+		Prefix:   "server",
+		MaxSize:  200,
+		MaxAge:   24 * time.Hour,
+		Compress: true,
+	})
+	t.Cleanup(func() {
+		sessions.EndSession()
+		sessions.Configure(SessionLogOptions{})
+	})
+
+	// A session that has expired
+	expired := filepath.Join(dir, "server-20200101-000000.log")
+	if err := os.WriteFile(expired, []byte("old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-48 * time.Hour)
+	os.Chtimes(expired, old, old)
+
+	if err := sessions.StartSession(); err != nil {
+		t.Fatal("Failed to start session:", err)
+	}
+
+	t.Run("RemovesExpiredSessions", func(t *testing.T) {
+		if _, err := os.Stat(expired); !os.IsNotExist(err) {
+			t.Error("Expected expired session log to be removed")
+		}
+	})
+
+	var lines []string
+	for i := 0; i < 20; i++ {
+		line := strings.Repeat("x", 20) + " line " + string(rune('a'+i))
+		lines = append(lines, line)
+		sessions.Write(models.ServerLogEntry{Timestamp: "2025-08-07 18:40:12", Level: "INFO", Message: line})
+	}
+
+	list, err := sessions.ListSessions()
+	if err != nil || len(list) != 1 {
+		t.Fatalf("Expected one session, got %+v, %v", list, err)
+	}
+	session := list[0]
+
+	t.Run("RotatesAndCompresses", func(t *testing.T) {
+		if !session.Active || session.Files < 3 {
+			t.Errorf("Expected an active session with rotated files, got %+v", session)
+		}
+		compressed, _ := filepath.Glob(filepath.Join(dir, "server-"+session.ID+".*.log.gz"))
+		if len(compressed) != session.Files-1 {
+			t.Errorf("Expected %d compressed parts, got %v", session.Files-1, compressed)
+		}
+	})
+
+	t.Run("DownloadJoinsParts", func(t *testing.T) {
+		var buffer bytes.Buffer
+		if err := sessions.WriteSession(session.ID, &buffer); err != nil {
+			t.Fatal("Failed to read session:", err)
+		}
+
+		got := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+		if len(got) != len(lines) {
+			t.Fatalf("Expected %d lines, got %d:\n%s", len(lines), len(got), buffer.String())
+		}
+		for i, line := range lines {
+			if got[i] != "[2025-08-07 18:40:12] [INFO] "+line {
+				t.Errorf("Line %d out of order: %s", i, got[i])
+			}
+		}
+	})
+
+	t.Run("InvalidAndMissingSessions", func(t *testing.T) {
+		var buffer bytes.Buffer
+		if err := sessions.WriteSession("../../etc/passwd", &buffer); err == nil || !strings.Contains(err.Error(), "invalid") {
+			t.Errorf("Expected invalid session ID error, got %v", err)
+		}
+		if err := sessions.WriteSession("20000101-000000", &buffer); err == nil || !strings.Contains(err.Error(), "not found") {
+			t.Errorf("Expected not found error, got %v", err)
+		}
+	})
+
+	t.Run("EndSession", func(t *testing.T) {
+		sessions.EndSession()
+		sessions.Write(models.ServerLogEntry{Message: "after stop"})
+
+		list, _ := sessions.ListSessions()
+		if len(list) != 1 || list[0].Active {
+			t.Errorf("Expected the session to be inactive, got %+v", list)
+		}
+		var buffer bytes.Buffer
+		sessions.WriteSession(session.ID, &buffer)
+		if strings.Contains(buffer.String(), "after stop") {
+			t.Error("Expected nothing to be written after the session ended")
+		}
+	})
+}
@@ -0,0 +1,281 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"minecraft-easyserver/models"
+	"minecraft-easyserver/utils"
+)
+
+const (
+	// AccessTokenTTL lifetime of access tokens
+	AccessTokenTTL = 15 * time.Minute
+	// RefreshTokenTTL a session expires if its refresh token isn't used for this long
+	RefreshTokenTTL = 7 * 24 * time.Hour
+)
+
+// sessionTimeFormat format of session timestamps
+const sessionTimeFormat = "2006-01-02 15:04:05"
+
+// storedSession session as saved, with hashes of its refresh tokens
+type storedSession struct {
+	models.Session
+	RefreshHash string `json:"refresh_hash"`
+	// PreviousRefreshHash is the refresh token rotated out last; seeing it again means
+	// the token was copied, so the session is revoked
+	PreviousRefreshHash string `json:"previous_refresh_hash,omitempty"`
+}
+
+// SessionService keeps the server-side login sessions
+type SessionService struct {
+	mutex sync.Mutex
+}
+
+var sessionService *SessionService
+
+// NewSessionService returns the global session service instance
+func NewSessionService() *SessionService {
+	if sessionService == nil {
+		sessionService = &SessionService{}
+	}
+	return sessionService
+}
+
+// sessionsPath returns the file sessions are stored in
+func sessionsPath() string {
+	return filepath.Join(dataDir, "sessions.json")
+}
+
+// CreateSession starts a session for a user and returns it with its refresh token
+func (s *SessionService) CreateSession(userID, ip, userAgent string) (models.Session, string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	sessions, err := readSessions()
+	if err != nil {
+		return models.Session{}, "", err
+	}
+
+	now := time.Now()
+	session := storedSession{
+		Session: models.Session{
+			ID:          newRandomID(),
+			UserID:      userID,
+			IP:          ip,
+			UserAgent:   userAgent,
+			CreatedAt:   now.Format(sessionTimeFormat),
+			RefreshedAt: now.Format(sessionTimeFormat),
+			ExpiresAt:   now.Add(RefreshTokenTTL).Format(sessionTimeFormat),
+		},
+	}
+	refreshToken, err := session.rotate()
+	if err != nil {
+		return models.Session{}, "", err
+	}
+
+	if err := writeSessions(append(sessions, session)); err != nil {
+		return models.Session{}, "", err
+	}
+	return session.Session, refreshToken, nil
+}
+
+// Refresh exchanges a refresh token for a new one, extending its session. Each refresh
+// token works once; presenting a rotated-out token again revokes the session.
+func (s *SessionService) Refresh(refreshToken string) (models.Session, string, error) {
+	id, _, found := strings.Cut(refreshToken, ".")
+	if !found || id == "" {
+		return models.Session{}, "", fmt.Errorf("invalid refresh token")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	sessions, err := readSessions()
+	if err != nil {
+		return models.Session{}, "", err
+	}
+	index := findSession(sessions, id)
+	if index < 0 {
+		return models.Session{}, "", fmt.Errorf("invalid refresh token: session expired or revoked")
+	}
+	session := &sessions[index]
+
+	hash := hashSecretToken(refreshToken)
+	if subtle.ConstantTimeCompare([]byte(hash), []byte(session.RefreshHash)) != 1 {
+		if subtle.ConstantTimeCompare([]byte(hash), []byte(session.PreviousRefreshHash)) == 1 {
+			slog.Warn("Refresh token reused, revoking session", "user_id", session.UserID, "session", session.ID)
+			if err := writeSessions(append(sessions[:index], sessions[index+1:]...)); err != nil {
+				return models.Session{}, "", err
+			}
+			return models.Session{}, "", fmt.Errorf("invalid refresh token: already used, session revoked")
+		}
+		return models.Session{}, "", fmt.Errorf("invalid refresh token")
+	}
+
+	newToken, err := session.rotate()
+	if err != nil {
+		return models.Session{}, "", err
+	}
+	now := time.Now()
+	session.RefreshedAt = now.Format(sessionTimeFormat)
+	session.ExpiresAt = now.Add(RefreshTokenTTL).Format(sessionTimeFormat)
+
+	if err := writeSessions(sessions); err != nil {
+		return models.Session{}, "", err
+	}
+	return session.Session, newToken, nil
+}
+
+// ValidateSession checks that a session exists, belongs to userID and hasn't expired
+func (s *SessionService) ValidateSession(id, userID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	sessions, err := readSessions()
+	if err != nil {
+		return err
+	}
+	index := findSession(sessions, id)
+	if index < 0 || sessions[index].UserID != userID {
+		return fmt.Errorf("session has expired or been revoked")
+	}
+	return nil
+}
+
+// ListSessions lists a user's sessions, newest first
+func (s *SessionService) ListSessions(userID string) ([]models.Session, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	sessions, err := readSessions()
+	if err != nil {
+		return nil, err
+	}
+
+	result := []models.Session{}
+	for i := len(sessions) - 1; i >= 0; i-- {
+		if sessions[i].UserID == userID {
+			result = append(result, sessions[i].Session)
+		}
+	}
+	return result, nil
+}
+
+// RevokeSession revokes one of a user's sessions
+func (s *SessionService) RevokeSession(userID, id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	sessions, err := readSessions()
+	if err != nil {
+		return err
+	}
+	index := findSession(sessions, id)
+	if index < 0 || sessions[index].UserID != userID {
+		return fmt.Errorf("session %s not found", id)
+	}
+	return writeSessions(append(sessions[:index], sessions[index+1:]...))
+}
+
+// RevokeUserSessions revokes all of a user's sessions and returns how many there were
+func (s *SessionService) RevokeUserSessions(userID string) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	sessions, err := readSessions()
+	if err != nil {
+		return 0, err
+	}
+	kept := sessions[:0]
+	for _, session := range sessions {
+		if session.UserID != userID {
+			kept = append(kept, session)
+		}
+	}
+	revoked := len(sessions) - len(kept)
+	if revoked == 0 {
+		return 0, nil
+	}
+	return revoked, writeSessions(kept)
+}
+
+// rotate gives the session a new refresh token, keeping the hash of the old one
+func (session *storedSession) rotate() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate refresh token: %v", err)
+	}
+	token := session.ID + "." + base64.RawURLEncoding.EncodeToString(secret)
+	session.PreviousRefreshHash = session.RefreshHash
+	session.RefreshHash = hashSecretToken(token)
+	return token, nil
+}
+
+// hashSecretToken hashes a refresh or API token for storage. The tokens are random,
+// so a fast unsalted hash is enough.
+func hashSecretToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// readSessions reads the sessions that haven't expired; callers must hold the mutex
+func readSessions() ([]storedSession, error) {
+	data, err := os.ReadFile(sessionsPath())
+	if os.IsNotExist(err) {
+		return []storedSession{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []storedSession
+	if err := json.Unmarshal(data, &sessions); err != nil {
+		return nil, fmt.Errorf("failed to parse sessions: %v", err)
+	}
+
+	now := time.Now()
+	active := sessions[:0]
+	for _, session := range sessions {
+		expiresAt, err := time.ParseInLocation(sessionTimeFormat, session.ExpiresAt, time.Local)
+		if err == nil && now.Before(expiresAt) {
+			active = append(active, session)
+		}
+	}
+	return active, nil
+}
+
+// writeSessions writes the sessions; callers must hold the mutex
+func writeSessions(sessions []storedSession) error {
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(sessions, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Refresh token hashes are only readable by the panel's own user
+	return utils.WriteFileAtomic(sessionsPath(), data, 0600)
+}
+
+// findSession returns the index of the session with id, or -1
+func findSession(sessions []storedSession, id string) int {
+	for i, session := range sessions {
+		if session.ID == id {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"minecraft-easyserver/config"
+	"minecraft-easyserver/models"
+)
+
+func TestSessions(t *testing.T) {
+	originalDataDir := dataDir
+	SetDataDir(t.TempDir())
+	t.Cleanup(func() { SetDataDir(originalDataDir) })
+
+	originalConfig := config.AppConfig
+	config.AppConfig = &config.Config{}
+	config.AppConfig.Auth.JWTSecret = "test-secret"
+	t.Cleanup(func() { config.AppConfig = originalConfig })
+
+	users := NewUserService()
+	sessions := NewSessionService()
+	auth := NewAuthService()
+
+	owner, err := users.CreateUser(models.CreateUserRequest{Username: "owner", Password: "Owner-pass1", Role: models.RoleOwner}, models.User{Role: models.RoleOwner})
+	if err != nil {
+		t.Fatal("Failed to create user:", err)
+	}
+
+	t.Run("LoginAndRefresh", func(t *testing.T) {
+		login, err := auth.Login("owner", "Owner-pass1", "10.0.0.1", "test-agent")
+		if err != nil {
+			t.Fatal("Failed to log in:", err)
+		}
+		if login.RefreshToken == "" || login.ExpiresIn != int64(AccessTokenTTL.Seconds()) {
+			t.Errorf("Expected a refresh token and access token lifetime, got %+v", login)
+		}
+		claims, err := auth.ValidateJWT(login.Token)
+		if err != nil || claims.UserID != owner.ID || claims.SessionID == "" {
+			t.Fatalf("Expected a valid access token for the session, got %+v, %v", claims, err)
+		}
+
+		refreshed, err := auth.Refresh(login.RefreshToken)
+		if err != nil {
+			t.Fatal("Failed to refresh:", err)
+		}
+		if refreshed.RefreshToken == login.RefreshToken {
+			t.Error("Expected the refresh token to be rotated")
+		}
+		newClaims, err := auth.ValidateJWT(refreshed.Token)
+		if err != nil || newClaims.SessionID != claims.SessionID {
+			t.Errorf("Expected the new access token to keep the session, got %+v, %v", newClaims, err)
+		}
+
+		list, _ := sessions.ListSessions(owner.ID)
+		if len(list) != 1 || list[0].IP != "10.0.0.1" || list[0].UserAgent != "test-agent" {
+			t.Errorf("Expected one session with client details, got %+v", list)
+		}
+	})
+
+	t.Run("ReusedRefreshTokenRevokesSession", func(t *testing.T) {
+		session, first, err := sessions.CreateSession(owner.ID, "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, second, err := sessions.Refresh(first)
+		if err != nil {
+			t.Fatal("Failed to refresh:", err)
+		}
+
+		if _, _, err := sessions.Refresh(first); err == nil || !strings.Contains(err.Error(), "session revoked") {
+			t.Errorf("Expected reusing a refresh token to revoke the session, got %v", err)
+		}
+		if _, _, err := sessions.Refresh(second); err == nil {
+			t.Error("Expected the latest refresh token to stop working too")
+		}
+		if err := sessions.ValidateSession(session.ID, owner.ID); err == nil {
+			t.Error("Expected the session to be revoked")
+		}
+		if _, _, err := sessions.Refresh("garbage"); err == nil || !strings.Contains(err.Error(), "invalid refresh token") {
+			t.Errorf("Expected a malformed token to be rejected, got %v", err)
+		}
+	})
+
+	t.Run("RevokeSession", func(t *testing.T) {
+		login, _ := auth.Login("owner", "Owner-pass1", "", "")
+		claims, _ := auth.ValidateJWT(login.Token)
+
+		if err := sessions.RevokeSession("someone-else", claims.SessionID); err == nil || !strings.Contains(err.Error(), "not found") {
+			t.Errorf("Expected other users' sessions to be hidden, got %v", err)
+		}
+		if err := sessions.RevokeSession(owner.ID, claims.SessionID); err != nil {
+			t.Fatal("Failed to revoke session:", err)
+		}
+		if _, err := auth.ValidateJWT(login.Token); err == nil {
+			t.Error("Expected the access token of a revoked session to be rejected")
+		}
+		if _, err := auth.Refresh(login.RefreshToken); err == nil {
+			t.Error("Expected the refresh token of a revoked session to be rejected")
+		}
+	})
+
+	t.Run("PasswordChangeRevokesAll", func(t *testing.T) {
+		first, _ := auth.Login("owner", "Owner-pass1", "", "")
+		second, _ := auth.Login("owner", "Owner-pass1", "", "")
+
+		if err := users.ChangePassword(owner.ID, "Owner-pass1", "Owner-pass2"); err != nil {
+			t.Fatal("Failed to change password:", err)
+		}
+		for _, login := range []*models.LoginResponse{first, second} {
+			if _, err := auth.ValidateJWT(login.Token); err == nil {
+				t.Error("Expected sessions to end after a password change")
+			}
+		}
+	})
+
+	t.Run("RoleChangeRevokesAll", func(t *testing.T) {
+		viewer, err := users.CreateUser(models.CreateUserRequest{Username: "viewer", Password: "Viewer-pass1", Role: models.RoleViewer}, owner)
+		if err != nil {
+			t.Fatal(err)
+		}
+		login, _ := auth.Login("viewer", "Viewer-pass1", "", "")
+		other, _ := auth.Login("owner", "Owner-pass2", "", "")
+
+		if _, err := users.UpdateUser(viewer.ID, models.UpdateUserRequest{Role: models.RoleModerator}, owner); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := auth.ValidateJWT(login.Token); err == nil {
+			t.Error("Expected the user's sessions to end after a role change")
+		}
+		if _, err := auth.ValidateJWT(other.Token); err != nil {
+			t.Error("Expected other users' sessions to be kept:", err)
+		}
+
+		count, err := sessions.RevokeUserSessions(owner.ID)
+		if err != nil || count != 1 {
+			t.Errorf("Expected to log out everywhere, got %d, %v", count, err)
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"minecraft-easyserver/models"
+)
+
+// streamAuthCheckInterval how often an open WebSocket or event stream checks that its
+// user may still use it
+const streamAuthCheckInterval = 10 * time.Second
+
+// StreamAuth identifies who opened a long-lived connection such as a WebSocket or
+// event stream, so their access can be checked again while it stays open
+type StreamAuth struct {
+	UserID     string
+	SessionID  string // Session of a login; empty for API tokens
+	APITokenID string // API token used instead of a login
+}
+
+// Check looks the user up again and checks that their session or API token is still
+// valid and their role still grants permission. It returns the user as they are now.
+func (a StreamAuth) Check(permission string) (models.User, error) {
+	user, err := NewUserService().GetUser(a.UserID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return models.User{}, fmt.Errorf("user account no longer exists")
+		}
+		return models.User{}, err
+	}
+
+	if a.APITokenID != "" {
+		err = NewAPITokenService().ValidateToken(a.APITokenID, a.UserID)
+	} else {
+		err = NewSessionService().ValidateSession(a.SessionID, a.UserID)
+	}
+	if err != nil {
+		return models.User{}, err
+	}
+
+	if !RoleHasPermission(user.Role, permission) {
+		return models.User{}, fmt.Errorf("requires '%s' permission", permission)
+	}
+	return user, nil
+}
+
+// Watch checks access periodically until done is closed, and calls revoke once
+// the user may no longer use the connection
+func (a StreamAuth) Watch(permission string, done <-chan struct{}, revoke func()) {
+	ticker := time.NewTicker(streamAuthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := a.Check(permission); err != nil {
+				revoke()
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
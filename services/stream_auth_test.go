@@ -0,0 +1,60 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"minecraft-easyserver/models"
+)
+
+func TestStreamAuth(t *testing.T) {
+	originalDataDir := dataDir
+	SetDataDir(t.TempDir())
+	t.Cleanup(func() { SetDataDir(originalDataDir) })
+
+	owner := models.User{Role: models.RoleOwner}
+
+	t.Run("Session", func(t *testing.T) {
+		auth := newStreamAuth(t, "stream-admin", models.RoleAdmin)
+		if user, err := auth.Check(models.PermissionModerate); err != nil || user.Role != models.RoleAdmin {
+			t.Fatalf("Expected access for a valid session, got %+v, %v", user, err)
+		}
+
+		// A role change applies to connections that are already open
+		if _, err := NewUserService().UpdateUser(auth.UserID, models.UpdateUserRequest{Role: models.RoleViewer}, owner); err != nil {
+			t.Fatal("Failed to change role:", err)
+		}
+		if _, err := auth.Check(models.PermissionModerate); err == nil {
+			t.Error("Expected the new role to apply")
+		}
+
+		if err := NewUserService().DeleteUser(auth.UserID, owner); err != nil {
+			t.Fatal("Failed to delete user:", err)
+		}
+		if _, err := auth.Check(models.PermissionView); err == nil || !strings.Contains(err.Error(), "no longer exists") {
+			t.Errorf("Expected a deleted user to lose access, got %v", err)
+		}
+	})
+
+	t.Run("APIToken", func(t *testing.T) {
+		user, err := NewUserService().CreateUser(models.CreateUserRequest{Username: "stream-bot", Password: "Stream-pass1", Role: models.RoleModerator}, owner)
+		if err != nil {
+			t.Fatal("Failed to create user:", err)
+		}
+		token, _, err := NewAPITokenService().CreateToken(user, models.CreateAPITokenRequest{Name: "events", Scopes: []string{models.ScopeServerRead}})
+		if err != nil {
+			t.Fatal("Failed to create token:", err)
+		}
+
+		auth := StreamAuth{UserID: user.ID, APITokenID: token.ID}
+		if _, err := auth.Check(models.PermissionView); err != nil {
+			t.Fatal("Expected access for a valid API token:", err)
+		}
+		if err := NewAPITokenService().RevokeToken(user.ID, token.ID); err != nil {
+			t.Fatal("Failed to revoke token:", err)
+		}
+		if _, err := auth.Check(models.PermissionView); err == nil || !strings.Contains(err.Error(), "revoked") {
+			t.Errorf("Expected a revoked API token to lose access, got %v", err)
+		}
+	})
+}
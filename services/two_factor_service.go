@@ -0,0 +1,256 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"sync"
+	"time"
+
+	"minecraft-easyserver/models"
+	"minecraft-easyserver/utils"
+)
+
+const (
+	// twoFactorIssuer name authenticator apps show for the panel
+	twoFactorIssuer = "Minecraft Easy Server"
+	// twoFactorChallengeTTL time allowed between the password and the code
+	twoFactorChallengeTTL = 5 * time.Minute
+	// twoFactorMaxAttempts codes a challenge accepts before the password is needed again
+	twoFactorMaxAttempts = 5
+	// recoveryCodeCount recovery codes issued at a time
+	recoveryCodeCount = 10
+)
+
+// twoFactorChallenge login waiting for its second step
+type twoFactorChallenge struct {
+	userID             string
+	mustChangePassword bool
+	expiresAt          time.Time
+	attempts           int
+}
+
+// TwoFactorService handles TOTP enrolment and the second login step
+type TwoFactorService struct {
+	mutex      sync.Mutex
+	challenges map[string]*twoFactorChallenge
+	users      *UserService
+}
+
+var twoFactorService *TwoFactorService
+
+// NewTwoFactorService returns the global two-factor service instance
+func NewTwoFactorService() *TwoFactorService {
+	if twoFactorService == nil {
+		twoFactorService = &TwoFactorService{
+			challenges: make(map[string]*twoFactorChallenge),
+			users:      NewUserService(),
+		}
+	}
+	return twoFactorService
+}
+
+// Status reports whether a user has two-factor authentication enabled and how many
+// recovery codes they have left
+func (t *TwoFactorService) Status(userID string) (bool, int, error) {
+	user, err := t.users.readUser(userID)
+	if err != nil {
+		return false, 0, err
+	}
+	return user.TwoFactorEnabled, len(user.RecoveryCodes), nil
+}
+
+// BeginSetup generates a new secret for a user to add to their authenticator app. It
+// takes effect once Enable confirms a code from it.
+func (t *TwoFactorService) BeginSetup(userID string) (models.TwoFactorSetup, error) {
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return models.TwoFactorSetup{}, fmt.Errorf("failed to generate secret: %v", err)
+	}
+
+	user, err := t.users.updateUser(userID, func(user *storedUser) error {
+		if user.TwoFactorEnabled {
+			return fmt.Errorf("two-factor authentication is already enabled")
+		}
+		user.TOTPPendingSecret = secret
+		return nil
+	})
+	if err != nil {
+		return models.TwoFactorSetup{}, err
+	}
+
+	return models.TwoFactorSetup{
+		Secret:          secret,
+		ProvisioningURI: utils.TOTPProvisioningURI(twoFactorIssuer, user.Username, secret),
+	}, nil
+}
+
+// Enable turns on two-factor authentication once code shows the user's app has the
+// pending secret, and returns the user's recovery codes
+func (t *TwoFactorService) Enable(userID, code string) ([]string, error) {
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = t.users.updateUser(userID, func(user *storedUser) error {
+		if user.TwoFactorEnabled {
+			return fmt.Errorf("two-factor authentication is already enabled")
+		}
+		if user.TOTPPendingSecret == "" {
+			return fmt.Errorf("invalid request: start two-factor setup first")
+		}
+		step, ok := utils.ValidateTOTP(user.TOTPPendingSecret, code, time.Now())
+		if !ok {
+			return fmt.Errorf("invalid two-factor code")
+		}
+
+		user.TOTPSecret = user.TOTPPendingSecret
+		user.TOTPPendingSecret = ""
+		user.TOTPLastStep = step
+		user.RecoveryCodes = hashes
+		user.TwoFactorEnabled = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Disable turns off two-factor authentication after checking the password and a code
+func (t *TwoFactorService) Disable(userID, password, code string) error {
+	_, err := t.users.updateUser(userID, func(user *storedUser) error {
+		if !user.TwoFactorEnabled {
+			return fmt.Errorf("invalid request: two-factor authentication is not enabled")
+		}
+		if ok, _ := utils.VerifyPassword(password, user.PasswordHash); !ok {
+			return fmt.Errorf("invalid password")
+		}
+		if err := verifySecondFactor(user, code); err != nil {
+			return err
+		}
+
+		user.TwoFactorEnabled = false
+		user.TOTPSecret = ""
+		user.TOTPLastStep = 0
+		user.RecoveryCodes = nil
+		return nil
+	})
+	return err
+}
+
+// RegenerateRecoveryCodes replaces a user's recovery codes after checking a code
+func (t *TwoFactorService) RegenerateRecoveryCodes(userID, code string) ([]string, error) {
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = t.users.updateUser(userID, func(user *storedUser) error {
+		if !user.TwoFactorEnabled {
+			return fmt.Errorf("invalid request: two-factor authentication is not enabled")
+		}
+		if err := verifySecondFactor(user, code); err != nil {
+			return err
+		}
+		user.RecoveryCodes = hashes
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// CreateChallenge starts the second login step for a user whose password was accepted
+func (t *TwoFactorService) CreateChallenge(userID string, mustChangePassword bool) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate challenge: %v", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := time.Now()
+	for key, challenge := range t.challenges {
+		if now.After(challenge.expiresAt) {
+			delete(t.challenges, key)
+		}
+	}
+	t.challenges[token] = &twoFactorChallenge{
+		userID:             userID,
+		mustChangePassword: mustChangePassword,
+		expiresAt:          now.Add(twoFactorChallengeTTL),
+	}
+	return token, nil
+}
+
+// CompleteChallenge checks the code for a login challenge. It returns the user the
+// challenge belongs to and whether they must change their password.
+func (t *TwoFactorService) CompleteChallenge(token, code string) (string, bool, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	challenge, exists := t.challenges[token]
+	if !exists || time.Now().After(challenge.expiresAt) {
+		delete(t.challenges, token)
+		return "", false, fmt.Errorf("invalid or expired challenge, log in again")
+	}
+
+	_, err := t.users.updateUser(challenge.userID, func(user *storedUser) error {
+		return verifySecondFactor(user, code)
+	})
+	if err != nil {
+		challenge.attempts++
+		if challenge.attempts >= twoFactorMaxAttempts {
+			delete(t.challenges, token)
+		}
+		return "", false, err
+	}
+
+	delete(t.challenges, token)
+	return challenge.userID, challenge.mustChangePassword, nil
+}
+
+// verifySecondFactor checks a TOTP code or uses up a recovery code. TOTP codes are
+// only accepted once, by remembering the last time step used.
+func verifySecondFactor(user *storedUser, code string) error {
+	if !user.TwoFactorEnabled || user.TOTPSecret == "" {
+		return fmt.Errorf("invalid two-factor code: two-factor authentication is not enabled")
+	}
+	if step, ok := utils.ValidateTOTP(user.TOTPSecret, code, time.Now()); ok {
+		if step <= user.TOTPLastStep {
+			return fmt.Errorf("invalid two-factor code: already used")
+		}
+		user.TOTPLastStep = step
+		return nil
+	}
+
+	hash := hashSecretToken(utils.NormalizeRecoveryCode(code))
+	for i, stored := range user.RecoveryCodes {
+		if subtle.ConstantTimeCompare([]byte(hash), []byte(stored)) == 1 {
+			user.RecoveryCodes = append(user.RecoveryCodes[:i], user.RecoveryCodes[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid two-factor code")
+}
+
+// generateRecoveryCodes generates a set of recovery codes and their hashes
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		code, err := utils.GenerateRecoveryCode()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery codes: %v", err)
+		}
+		codes[i] = code
+		hashes[i] = hashSecretToken(utils.NormalizeRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"minecraft-easyserver/config"
+	"minecraft-easyserver/models"
+	"minecraft-easyserver/utils"
+)
+
+func TestTwoFactor(t *testing.T) {
+	originalDataDir := dataDir
+	SetDataDir(t.TempDir())
+	t.Cleanup(func() { SetDataDir(originalDataDir) })
+
+	originalConfig := config.AppConfig
+	config.AppConfig = &config.Config{}
+	config.AppConfig.Auth.JWTSecret = "test-secret"
+	t.Cleanup(func() { config.AppConfig = originalConfig })
+
+	users := NewUserService()
+	twoFactor := NewTwoFactorService()
+	auth := NewAuthService()
+
+	owner, err := users.CreateUser(models.CreateUserRequest{Username: "carol", Password: "Carol-pass1", Role: models.RoleOwner}, models.User{Role: models.RoleOwner})
+	if err != nil {
+		t.Fatal("Failed to create user:", err)
+	}
+
+	var secret string
+	var recoveryCodes []string
+	t.Run("Enable", func(t *testing.T) {
+		if _, err := twoFactor.Enable(owner.ID, "123456"); err == nil || !strings.Contains(err.Error(), "setup first") {
+			t.Errorf("Expected enabling without setup to fail, got %v", err)
+		}
+
+		setup, err := twoFactor.BeginSetup(owner.ID)
+		if err != nil {
+			t.Fatal("Failed to start setup:", err)
+		}
+		if !strings.Contains(setup.ProvisioningURI, "secret="+setup.Secret) || !strings.Contains(setup.ProvisioningURI, ":carol?") {
+			t.Errorf("Unexpected provisioning URI: %s", setup.ProvisioningURI)
+		}
+		secret = setup.Secret
+
+		if _, err := twoFactor.Enable(owner.ID, "000000x"); err == nil || !strings.Contains(err.Error(), "invalid two-factor code") {
+			t.Errorf("Expected a wrong code to be rejected, got %v", err)
+		}
+
+		code, _ := utils.TOTPCode(secret, time.Now())
+		recoveryCodes, err = twoFactor.Enable(owner.ID, code)
+		if err != nil {
+			t.Fatal("Failed to enable two-factor authentication:", err)
+		}
+		enabled, remaining, _ := twoFactor.Status(owner.ID)
+		if !enabled || remaining != recoveryCodeCount || len(recoveryCodes) != recoveryCodeCount {
+			t.Errorf("Expected two-factor authentication with %d recovery codes, got %v, %d", recoveryCodeCount, enabled, remaining)
+		}
+		if _, err := twoFactor.BeginSetup(owner.ID); err == nil || !strings.Contains(err.Error(), "already enabled") {
+			t.Errorf("Expected setup to fail once enabled, got %v", err)
+		}
+	})
+
+	t.Run("Login", func(t *testing.T) {
+		login, err := auth.Login("carol", "Carol-pass1", "10.0.0.1", "test-agent")
+		if err != nil {
+			t.Fatal("Failed to log in:", err)
+		}
+		if !login.TwoFactorRequired || login.ChallengeToken == "" || login.Token != "" || login.RefreshToken != "" {
+			t.Fatalf("Expected a challenge and no tokens, got %+v", login)
+		}
+
+		// The code used to enable two-factor authentication can't be replayed
+		used, _ := utils.TOTPCode(secret, time.Now())
+		if _, err := auth.LoginTwoFactor(login.ChallengeToken, used, "10.0.0.1", "test-agent"); err == nil || !strings.Contains(err.Error(), "invalid two-factor code") {
+			t.Errorf("Expected a used code to be rejected, got %v", err)
+		}
+
+		next, _ := utils.TOTPCode(secret, time.Now().Add(30*time.Second))
+		response, err := auth.LoginTwoFactor(login.ChallengeToken, next, "10.0.0.1", "test-agent")
+		if err != nil {
+			t.Fatal("Failed to complete login:", err)
+		}
+		if claims, err := auth.ValidateJWT(response.Token); err != nil || claims.UserID != owner.ID || response.RefreshToken == "" {
+			t.Errorf("Expected a session for the user, got %+v, %v", response, err)
+		}
+		if response.User == nil || !response.User.TwoFactorEnabled {
+			t.Errorf("Expected the user to show two-factor authentication, got %+v", response.User)
+		}
+
+		if _, err := auth.LoginTwoFactor(login.ChallengeToken, recoveryCodes[0], "", ""); err == nil || !strings.Contains(err.Error(), "expired challenge") {
+			t.Errorf("Expected a challenge to work only once, got %v", err)
+		}
+	})
+
+	t.Run("AttemptLimit", func(t *testing.T) {
+		challenge, err := twoFactor.CreateChallenge(owner.ID, false)
+		if err != nil {
+			t.Fatal("Failed to create challenge:", err)
+		}
+		for i := 0; i < twoFactorMaxAttempts; i++ {
+			if _, _, err := twoFactor.CompleteChallenge(challenge, "000000x"); err == nil {
+				t.Fatal("Expected a wrong code to be rejected")
+			}
+		}
+		if _, _, err := twoFactor.CompleteChallenge(challenge, recoveryCodes[0]); err == nil || !strings.Contains(err.Error(), "expired challenge") {
+			t.Errorf("Expected the challenge to be dropped after %d attempts, got %v", twoFactorMaxAttempts, err)
+		}
+	})
+
+	t.Run("RecoveryCode", func(t *testing.T) {
+		challenge, _ := twoFactor.CreateChallenge(owner.ID, true)
+		userID, mustChange, err := twoFactor.CompleteChallenge(challenge, " "+strings.ToUpper(recoveryCodes[0])+" ")
+		if err != nil || userID != owner.ID || !mustChange {
+			t.Fatalf("Expected a recovery code to complete the challenge, got %q, %v, %v", userID, mustChange, err)
+		}
+		if _, remaining, _ := twoFactor.Status(owner.ID); remaining != recoveryCodeCount-1 {
+			t.Errorf("Expected the recovery code to be used up, %d left", remaining)
+		}
+
+		challenge, _ = twoFactor.CreateChallenge(owner.ID, false)
+		if _, _, err := twoFactor.CompleteChallenge(challenge, recoveryCodes[0]); err == nil {
+			t.Error("Expected a recovery code to work only once")
+		}
+
+		replaced, err := twoFactor.RegenerateRecoveryCodes(owner.ID, recoveryCodes[1])
+		if err != nil || len(replaced) != recoveryCodeCount {
+			t.Fatalf("Failed to regenerate recovery codes: %v", err)
+		}
+		if _, _, err := twoFactor.CompleteChallenge(challenge, recoveryCodes[2]); err == nil {
+			t.Error("Expected old recovery codes to stop working")
+		}
+		recoveryCodes = replaced
+	})
+
+	t.Run("Disable", func(t *testing.T) {
+		if err := twoFactor.Disable(owner.ID, "wrong", recoveryCodes[0]); err == nil || !strings.Contains(err.Error(), "invalid password") {
+			t.Errorf("Expected a wrong password to be rejected, got %v", err)
+		}
+		if err := twoFactor.Disable(owner.ID, "Carol-pass1", recoveryCodes[0]); err != nil {
+			t.Fatal("Failed to disable two-factor authentication:", err)
+		}
+		if enabled, remaining, _ := twoFactor.Status(owner.ID); enabled || remaining != 0 {
+			t.Errorf("Expected two-factor authentication to be off, got %v, %d", enabled, remaining)
+		}
+
+		login, err := auth.Login("carol", "Carol-pass1", "", "")
+		if err != nil || login.TwoFactorRequired || login.Token == "" {
+			t.Errorf("Expected a single step login, got %+v, %v", login, err)
+		}
+	})
+}
@@ -0,0 +1,594 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"minecraft-easyserver/config"
+	"minecraft-easyserver/models"
+	"minecraft-easyserver/utils"
+)
+
+// DefaultUsername account created from the original single panel password
+const DefaultUsername = "admin"
+
+// legacyDefaultPassword password older versions gave every fresh installation. Accounts
+// still using it are asked to change it after their hash is upgraded.
+const legacyDefaultPassword = "admin123"
+
+// usernamePattern valid usernames
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{3,32}$`)
+
+// rolePermissions permissions granted by each role
+var rolePermissions = map[string][]string{
+	models.RoleOwner:     {models.PermissionView, models.PermissionModerate, models.PermissionControl, models.PermissionManage, models.PermissionUsers},
+	models.RoleAdmin:     {models.PermissionView, models.PermissionModerate, models.PermissionControl, models.PermissionManage, models.PermissionUsers},
+	models.RoleModerator: {models.PermissionView, models.PermissionModerate},
+	models.RoleViewer:    {models.PermissionView},
+}
+
+// roleRanks orders roles; users can only manage accounts ranked below their own,
+// except owners, who can manage everyone
+var roleRanks = map[string]int{
+	models.RoleOwner:     4,
+	models.RoleAdmin:     3,
+	models.RoleModerator: 2,
+	models.RoleViewer:    1,
+}
+
+// RoleHasPermission reports whether role grants permission
+func RoleHasPermission(role, permission string) bool {
+	for _, granted := range rolePermissions[role] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// storedUser user account as saved, with its password hash
+type storedUser struct {
+	models.User
+	PasswordHash       string `json:"password_hash"`
+	MustChangePassword bool   `json:"must_change_password,omitempty"`
+
+	// Two-factor authentication; the pending secret waits for a first code to confirm it
+	TOTPSecret        string   `json:"totp_secret,omitempty"`
+	TOTPPendingSecret string   `json:"totp_pending_secret,omitempty"`
+	TOTPLastStep      int64    `json:"totp_last_step,omitempty"`
+	RecoveryCodes     []string `json:"recovery_codes,omitempty"` // Hashes of unused codes
+}
+
+// dummyPasswordHash is checked against when a username doesn't exist, so unknown
+// and known usernames take as long to reject
+var (
+	dummyPasswordHash     string
+	dummyPasswordHashOnce sync.Once
+)
+
+// UserService manages panel user accounts
+type UserService struct {
+	mutex sync.Mutex
+}
+
+var userService *UserService
+
+// NewUserService returns the global user service instance
+func NewUserService() *UserService {
+	if userService == nil {
+		userService = &UserService{}
+	}
+	return userService
+}
+
+// usersPath returns the file user accounts are stored in
+func usersPath() string {
+	return filepath.Join(dataDir, "users.json")
+}
+
+// Init loads the user accounts, creating the owner account on first start
+func (s *UserService) Init() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, err := s.loadUsers()
+	return err
+}
+
+// ListUsers lists all user accounts
+func (s *UserService) ListUsers() ([]models.User, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	users, err := s.loadUsers()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.User, len(users))
+	for i, user := range users {
+		result[i] = user.User
+	}
+	return result, nil
+}
+
+// GetUser gets a user account by ID
+func (s *UserService) GetUser(id string) (models.User, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	users, err := s.loadUsers()
+	if err != nil {
+		return models.User{}, err
+	}
+	for _, user := range users {
+		if user.ID == id {
+			return user.User, nil
+		}
+	}
+	return models.User{}, fmt.Errorf("user %s not found", id)
+}
+
+// Authenticate checks a username and password. It also reports whether the account
+// must change its password before using the panel. Legacy SHA-256 hashes are
+// replaced with argon2id hashes on the first successful login.
+func (s *UserService) Authenticate(username, password string) (models.User, bool, error) {
+	user, found, err := s.readUserByName(username)
+	if err != nil {
+		return models.User{}, false, err
+	}
+
+	// argon2id is slow on purpose, so passwords are checked without holding the mutex
+	// that every authenticated request needs
+	if !found {
+		dummyPasswordHashOnce.Do(func() {
+			dummyPasswordHash, _ = utils.HashPassword(legacyDefaultPassword)
+		})
+		utils.VerifyPassword(password, dummyPasswordHash)
+		return models.User{}, false, fmt.Errorf("invalid username or password")
+	}
+
+	ok, needsRehash := utils.VerifyPassword(password, user.PasswordHash)
+	if !ok {
+		return models.User{}, false, fmt.Errorf("invalid username or password")
+	}
+	if !needsRehash {
+		return user.User, user.MustChangePassword, nil
+	}
+
+	mustChangePassword := user.MustChangePassword ||
+		(utils.IsLegacyPasswordHash(user.PasswordHash) && password == legacyDefaultPassword)
+	hash, err := utils.HashPassword(password)
+	if err != nil {
+		slog.Warn("Failed to upgrade password hash", "user", user.Username, "error", err)
+		return user.User, mustChangePassword, nil
+	}
+	if err := s.replacePasswordHash(user.ID, user.PasswordHash, hash, mustChangePassword); err != nil {
+		slog.Warn("Failed to save upgraded password hash", "user", user.Username, "error", err)
+	} else {
+		slog.Info("Upgraded password hash", "user", user.Username)
+	}
+	return user.User, mustChangePassword, nil
+}
+
+// CreateUser creates a user account on behalf of actor
+func (s *UserService) CreateUser(request models.CreateUserRequest, actor models.User) (models.User, error) {
+	if !usernamePattern.MatchString(request.Username) {
+		return models.User{}, fmt.Errorf("invalid username: use 3-32 letters, digits, '.', '_' or '-'")
+	}
+	if err := checkRoleAssignment(actor, request.Role); err != nil {
+		return models.User{}, err
+	}
+	if err := NewAuthService().ValidatePasswordStrength(request.Password); err != nil {
+		return models.User{}, fmt.Errorf("invalid password: %v", err)
+	}
+	passwordHash, err := utils.HashPassword(request.Password)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	users, err := s.loadUsers()
+	if err != nil {
+		return models.User{}, err
+	}
+	for _, user := range users {
+		if strings.EqualFold(user.Username, request.Username) {
+			return models.User{}, fmt.Errorf("username %s already exists", request.Username)
+		}
+	}
+
+	now := time.Now().Format("2006-01-02 15:04:05")
+	user := storedUser{
+		User: models.User{
+			ID:        newRandomID(),
+			Username:  request.Username,
+			Role:      request.Role,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		PasswordHash: passwordHash,
+	}
+	if err := saveUsers(append(users, user)); err != nil {
+		return models.User{}, err
+	}
+	return user.User, nil
+}
+
+// UpdateUser changes the role and/or password of a user account on behalf of actor
+func (s *UserService) UpdateUser(id string, request models.UpdateUserRequest, actor models.User) (models.User, error) {
+	if request.Role == "" && request.Password == "" {
+		return models.User{}, fmt.Errorf("invalid request: nothing to update")
+	}
+	// Resetting a password skips the current password check, so users change their own
+	// through ChangePassword, which also needs a login session rather than an API token
+	if request.Password != "" && id == actor.ID {
+		return models.User{}, fmt.Errorf("not allowed to reset your own password, use change password instead")
+	}
+	passwordHash := ""
+	if request.Password != "" {
+		if err := NewAuthService().ValidatePasswordStrength(request.Password); err != nil {
+			return models.User{}, fmt.Errorf("invalid password: %v", err)
+		}
+		var err error
+		if passwordHash, err = utils.HashPassword(request.Password); err != nil {
+			return models.User{}, err
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	users, err := s.loadUsers()
+	if err != nil {
+		return models.User{}, err
+	}
+	index := findUser(users, id)
+	if index < 0 {
+		return models.User{}, fmt.Errorf("user %s not found", id)
+	}
+	user := &users[index]
+
+	if err := checkUserManagement(actor, user.User); err != nil {
+		return models.User{}, err
+	}
+	revoke := false
+	if request.Role != "" && request.Role != user.Role {
+		if user.ID == actor.ID {
+			return models.User{}, fmt.Errorf("not allowed to change your own role")
+		}
+		if err := checkRoleAssignment(actor, request.Role); err != nil {
+			return models.User{}, err
+		}
+		if user.Role == models.RoleOwner && countOwners(users) == 1 {
+			return models.User{}, fmt.Errorf("not allowed to remove the last owner")
+		}
+		user.Role = request.Role
+		revoke = true
+	}
+	if passwordHash != "" {
+		user.PasswordHash = passwordHash
+		revoke = true
+	}
+	user.UpdatedAt = time.Now().Format("2006-01-02 15:04:05")
+
+	if err := saveUsers(users); err != nil {
+		return models.User{}, err
+	}
+	// A new role or password logs the user out everywhere
+	if revoke {
+		revokeSessions(user.ID)
+	}
+	return user.User, nil
+}
+
+// DeleteUser deletes a user account on behalf of actor
+func (s *UserService) DeleteUser(id string, actor models.User) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	users, err := s.loadUsers()
+	if err != nil {
+		return err
+	}
+	index := findUser(users, id)
+	if index < 0 {
+		return fmt.Errorf("user %s not found", id)
+	}
+
+	if id == actor.ID {
+		return fmt.Errorf("not allowed to delete your own account")
+	}
+	if err := checkUserManagement(actor, users[index].User); err != nil {
+		return err
+	}
+	if users[index].Role == models.RoleOwner && countOwners(users) == 1 {
+		return fmt.Errorf("not allowed to remove the last owner")
+	}
+
+	if err := saveUsers(append(users[:index], users[index+1:]...)); err != nil {
+		return err
+	}
+	revokeSessions(id)
+	if _, err := NewAPITokenService().RevokeUserTokens(id); err != nil {
+		slog.Warn("Failed to revoke API tokens", "user_id", id, "error", err)
+	}
+	return nil
+}
+
+// ChangePassword changes a user's own password after checking the current one
+func (s *UserService) ChangePassword(id, currentPassword, newPassword string) error {
+	user, err := s.readUser(id)
+	if err != nil {
+		return err
+	}
+
+	if ok, _ := utils.VerifyPassword(currentPassword, user.PasswordHash); !ok {
+		return fmt.Errorf("invalid current password")
+	}
+	if err := NewAuthService().ValidatePasswordStrength(newPassword); err != nil {
+		return fmt.Errorf("invalid password: %v", err)
+	}
+	passwordHash, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	users, err := s.loadUsers()
+	if err != nil {
+		return err
+	}
+	index := findUser(users, id)
+	if index < 0 {
+		return fmt.Errorf("user %s not found", id)
+	}
+	// The password was checked without the mutex; it mustn't have changed since
+	if users[index].PasswordHash != user.PasswordHash {
+		return fmt.Errorf("invalid current password")
+	}
+	users[index].PasswordHash = passwordHash
+	users[index].MustChangePassword = false
+	users[index].UpdatedAt = time.Now().Format("2006-01-02 15:04:05")
+	if err := saveUsers(users); err != nil {
+		return err
+	}
+	// Sessions that may have been opened with the old password end here
+	revokeSessions(id)
+	return nil
+}
+
+// readUserByName reads a stored user account by its case-insensitive username
+func (s *UserService) readUserByName(username string) (storedUser, bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	users, err := s.loadUsers()
+	if err != nil {
+		return storedUser{}, false, err
+	}
+	for _, user := range users {
+		if strings.EqualFold(user.Username, username) {
+			return user, true, nil
+		}
+	}
+	return storedUser{}, false, nil
+}
+
+// replacePasswordHash saves an upgraded hash of the same password, unless the password
+// was changed since oldHash was read
+func (s *UserService) replacePasswordHash(id, oldHash, newHash string, mustChangePassword bool) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	users, err := s.loadUsers()
+	if err != nil {
+		return err
+	}
+	index := findUser(users, id)
+	if index < 0 || users[index].PasswordHash != oldHash {
+		return fmt.Errorf("password of user %s changed during login", id)
+	}
+	users[index].PasswordHash = newHash
+	users[index].MustChangePassword = mustChangePassword
+	return saveUsers(users)
+}
+
+// readUser reads a stored user account
+func (s *UserService) readUser(id string) (storedUser, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	users, err := s.loadUsers()
+	if err != nil {
+		return storedUser{}, err
+	}
+	index := findUser(users, id)
+	if index < 0 {
+		return storedUser{}, fmt.Errorf("user %s not found", id)
+	}
+	return users[index], nil
+}
+
+// updateUser applies change to a stored user account and saves it if change succeeds
+func (s *UserService) updateUser(id string, change func(user *storedUser) error) (models.User, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	users, err := s.loadUsers()
+	if err != nil {
+		return models.User{}, err
+	}
+	index := findUser(users, id)
+	if index < 0 {
+		return models.User{}, fmt.Errorf("user %s not found", id)
+	}
+
+	if err := change(&users[index]); err != nil {
+		return models.User{}, err
+	}
+	users[index].UpdatedAt = time.Now().Format("2006-01-02 15:04:05")
+	if err := saveUsers(users); err != nil {
+		return models.User{}, err
+	}
+	return users[index].User, nil
+}
+
+// loadUsers reads the user accounts. The first time, the owner account is created
+// from the password in config.yml so existing logins keep working; without one, a
+// one-time bootstrap password is generated and printed. Callers must hold the mutex.
+func (s *UserService) loadUsers() ([]storedUser, error) {
+	data, err := os.ReadFile(usersPath())
+	if err == nil {
+		var users []storedUser
+		if err := json.Unmarshal(data, &users); err != nil {
+			return nil, fmt.Errorf("failed to parse users: %v", err)
+		}
+		clearLegacyPassword()
+		return users, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	owner := storedUser{
+		User: models.User{
+			ID:       newRandomID(),
+			Username: DefaultUsername,
+			Role:     models.RoleOwner,
+		},
+	}
+	bootstrapPassword := ""
+	if config.AppConfig != nil && config.AppConfig.Auth.Password != "" {
+		owner.PasswordHash = config.AppConfig.Auth.Password
+	} else {
+		if bootstrapPassword, err = utils.GeneratePassword(); err != nil {
+			return nil, fmt.Errorf("failed to generate bootstrap password: %v", err)
+		}
+		if owner.PasswordHash, err = utils.HashPassword(bootstrapPassword); err != nil {
+			return nil, err
+		}
+		owner.MustChangePassword = true
+	}
+	owner.CreatedAt = time.Now().Format("2006-01-02 15:04:05")
+	owner.UpdatedAt = owner.CreatedAt
+
+	users := []storedUser{owner}
+	if err := saveUsers(users); err != nil {
+		return nil, err
+	}
+	clearLegacyPassword()
+
+	if bootstrapPassword != "" {
+		// Printed directly rather than logged, so it never ends up in log files
+		fmt.Fprintf(os.Stderr, "\n"+
+			"==============================================================\n"+
+			"  Created panel account %q with a one-time password:\n\n"+
+			"      %s\n\n"+
+			"  Log in and change it now; it will not be shown again.\n"+
+			"==============================================================\n\n",
+			DefaultUsername, bootstrapPassword)
+		slog.Warn("Created the owner account with a bootstrap password printed to the console", "user", DefaultUsername)
+	}
+	return users, nil
+}
+
+// clearLegacyPassword removes the unsalted password hash of older versions from
+// config.yml once users.json holds the owner account created from it
+func clearLegacyPassword() {
+	if config.AppConfig == nil || config.AppConfig.Auth.Password == "" {
+		return
+	}
+	if err := config.ClearLegacyPassword(); err != nil {
+		slog.Warn("Failed to remove the old password hash from config.yml, remove auth.password by hand", "error", err)
+		// The account no longer needs it, so don't try again on every load
+		config.AppConfig.Auth.Password = ""
+		return
+	}
+	slog.Info("Removed the old password hash from config.yml")
+}
+
+// saveUsers writes the user accounts; callers must hold the mutex
+func saveUsers(users []storedUser) error {
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(users, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Password hashes are only readable by the panel's own user
+	return utils.WriteFileAtomic(usersPath(), data, 0600)
+}
+
+// revokeSessions ends all of a user's sessions after their account changed
+func revokeSessions(userID string) {
+	if count, err := NewSessionService().RevokeUserSessions(userID); err != nil {
+		slog.Warn("Failed to revoke sessions", "user_id", userID, "error", err)
+	} else if count > 0 {
+		slog.Info("Revoked sessions", "user_id", userID, "count", count)
+	}
+}
+
+// checkUserManagement checks that actor may change target's account
+func checkUserManagement(actor, target models.User) error {
+	if actor.ID == target.ID || actor.Role == models.RoleOwner || roleRanks[target.Role] < roleRanks[actor.Role] {
+		return nil
+	}
+	return fmt.Errorf("not allowed to manage %s accounts", target.Role)
+}
+
+// checkRoleAssignment checks that role exists and actor may give it to someone
+func checkRoleAssignment(actor models.User, role string) error {
+	if _, exists := roleRanks[role]; !exists {
+		return fmt.Errorf("invalid role: %s (use owner, admin, moderator or viewer)", role)
+	}
+	if actor.Role != models.RoleOwner && roleRanks[role] >= roleRanks[actor.Role] {
+		return fmt.Errorf("not allowed to assign the %s role", role)
+	}
+	return nil
+}
+
+// findUser returns the index of the user with id, or -1
+func findUser(users []storedUser, id string) int {
+	for i, user := range users {
+		if user.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// countOwners counts the owner accounts
+func countOwners(users []storedUser) int {
+	count := 0
+	for _, user := range users {
+		if user.Role == models.RoleOwner {
+			count++
+		}
+	}
+	return count
+}
+
+// newRandomID generates a random ID for users and sessions
+func newRandomID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,236 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"minecraft-easyserver/config"
+	"minecraft-easyserver/models"
+)
+
+func TestUserService(t *testing.T) {
+	originalDataDir := dataDir
+	SetDataDir(t.TempDir())
+	t.Cleanup(func() { SetDataDir(originalDataDir) })
+
+	users := NewUserService()
+
+	t.Run("BootstrapPassword", func(t *testing.T) {
+		if err := users.Init(); err != nil {
+			t.Fatal("Failed to create owner:", err)
+		}
+		info, err := os.Stat(usersPath())
+		if err != nil || info.Mode().Perm() != 0600 {
+			t.Errorf("Expected users.json to be private, got %v, %v", info, err)
+		}
+
+		data, _ := os.ReadFile(usersPath())
+		var stored []storedUser
+		if err := json.Unmarshal(data, &stored); err != nil || len(stored) != 1 {
+			t.Fatalf("Expected one stored owner, got %s", data)
+		}
+		if stored[0].Role != models.RoleOwner || !stored[0].MustChangePassword || !strings.HasPrefix(stored[0].PasswordHash, "$argon2id$") {
+			t.Errorf("Expected an owner with a bootstrap argon2id hash, got %+v", stored[0])
+		}
+		if _, _, err := users.Authenticate("admin", legacyDefaultPassword); err == nil {
+			t.Error("Expected the old default password not to work on a fresh installation")
+		}
+	})
+
+	var owner models.User
+	t.Run("MigratesLegacyHash", func(t *testing.T) {
+		// An installation upgraded from the single password, still on admin123
+		sum := sha256.Sum256([]byte(legacyDefaultPassword))
+		legacy := []storedUser{{
+			User:         models.User{ID: "owner1", Username: DefaultUsername, Role: models.RoleOwner},
+			PasswordHash: hex.EncodeToString(sum[:]),
+		}}
+		if err := saveUsers(legacy); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, _, err := users.Authenticate("admin", "wrong"); err == nil {
+			t.Error("Expected a wrong password to be rejected")
+		}
+		user, mustChange, err := users.Authenticate("ADMIN", legacyDefaultPassword)
+		if err != nil {
+			t.Fatal("Expected the legacy hash to be accepted:", err)
+		}
+		if user.Role != models.RoleOwner || !mustChange {
+			t.Errorf("Expected an owner that must change the default password, got %+v, %v", user, mustChange)
+		}
+		owner = user
+
+		data, _ := os.ReadFile(usersPath())
+		if !strings.Contains(string(data), "$argon2id$") || strings.Contains(string(data), legacy[0].PasswordHash) {
+			t.Errorf("Expected the hash to be upgraded, got %s", data)
+		}
+		if _, mustChange, err := users.Authenticate("admin", legacyDefaultPassword); err != nil || !mustChange {
+			t.Errorf("Expected the upgraded hash to work and still require a change, got %v, %v", mustChange, err)
+		}
+	})
+
+	var admin, moderator models.User
+	t.Run("CreateUser", func(t *testing.T) {
+		var err error
+		admin, err = users.CreateUser(models.CreateUserRequest{Username: "alice", Password: "Alice-pass1", Role: models.RoleAdmin}, owner)
+		if err != nil {
+			t.Fatal("Failed to create admin:", err)
+		}
+		moderator, err = users.CreateUser(models.CreateUserRequest{Username: "bob", Password: "Bob-pass12", Role: models.RoleModerator}, admin)
+		if err != nil {
+			t.Fatal("Failed to create moderator:", err)
+		}
+
+		cases := []struct {
+			request models.CreateUserRequest
+			actor   models.User
+			want    string
+		}{
+			{models.CreateUserRequest{Username: "Alice", Password: "Alice-pass1", Role: models.RoleViewer}, owner, "already exists"},
+			{models.CreateUserRequest{Username: "a b", Password: "Pass-word1", Role: models.RoleViewer}, owner, "invalid username"},
+			{models.CreateUserRequest{Username: "carol", Password: "Pass-word1", Role: "guest"}, owner, "invalid role"},
+			{models.CreateUserRequest{Username: "carol", Password: "Pass-word1", Role: models.RoleAdmin}, admin, "not allowed"},
+			{models.CreateUserRequest{Username: "carol", Password: "short", Role: models.RoleViewer}, owner, "invalid password"},
+		}
+		for _, tc := range cases {
+			if _, err := users.CreateUser(tc.request, tc.actor); err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("Expected %q for %+v, got %v", tc.want, tc.request, err)
+			}
+		}
+
+		list, _ := users.ListUsers()
+		if len(list) != 3 {
+			t.Errorf("Expected 3 users, got %d", len(list))
+		}
+		if _, _, err := users.Authenticate("bob", "Bob-pass12"); err != nil {
+			t.Error("Expected the new user to log in:", err)
+		}
+	})
+
+	t.Run("UpdateUser", func(t *testing.T) {
+		if _, err := users.UpdateUser(admin.ID, models.UpdateUserRequest{Role: models.RoleViewer}, moderator); err == nil || !strings.Contains(err.Error(), "not allowed") {
+			t.Errorf("Expected a moderator to be unable to manage an admin, got %v", err)
+		}
+		if _, err := users.UpdateUser(admin.ID, models.UpdateUserRequest{Role: models.RoleOwner}, admin); err == nil || !strings.Contains(err.Error(), "own role") {
+			t.Errorf("Expected users to be unable to change their own role, got %v", err)
+		}
+		if _, err := users.UpdateUser(owner.ID, models.UpdateUserRequest{Role: models.RoleAdmin}, owner); err == nil {
+			t.Error("Expected the owner to be unable to demote themselves")
+		}
+		if _, err := users.UpdateUser(owner.ID, models.UpdateUserRequest{Password: "Taken-over1"}, owner); err == nil || !strings.Contains(err.Error(), "own password") {
+			t.Errorf("Expected users to be unable to reset their own password, got %v", err)
+		}
+		if _, _, err := users.Authenticate(owner.Username, "Taken-over1"); err == nil {
+			t.Error("Expected the password to be unchanged")
+		}
+
+		updated, err := users.UpdateUser(moderator.ID, models.UpdateUserRequest{Role: models.RoleViewer}, admin)
+		if err != nil || updated.Role != models.RoleViewer {
+			t.Errorf("Expected the admin to demote the moderator, got %+v, %v", updated, err)
+		}
+		if _, err := users.UpdateUser(moderator.ID, models.UpdateUserRequest{}, admin); err == nil || !strings.Contains(err.Error(), "nothing to update") {
+			t.Errorf("Expected an empty update to be rejected, got %v", err)
+		}
+	})
+
+	t.Run("LastOwner", func(t *testing.T) {
+		second, err := users.CreateUser(models.CreateUserRequest{Username: "dave", Password: "Dave-pass1", Role: models.RoleOwner}, owner)
+		if err != nil {
+			t.Fatal("Failed to create second owner:", err)
+		}
+		if _, err := users.UpdateUser(owner.ID, models.UpdateUserRequest{Role: models.RoleAdmin}, second); err != nil {
+			t.Fatal("Expected an owner to demote another owner:", err)
+		}
+		if err := users.DeleteUser(second.ID, second); err == nil || !strings.Contains(err.Error(), "your own account") {
+			t.Errorf("Expected users to be unable to delete themselves, got %v", err)
+		}
+		owner, _ = users.GetUser(owner.ID)
+		if err := users.DeleteUser(second.ID, owner); err == nil || !strings.Contains(err.Error(), "not allowed") {
+			t.Errorf("Expected an admin to be unable to delete an owner, got %v", err)
+		}
+	})
+
+	t.Run("DeleteUser", func(t *testing.T) {
+		if err := users.DeleteUser(moderator.ID, admin); err != nil {
+			t.Fatal("Failed to delete user:", err)
+		}
+		if _, err := users.GetUser(moderator.ID); err == nil || !strings.Contains(err.Error(), "not found") {
+			t.Errorf("Expected the deleted user to be gone, got %v", err)
+		}
+		if err := users.DeleteUser(moderator.ID, admin); err == nil || !strings.Contains(err.Error(), "not found") {
+			t.Errorf("Expected deleting again to fail with not found, got %v", err)
+		}
+	})
+
+	t.Run("ChangePassword", func(t *testing.T) {
+		if err := users.ChangePassword(admin.ID, "wrong", "New-pass12"); err == nil || !strings.Contains(err.Error(), "current password") {
+			t.Errorf("Expected the current password to be checked, got %v", err)
+		}
+		if err := users.ChangePassword(admin.ID, "Alice-pass1", "New-pass12"); err != nil {
+			t.Fatal("Failed to change password:", err)
+		}
+		if _, _, err := users.Authenticate("alice", "Alice-pass1"); err == nil {
+			t.Error("Expected the old password to stop working")
+		}
+		if _, _, err := users.Authenticate("alice", "New-pass12"); err != nil {
+			t.Error("Expected the new password to work:", err)
+		}
+
+		if err := users.ChangePassword(owner.ID, legacyDefaultPassword, "Owner-pass1"); err != nil {
+			t.Fatal("Failed to change owner password:", err)
+		}
+		if _, mustChange, err := users.Authenticate("admin", "Owner-pass1"); err != nil || mustChange {
+			t.Errorf("Expected the password change requirement to be cleared, got %v, %v", mustChange, err)
+		}
+	})
+}
+
+func TestRoleHasPermission(t *testing.T) {
+	if !RoleHasPermission(models.RoleModerator, models.PermissionModerate) || RoleHasPermission(models.RoleModerator, models.PermissionControl) {
+		t.Error("Unexpected moderator permissions")
+	}
+	if !RoleHasPermission(models.RoleViewer, models.PermissionView) || RoleHasPermission(models.RoleViewer, models.PermissionModerate) {
+		t.Error("Unexpected viewer permissions")
+	}
+	if RoleHasPermission("guest", models.PermissionView) {
+		t.Error("Expected unknown roles to have no permissions")
+	}
+}
+
+func TestLegacyConfigPassword(t *testing.T) {
+	originalDataDir := dataDir
+	SetDataDir(t.TempDir())
+	t.Cleanup(func() { SetDataDir(originalDataDir) })
+
+	// A config.yml of an older version with the single panel password
+	sum := sha256.Sum256([]byte("Legacy-pass1"))
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+	content := "server:\n    port: 8080\nauth:\n    password: " + hex.EncodeToString(sum[:]) + "\n    jwt_secret: keep-me\nbedrock:\n    executable: bedrock_server\n"
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	originalConfig := config.AppConfig
+	t.Cleanup(func() { config.AppConfig = originalConfig })
+	if err := config.LoadConfig(configPath); err != nil {
+		t.Fatal("Failed to load config:", err)
+	}
+
+	if _, _, err := NewUserService().Authenticate("admin", "Legacy-pass1"); err != nil {
+		t.Fatal("Expected the password from config.yml to work:", err)
+	}
+
+	data, _ := os.ReadFile(configPath)
+	if strings.Contains(string(data), "password") || !strings.Contains(string(data), "jwt_secret: keep-me") {
+		t.Errorf("Expected only the password hash to be removed from config.yml, got %s", data)
+	}
+	if config.AppConfig.Auth.Password != "" {
+		t.Error("Expected the password hash to be cleared from the loaded configuration")
+	}
+}
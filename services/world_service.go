@@ -28,7 +28,7 @@ func (w *WorldService) GetWorlds() ([]models.WorldInfo, error) {
 }
 
 // DeleteWorld deletes world
-func (w *WorldService) DeleteWorld(worldName string) error {
+func (w *WorldService) DeleteWorld(worldName, user string) error {
 	if worldName == "" {
 		return fmt.Errorf("world name cannot be empty")
 	}
@@ -57,8 +57,7 @@ func (w *WorldService) DeleteWorld(worldName string) error {
 	}
 
 	if config.LevelName == worldName {
-		config.LevelName = "Bedrock level"
-		if err := writeServerProperties(configPath, config); err != nil {
+		if _, err := setServerProperties(configPath, map[string]string{"level-name": "Bedrock level"}, user); err != nil {
 			return err
 		}
 	}
@@ -67,7 +66,7 @@ func (w *WorldService) DeleteWorld(worldName string) error {
 }
 
 // ActivateWorld activates world
-func (w *WorldService) ActivateWorld(worldName string) error {
+func (w *WorldService) ActivateWorld(worldName, user string) error {
 	if worldName == "" {
 		return fmt.Errorf("world name cannot be empty")
 	}
@@ -79,13 +78,12 @@ func (w *WorldService) ActivateWorld(worldName string) error {
 
 	// Update level-name in server.properties
 	configPath := filepath.Join(bedrockPath, "server.properties")
-	config, err := readServerProperties(configPath)
-	if err != nil {
+	if _, err := os.Stat(configPath); err != nil {
 		return err
 	}
 
-	config.LevelName = worldName
-	return writeServerProperties(configPath, config)
+	_, err := setServerProperties(configPath, map[string]string{"level-name": worldName}, user)
+	return err
 }
 
 // Get world list
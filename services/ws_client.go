@@ -0,0 +1,125 @@
+package services
+
+import (
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// wsWriteWait time allowed to write a message to a WebSocket client
+	wsWriteWait = 10 * time.Second
+	// wsPongWait time allowed between messages or pongs before a client is considered gone
+	wsPongWait = 60 * time.Second
+	// wsPingPeriod how often WebSocket clients are pinged; must be below wsPongWait
+	wsPingPeriod = 30 * time.Second
+	// wsReadLimit largest message accepted from a WebSocket client
+	wsReadLimit = 4096
+)
+
+// wsClient a connected WebSocket with a bounded send queue. Messages are written by
+// the client's own goroutine so a slow client can't hold up the others; a client whose
+// queue is full is disconnected.
+type wsClient struct {
+	kind      string // Kind of WebSocket, for log messages
+	conn      *websocket.Conn
+	send      chan []byte
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// newWSClient wraps a connection with a queue of queueSize messages and starts writing
+func newWSClient(kind string, conn *websocket.Conn, queueSize int) *wsClient {
+	c := &wsClient{
+		kind: kind,
+		conn: conn,
+		send: make(chan []byte, queueSize),
+		done: make(chan struct{}),
+	}
+	go c.writePump()
+	return c
+}
+
+// enqueue queues a message for the client without blocking
+func (c *wsClient) enqueue(data []byte) {
+	select {
+	case <-c.done:
+		return
+	default:
+	}
+
+	select {
+	case c.send <- data:
+	default:
+		slog.Warn(c.kind+" client is not keeping up, disconnecting", "remote_addr", c.conn.RemoteAddr().String())
+		c.close()
+	}
+}
+
+// enqueueJSON queues a message encoded as JSON
+func (c *wsClient) enqueueJSON(message interface{}) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		slog.Warn("Failed to encode "+c.kind+" message", "error", err)
+		return
+	}
+	c.enqueue(data)
+}
+
+// readPump passes each message from the client to handle until the connection closes.
+// Reading is also needed to process pongs and notice disconnects.
+func (c *wsClient) readPump(handle func(data []byte)) {
+	c.conn.SetReadLimit(wsReadLimit)
+	c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		// Any message from the client shows it is still there
+		c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		if handle != nil {
+			handle(data)
+		}
+	}
+}
+
+// writePump is the only goroutine writing to the connection
+func (c *wsClient) writePump() {
+	ticker := time.NewTicker(wsPingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case data := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := c.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				c.close()
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				c.close()
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// close closes the connection once; the read and write pumps then exit
+func (c *wsClient) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.conn.Close()
+	})
+}
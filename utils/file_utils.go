@@ -70,4 +70,73 @@ func ExtractZip(src, dest string) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// Hooks for the steps of WriteFileAtomic, replaced in tests to simulate failures
+var (
+	writeTempFile = func(f *os.File, data []byte) (int, error) { return f.Write(data) }
+	syncTempFile  = func(f *os.File) error { return f.Sync() }
+	renameFile    = os.Rename
+)
+
+// WriteFileAtomic writes data to path without ever leaving a truncated file behind.
+// The data goes to a temp file in the same directory which is fsynced and then
+// renamed over path. If path already exists its previous content is kept as path.bak.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	previous, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	// Back up the previous version first so one always exists, whichever step fails
+	if err == nil {
+		if err := writeAndRename(path+".bak", previous, perm); err != nil {
+			return fmt.Errorf("failed to back up %s: %v", filepath.Base(path), err)
+		}
+	}
+
+	return writeAndRename(path, data, perm)
+}
+
+// writeAndRename writes data to a synced temp file next to path and renames it into place
+func writeAndRename(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tempFile, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %v", err)
+	}
+	tempPath := tempFile.Name()
+
+	// Remove the temp file on any failure so the directory stays clean
+	success := false
+	defer func() {
+		if !success {
+			tempFile.Close()
+			os.Remove(tempPath)
+		}
+	}()
+
+	if _, err := writeTempFile(tempFile, data); err != nil {
+		return fmt.Errorf("failed to write temp file: %v", err)
+	}
+	if err := syncTempFile(tempFile); err != nil {
+		return fmt.Errorf("failed to sync temp file: %v", err)
+	}
+	if err := tempFile.Close(); err != nil {
+		return fmt.Errorf("failed to close temp file: %v", err)
+	}
+	if err := os.Chmod(tempPath, perm); err != nil {
+		return fmt.Errorf("failed to set file permissions: %v", err)
+	}
+	if err := renameFile(tempPath, path); err != nil {
+		return fmt.Errorf("failed to replace %s: %v", filepath.Base(path), err)
+	}
+	success = true
+
+	// Persist the rename itself; directories can't be synced on every platform
+	if dirFile, err := os.Open(dir); err == nil {
+		dirFile.Sync()
+		dirFile.Close()
+	}
+	return nil
+}
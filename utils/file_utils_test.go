@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// restoreHooks puts the real WriteFileAtomic steps back after a test replaced them
+func restoreHooks(t *testing.T) {
+	originalWrite, originalSync, originalRename := writeTempFile, syncTempFile, renameFile
+	t.Cleanup(func() {
+		writeTempFile, syncTempFile, renameFile = originalWrite, originalSync, originalRename
+	})
+}
+
+// assertNoTempFiles checks that a failed write didn't leave temp files behind
+func assertNoTempFiles(t *testing.T, dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal("Failed to read directory:", err)
+	}
+	for _, entry := range entries {
+		if strings.Contains(entry.Name(), ".tmp-") {
+			t.Errorf("Temp file left behind: %s", entry.Name())
+		}
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "server.properties")
+
+	t.Run("CreatesNewFile", func(t *testing.T) {
+		if err := WriteFileAtomic(path, []byte("level-name=first\n"), 0644); err != nil {
+			t.Fatal("Failed to write file:", err)
+		}
+		data, _ := os.ReadFile(path)
+		if string(data) != "level-name=first\n" {
+			t.Errorf("Unexpected content: %q", data)
+		}
+		if _, err := os.Stat(path + ".bak"); !os.IsNotExist(err) {
+			t.Error("Expected no backup for a newly created file")
+		}
+	})
+
+	t.Run("KeepsBackupOfPreviousVersion", func(t *testing.T) {
+		if err := WriteFileAtomic(path, []byte("level-name=second\n"), 0644); err != nil {
+			t.Fatal("Failed to write file:", err)
+		}
+		data, _ := os.ReadFile(path)
+		if string(data) != "level-name=second\n" {
+			t.Errorf("Unexpected content: %q", data)
+		}
+		backup, _ := os.ReadFile(path + ".bak")
+		if string(backup) != "level-name=first\n" {
+			t.Errorf("Expected backup of previous version, got %q", backup)
+		}
+		assertNoTempFiles(t, dir)
+	})
+
+	failures := []struct {
+		name  string
+		setup func()
+	}{
+		{"WriteFails", func() {
+			writeTempFile = func(f *os.File, data []byte) (int, error) {
+				// Simulate a full disk after a partial write
+				f.Write(data[:len(data)/2])
+				return len(data) / 2, errors.New("no space left on device")
+			}
+		}},
+		{"SyncFails", func() {
+			syncTempFile = func(f *os.File) error { return errors.New("input/output error") }
+		}},
+		{"RenameFails", func() {
+			renameFile = func(oldPath, newPath string) error {
+				if newPath == path {
+					return errors.New("rename failed")
+				}
+				return os.Rename(oldPath, newPath)
+			}
+		}},
+	}
+
+	for _, failure := range failures {
+		t.Run(failure.name, func(t *testing.T) {
+			restoreHooks(t)
+			failure.setup()
+
+			if err := WriteFileAtomic(path, []byte("level-name=broken\n"), 0644); err == nil {
+				t.Fatal("Expected write to fail")
+			}
+
+			data, _ := os.ReadFile(path)
+			if string(data) != "level-name=second\n" {
+				t.Errorf("Expected original file to be untouched, got %q", data)
+			}
+			assertNoTempFiles(t, dir)
+		})
+	}
+}
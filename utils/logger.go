@@ -0,0 +1,213 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// LoggerOptions controls the panel's own application log
+type LoggerOptions struct {
+	Level    string // debug, info, warn or error
+	Format   string // text or json
+	FilePath string // Also write to this file when set
+	MaxSize  int64  // Rotate the file once it reaches this many bytes; 0 never rotates
+	MaxAge   time.Duration
+	Compress bool
+}
+
+// redacted replaces secrets in log output
+const redacted = "[REDACTED]"
+
+// sensitiveKeys attribute keys whose values are never logged
+var sensitiveKeys = []string{"password", "token", "secret", "authorization", "cookie"}
+
+// secretPatterns secrets embedded in messages and string values; the first group is kept
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9\-._~+/]+=*`),
+	regexp.MustCompile(`(?i)((?:token|password|secret)["']?\s*[=:]\s*["']?)[^\s"'&,;]+`),
+	regexp.MustCompile(`()eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`),
+	regexp.MustCompile(`()mcs_[A-Za-z0-9]+_[A-Za-z0-9_-]+`), // API tokens
+}
+
+// requestIDKey context key of the request ID
+type requestIDKey struct{}
+
+// ContextWithRequestID returns a context carrying a request ID; log records written
+// with it include the ID
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, if any
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// ParseLogLevel parses a configured log level; empty means info
+func ParseLogLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return slog.LevelInfo, fmt.Errorf("invalid log level: %s", level)
+}
+
+// NewLogger creates a logger writing records at level and above to w as text or
+// JSON. Secrets are redacted and request IDs added from the context.
+func NewLogger(w io.Writer, level slog.Level, format string) (*slog.Logger, error) {
+	options := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case "", "text":
+		handler = slog.NewTextHandler(w, options)
+	case "json":
+		handler = slog.NewJSONHandler(w, options)
+	default:
+		return nil, fmt.Errorf("invalid log format: %s", format)
+	}
+	return slog.New(&logHandler{next: handler}), nil
+}
+
+// SetupLogger makes the configured logger the default for slog and the standard log
+// package. The returned closer closes the log file, if there is one.
+func SetupLogger(options LoggerOptions) (io.Closer, error) {
+	level, err := ParseLogLevel(options.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	var output io.Writer = os.Stderr
+	var closer io.Closer = nopCloser{}
+	if options.FilePath != "" {
+		file, err := OpenRotatingFile(options.FilePath, RotatingFileOptions{
+			MaxSize:  options.MaxSize,
+			MaxAge:   options.MaxAge,
+			Compress: options.Compress,
+		})
+		if err != nil {
+			return nil, err
+		}
+		output = io.MultiWriter(os.Stderr, file)
+		closer = file
+	}
+
+	logger, err := NewLogger(output, level, options.Format)
+	if err != nil {
+		closer.Close()
+		return nil, err
+	}
+	slog.SetDefault(logger)
+	return closer, nil
+}
+
+// RedactString masks tokens, passwords and other secrets in s
+func RedactString(s string) string {
+	for _, pattern := range secretPatterns {
+		s = pattern.ReplaceAllString(s, "${1}"+redacted)
+	}
+	return s
+}
+
+// LogWriter returns a writer logging each line written to it at level, for
+// libraries that only accept an io.Writer
+func LogWriter(level slog.Level) io.Writer {
+	return &logWriter{level: level}
+}
+
+// nopCloser is returned by SetupLogger when there is no file to close
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
+
+// logWriter logs the lines written to it through the default logger
+type logWriter struct {
+	level slog.Level
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	for _, line := range bytes.Split(bytes.TrimRight(p, "\n"), []byte("\n")) {
+		if message := strings.TrimSpace(string(line)); message != "" {
+			slog.Log(context.Background(), w.level, message)
+		}
+	}
+	return len(p), nil
+}
+
+// logHandler redacts secrets and adds the request ID before passing records on
+type logHandler struct {
+	next slog.Handler
+}
+
+func (h *logHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *logHandler) Handle(ctx context.Context, record slog.Record) error {
+	clean := slog.NewRecord(record.Time, record.Level, RedactString(record.Message), record.PC)
+	if id := RequestIDFromContext(ctx); id != "" {
+		clean.AddAttrs(slog.String("request_id", id))
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		clean.AddAttrs(redactAttr(attr))
+		return true
+	})
+	return h.next.Handle(ctx, clean)
+}
+
+func (h *logHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clean := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		clean[i] = redactAttr(attr)
+	}
+	return &logHandler{next: h.next.WithAttrs(clean)}
+}
+
+func (h *logHandler) WithGroup(name string) slog.Handler {
+	return &logHandler{next: h.next.WithGroup(name)}
+}
+
+// redactAttr masks the value of sensitive attributes and secrets in string values
+func redactAttr(attr slog.Attr) slog.Attr {
+	key := strings.ToLower(attr.Key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return slog.String(attr.Key, redacted)
+		}
+	}
+
+	value := attr.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, RedactString(value.String()))
+	case slog.KindGroup:
+		group := value.Group()
+		clean := make([]any, len(group))
+		for i, member := range group {
+			clean[i] = redactAttr(member)
+		}
+		return slog.Group(attr.Key, clean...)
+	case slog.KindAny:
+		if err, ok := value.Any().(error); ok {
+			return slog.String(attr.Key, RedactString(err.Error()))
+		}
+	}
+	return slog.Attr{Key: attr.Key, Value: value}
+}
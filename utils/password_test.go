@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestPasswordHashing(t *testing.T) {
+	t.Run("Argon2id", func(t *testing.T) {
+		hash, err := HashPassword("Secret-123")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(hash, "$argon2id$v=19$m=65536,t=3,p=4$") {
+			t.Errorf("Unexpected hash format: %s", hash)
+		}
+		other, _ := HashPassword("Secret-123")
+		if other == hash {
+			t.Error("Expected each hash to use its own salt")
+		}
+
+		if ok, needsRehash := VerifyPassword("Secret-123", hash); !ok || needsRehash {
+			t.Errorf("Expected the password to match without a rehash, got %v, %v", ok, needsRehash)
+		}
+		if ok, _ := VerifyPassword("secret-123", hash); ok {
+			t.Error("Expected a wrong password to be rejected")
+		}
+	})
+
+	t.Run("LegacySHA256", func(t *testing.T) {
+		sum := sha256.Sum256([]byte("admin123"))
+		legacy := hex.EncodeToString(sum[:])
+		if !IsLegacyPasswordHash(legacy) {
+			t.Fatal("Expected a SHA-256 digest to be recognised")
+		}
+		if ok, needsRehash := VerifyPassword("admin123", legacy); !ok || !needsRehash {
+			t.Errorf("Expected the legacy hash to match and need a rehash, got %v, %v", ok, needsRehash)
+		}
+		if ok, needsRehash := VerifyPassword("admin1234", legacy); ok || needsRehash {
+			t.Errorf("Expected a wrong password to be rejected, got %v, %v", ok, needsRehash)
+		}
+	})
+
+	t.Run("OutdatedParameters", func(t *testing.T) {
+		original := DefaultArgon2idParams
+		DefaultArgon2idParams.Iterations = 1
+		hash, _ := HashPassword("Secret-123")
+		DefaultArgon2idParams = original
+
+		if ok, needsRehash := VerifyPassword("Secret-123", hash); !ok || !needsRehash {
+			t.Errorf("Expected a weaker hash to need a rehash, got %v, %v", ok, needsRehash)
+		}
+	})
+
+	t.Run("RejectsMalformedHashes", func(t *testing.T) {
+		for _, hash := range []string{"", "plain", "$argon2i$v=19$m=65536,t=3,p=4$c2FsdA$aGFzaA", "$argon2id$v=19$m=0,t=3,p=4$c2FsdA$aGFzaA", "$argon2id$v=19$m=65536,t=3,p=4$!!$aGFzaA"} {
+			if ok, _ := VerifyPassword("", hash); ok {
+				t.Errorf("Expected %q to be rejected", hash)
+			}
+		}
+	})
+
+	t.Run("GeneratePassword", func(t *testing.T) {
+		a, _ := GeneratePassword()
+		b, _ := GeneratePassword()
+		if a == b || len(a) < 16 {
+			t.Errorf("Expected long distinct passwords, got %q and %q", a, b)
+		}
+	})
+}
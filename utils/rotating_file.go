@@ -0,0 +1,230 @@
+package utils
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rotatedTimeFormat layout of the timestamp in rotated file names
+const rotatedTimeFormat = "20060102-150405"
+
+// RotatingFileOptions controls when a RotatingFile is rotated and what happens to
+// the rotated files
+type RotatingFileOptions struct {
+	MaxSize  int64         // Rotate once a write would take the file past this many bytes; 0 never rotates
+	MaxAge   time.Duration // Delete expiring files older than this; 0 keeps them
+	Compress bool          // Gzip rotated files
+
+	// RotatedPath returns the path the full file is moved to. By default it is
+	// timestamped, e.g. panel.log becomes panel.20250807-184012.log
+	RotatedPath func() string
+	// Expires reports whether a file in the log directory is deleted once older than
+	// MaxAge. By default that is the files rotated under the default names.
+	Expires func(name string) bool
+}
+
+// RotatingFile is an append-only log file that is moved aside once it reaches
+// its maximum size
+type RotatingFile struct {
+	mutex   sync.Mutex
+	path    string
+	options RotatingFileOptions
+	file    *os.File
+	size    int64
+}
+
+// OpenRotatingFile opens or creates the log file at path and deletes expired files
+func OpenRotatingFile(path string, options RotatingFileOptions) (*RotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %v", err)
+	}
+
+	f := &RotatingFile{path: path, options: options}
+	if f.options.RotatedPath == nil {
+		f.options.RotatedPath = f.timestampedPath
+	}
+	if f.options.Expires == nil {
+		f.options.Expires = f.timestampedPattern().MatchString
+	}
+
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	f.removeExpired()
+	return f, nil
+}
+
+// Write appends p to the file, rotating it first if p would take it past maxSize
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	if f.options.MaxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.options.MaxSize {
+		if err := f.rotate(); err != nil {
+			// Report to stderr; logging here would write back into this file
+			fmt.Fprintf(os.Stderr, "Warning: failed to rotate %s: %v\n", f.path, err)
+		}
+		if f.file == nil {
+			return 0, os.ErrClosed
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close closes the file
+func (f *RotatingFile) Close() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// open opens the file for appending; callers must hold the mutex
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %v", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to open log file: %v", err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+// rotate moves the file aside and opens a new one; callers must hold the mutex
+func (f *RotatingFile) rotate() error {
+	f.file.Close()
+	f.file = nil
+
+	rotated := f.options.RotatedPath()
+	renameErr := os.Rename(f.path, rotated)
+	if renameErr == nil && f.options.Compress {
+		if err := GzipFile(rotated); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to compress %s: %v\n", rotated, err)
+		}
+	}
+
+	// Keep logging even if the old file could not be moved
+	if err := f.open(); err != nil {
+		return err
+	}
+	f.removeExpired()
+	return renameErr
+}
+
+// removeExpired deletes expiring files older than MaxAge; callers must hold the
+// mutex or not have shared the file yet
+func (f *RotatingFile) removeExpired() {
+	if f.options.MaxAge <= 0 {
+		return
+	}
+
+	dir := filepath.Dir(f.path)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	cutoff := time.Now().Add(-f.options.MaxAge)
+	for _, entry := range entries {
+		if entry.IsDir() || entry.Name() == filepath.Base(f.path) || !f.options.Expires(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to remove expired log %s: %v\n", entry.Name(), err)
+		}
+	}
+}
+
+// timestampedPath returns a free timestamped name for the file being rotated
+func (f *RotatingFile) timestampedPath() string {
+	stem, ext := f.nameParts()
+	timestamp := time.Now().Format(rotatedTimeFormat)
+	rotated := filepath.Join(filepath.Dir(f.path), fmt.Sprintf("%s.%s%s", stem, timestamp, ext))
+	// Several rotations within a second get a counter
+	for i := 1; fileExists(rotated) || fileExists(rotated+".gz"); i++ {
+		rotated = filepath.Join(filepath.Dir(f.path), fmt.Sprintf("%s.%s-%d%s", stem, timestamp, i, ext))
+	}
+	return rotated
+}
+
+// timestampedPattern matches the names timestampedPath gives rotated files
+func (f *RotatingFile) timestampedPattern() *regexp.Regexp {
+	stem, ext := f.nameParts()
+	return regexp.MustCompile(`^` + regexp.QuoteMeta(stem) + `\.\d{8}-\d{6}(?:-\d+)?` + regexp.QuoteMeta(ext) + `(\.gz)?$`)
+}
+
+// nameParts splits the file name into the part before and the extension
+func (f *RotatingFile) nameParts() (string, string) {
+	name := filepath.Base(f.path)
+	ext := filepath.Ext(name)
+	return strings.TrimSuffix(name, ext), ext
+}
+
+// fileExists reports whether something exists at path
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// GzipFile compresses a file to path.gz and removes the original
+func GzipFile(path string) error {
+	source, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	tempPath := path + ".gz.tmp"
+	target, err := os.Create(tempPath)
+	if err != nil {
+		return err
+	}
+
+	writer := gzip.NewWriter(target)
+	_, err = io.Copy(writer, source)
+	if closeErr := writer.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := target.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+
+	if err := os.Rename(tempPath, path+".gz"); err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+	return os.Remove(path)
+}
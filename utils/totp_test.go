@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTOTP(t *testing.T) {
+	// RFC 6238 appendix B test vectors for SHA-1, truncated to 6 digits
+	secret := totpEncoding.EncodeToString([]byte("12345678901234567890"))
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+	for unix, want := range vectors {
+		code, err := TOTPCode(secret, time.Unix(unix, 0))
+		if err != nil || code != want {
+			t.Errorf("At %d expected %s, got %s, %v", unix, want, code, err)
+		}
+	}
+
+	t.Run("Validate", func(t *testing.T) {
+		now := time.Unix(1111111111, 0)
+		step, ok := ValidateTOTP(secret, "050471", now)
+		if !ok || step != 1111111111/30 {
+			t.Errorf("Expected the current code to be accepted, got %d, %v", step, ok)
+		}
+		if _, ok := ValidateTOTP(secret, "081 804", now); !ok {
+			t.Error("Expected the previous period's code to be accepted")
+		}
+		if _, ok := ValidateTOTP(secret, "050471", now.Add(2*time.Minute)); ok {
+			t.Error("Expected an old code to be rejected")
+		}
+		if _, ok := ValidateTOTP(secret, "12345", now); ok {
+			t.Error("Expected a short code to be rejected")
+		}
+	})
+
+	t.Run("Secret", func(t *testing.T) {
+		generated, err := GenerateTOTPSecret()
+		if err != nil || len(generated) != 32 {
+			t.Fatalf("Expected a 32 character secret, got %q, %v", generated, err)
+		}
+		code, _ := TOTPCode(generated, time.Now())
+		if _, ok := ValidateTOTP(generated, code, time.Now()); !ok {
+			t.Error("Expected a generated secret to round trip")
+		}
+
+		uri := TOTPProvisioningURI("Minecraft Easy Server", "admin", generated)
+		if !strings.HasPrefix(uri, "otpauth://totp/Minecraft%20Easy%20Server:admin?") || !strings.Contains(uri, "secret="+generated) {
+			t.Errorf("Unexpected provisioning URI: %s", uri)
+		}
+	})
+
+	t.Run("RecoveryCode", func(t *testing.T) {
+		code, err := GenerateRecoveryCode()
+		if err != nil || len(code) != 11 || code[5] != '-' {
+			t.Fatalf("Unexpected recovery code %q, %v", code, err)
+		}
+		if NormalizeRecoveryCode(" "+strings.ToUpper(code)+" ") != strings.ReplaceAll(code, "-", "") {
+			t.Error("Expected recovery codes to be normalized")
+		}
+	})
+}